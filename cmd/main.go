@@ -1,7 +1,42 @@
 package main
 
-import "github.com/arifai/zenith/pkg/server"
+import (
+	"flag"
+	"fmt"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/server"
+	"github.com/arifai/zenith/pkg/webpush"
+	"os"
+	"strings"
+
+	// Embeds the time zone database, quiet hours are evaluated in the time zone of each account.
+	_ "time/tzdata"
+)
 
 func main() {
-	server.Run()
+	generateVAPIDKeys := flag.Bool("generate-vapid-keys", false, "print a new VAPID key pair for Web Push and exit")
+	createInternalKey := flag.String("create-internal-key", "", "create an API key with the given name for an internal service, print it and exit")
+	internalKeyScopes := flag.String("internal-key-scopes", model.ScopeNotificationSend, "comma-separated scopes of the internal API key, e.g. notification:send,notification:template,notification:campaign,account:read")
+	flag.Parse()
+
+	switch {
+	case *generateVAPIDKeys:
+		privateKey, publicKey, err := webpush.GenerateKeys()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("VAPID_PRIVATE_KEY=%s\n# public key: %s\n", privateKey, publicKey)
+	case *createInternalKey != "":
+		key, err := server.CreateInternalAPIKey(*createInternalKey, strings.Split(*internalKeyScopes, ","))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		fmt.Println(key)
+	default:
+		server.Run()
+	}
 }
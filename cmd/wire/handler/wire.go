@@ -10,6 +10,9 @@ import (
 	"github.com/arifai/zenith/internal/repository"
 	"github.com/arifai/zenith/internal/service"
 	"github.com/arifai/zenith/pkg/logger"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/arifai/zenith/pkg/storage"
+	"github.com/arifai/zenith/pkg/utils"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -20,12 +23,52 @@ func ProvideHandler() *handler.Handler {
 	return &handler.Handler{}
 }
 
-func ProvideAccountHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.AccountHandler {
-	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewAccountRepository, service.NewAccountService, handler.NewAccountHandler)
+func ProvideAccountHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, mailer utils.Mailer, store storage.Storage, topics push.TopicManager) *handler.AccountHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewAccountRepository, repository.NewDeviceTokenRepository, repository.NewTopicRepository, service.NewAccountService, handler.NewAccountHandler)
 	return &handler.AccountHandler{}
 }
 
 func ProvideNotificationHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.NotificationHandler {
-	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewNotificationRepository, service.NewNotificationService, handler.NewNotificationHandler)
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewNotificationRepository, repository.NewNotificationPreferenceRepository, repository.NewNotificationTemplateRepository, repository.NewNotificationEventRepository, service.NewNotificationService, service.NewNotificationStreamService, handler.NewNotificationHandler)
 	return &handler.NotificationHandler{}
 }
+
+func ProvideAPIKeyHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.APIKeyHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewAPIKeyRepository, service.NewAPIKeyService, handler.NewAPIKeyHandler)
+	return &handler.APIKeyHandler{}
+}
+
+func ProvideOAuthHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.OAuthHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewOAuthRepository, repository.NewAccountRepository, service.NewOAuthService, handler.NewOAuthHandler)
+	return &handler.OAuthHandler{}
+}
+
+func ProvideTopicHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, topics push.TopicManager) *handler.TopicHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewTopicRepository, repository.NewDeviceTokenRepository, repository.NewPushNotificationRepository, service.NewTopicService, handler.NewTopicHandler)
+	return &handler.TopicHandler{}
+}
+
+func ProvideWebPushHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.WebPushHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewDeviceTokenRepository, service.NewWebPushService, handler.NewWebPushHandler)
+	return &handler.WebPushHandler{}
+}
+
+func ProvideNotificationPreferenceHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.NotificationPreferenceHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewNotificationPreferenceRepository, service.NewNotificationPreferenceService, handler.NewNotificationPreferenceHandler)
+	return &handler.NotificationPreferenceHandler{}
+}
+
+func ProvideNotificationTemplateHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.NotificationTemplateHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewNotificationTemplateRepository, repository.NewPushReceiptRepository, service.NewNotificationTemplateService, handler.NewNotificationTemplateHandler)
+	return &handler.NotificationTemplateHandler{}
+}
+
+func ProvideCampaignHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.CampaignHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewCampaignRepository, repository.NewNotificationTemplateRepository, service.NewCampaignService, handler.NewCampaignHandler)
+	return &handler.CampaignHandler{}
+}
+
+func ProvidePushReceiptHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.PushReceiptHandler {
+	wire.Build(cmn.ProvideResponse, repo.ProvideRepository, handler.New, service.New, repository.NewPushReceiptRepository, repository.NewNotificationRepository, repository.NewNotificationEventRepository, service.NewPushReceiptService, handler.NewPushReceiptHandler)
+	return &handler.PushReceiptHandler{}
+}
@@ -14,6 +14,9 @@ import (
 	repository2 "github.com/arifai/zenith/internal/repository"
 	"github.com/arifai/zenith/internal/service"
 	"github.com/arifai/zenith/pkg/logger"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/arifai/zenith/pkg/storage"
+	"github.com/arifai/zenith/pkg/utils"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -26,13 +29,15 @@ func ProvideHandler() *handler.Handler {
 	return handlerHandler
 }
 
-func ProvideAccountHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.AccountHandler {
+func ProvideAccountHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, mailer utils.Mailer, store storage.Storage, topics push.TopicManager) *handler.AccountHandler {
 	response := common.ProvideResponse()
 	handlerHandler := handler.New(response)
 	serviceService := service.New(cfg, log)
 	repositoryRepository := repository.ProvideRepository(db, rdb)
 	accountRepository := repository2.NewAccountRepository(repositoryRepository)
-	accountService := service.NewAccountService(serviceService, accountRepository)
+	deviceTokenRepository := repository2.NewDeviceTokenRepository(repositoryRepository)
+	topicRepository := repository2.NewTopicRepository(repositoryRepository)
+	accountService := service.NewAccountService(serviceService, accountRepository, deviceTokenRepository, topicRepository, topics, mailer, store)
 	accountHandler := handler.NewAccountHandler(handlerHandler, accountService)
 	return accountHandler
 }
@@ -43,7 +48,106 @@ func ProvideNotificationHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Conf
 	serviceService := service.New(cfg, log)
 	repositoryRepository := repository.ProvideRepository(db, rdb)
 	notificationRepository := repository2.NewNotificationRepository(repositoryRepository)
-	notificationService := service.NewNotificationService(serviceService, notificationRepository)
-	notificationHandler := handler.NewNotificationHandler(handlerHandler, notificationService)
+	notificationPreferenceRepository := repository2.NewNotificationPreferenceRepository(repositoryRepository)
+	notificationTemplateRepository := repository2.NewNotificationTemplateRepository(repositoryRepository)
+	notificationEventRepository := repository2.NewNotificationEventRepository(repositoryRepository)
+	notificationService := service.NewNotificationService(serviceService, notificationRepository, notificationPreferenceRepository, notificationTemplateRepository, notificationEventRepository)
+	notificationStreamService := service.NewNotificationStreamService(serviceService, notificationEventRepository)
+	notificationHandler := handler.NewNotificationHandler(handlerHandler, notificationService, notificationStreamService)
 	return notificationHandler
 }
+
+func ProvideAPIKeyHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.APIKeyHandler {
+	response := common.ProvideResponse()
+	handlerHandler := handler.New(response)
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.ProvideRepository(db, rdb)
+	apiKeyRepository := repository2.NewAPIKeyRepository(repositoryRepository)
+	apiKeyService := service.NewAPIKeyService(serviceService, apiKeyRepository)
+	apiKeyHandler := handler.NewAPIKeyHandler(handlerHandler, apiKeyService)
+	return apiKeyHandler
+}
+
+func ProvideOAuthHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.OAuthHandler {
+	response := common.ProvideResponse()
+	handlerHandler := handler.New(response)
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.ProvideRepository(db, rdb)
+	oAuthRepository := repository2.NewOAuthRepository(repositoryRepository)
+	accountRepository := repository2.NewAccountRepository(repositoryRepository)
+	oAuthService := service.NewOAuthService(serviceService, oAuthRepository, accountRepository)
+	oAuthHandler := handler.NewOAuthHandler(handlerHandler, oAuthService)
+	return oAuthHandler
+}
+
+func ProvideTopicHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, topics push.TopicManager) *handler.TopicHandler {
+	response := common.ProvideResponse()
+	handlerHandler := handler.New(response)
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.ProvideRepository(db, rdb)
+	topicRepository := repository2.NewTopicRepository(repositoryRepository)
+	deviceTokenRepository := repository2.NewDeviceTokenRepository(repositoryRepository)
+	pushNotificationRepository := repository2.NewPushNotificationRepository(repositoryRepository)
+	topicService := service.NewTopicService(serviceService, topicRepository, deviceTokenRepository, pushNotificationRepository, topics)
+	topicHandler := handler.NewTopicHandler(handlerHandler, topicService)
+	return topicHandler
+}
+
+func ProvideWebPushHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.WebPushHandler {
+	response := common.ProvideResponse()
+	handlerHandler := handler.New(response)
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.ProvideRepository(db, rdb)
+	deviceTokenRepository := repository2.NewDeviceTokenRepository(repositoryRepository)
+	webPushService := service.NewWebPushService(serviceService, deviceTokenRepository)
+	webPushHandler := handler.NewWebPushHandler(handlerHandler, webPushService)
+	return webPushHandler
+}
+
+func ProvideNotificationPreferenceHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.NotificationPreferenceHandler {
+	response := common.ProvideResponse()
+	handlerHandler := handler.New(response)
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.ProvideRepository(db, rdb)
+	notificationPreferenceRepository := repository2.NewNotificationPreferenceRepository(repositoryRepository)
+	notificationPreferenceService := service.NewNotificationPreferenceService(serviceService, notificationPreferenceRepository)
+	notificationPreferenceHandler := handler.NewNotificationPreferenceHandler(handlerHandler, notificationPreferenceService)
+	return notificationPreferenceHandler
+}
+
+func ProvideNotificationTemplateHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.NotificationTemplateHandler {
+	response := common.ProvideResponse()
+	handlerHandler := handler.New(response)
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.ProvideRepository(db, rdb)
+	notificationTemplateRepository := repository2.NewNotificationTemplateRepository(repositoryRepository)
+	pushReceiptRepository := repository2.NewPushReceiptRepository(repositoryRepository)
+	notificationTemplateService := service.NewNotificationTemplateService(serviceService, notificationTemplateRepository, pushReceiptRepository)
+	notificationTemplateHandler := handler.NewNotificationTemplateHandler(handlerHandler, notificationTemplateService)
+	return notificationTemplateHandler
+}
+
+func ProvideCampaignHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.CampaignHandler {
+	response := common.ProvideResponse()
+	handlerHandler := handler.New(response)
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.ProvideRepository(db, rdb)
+	campaignRepository := repository2.NewCampaignRepository(repositoryRepository)
+	notificationTemplateRepository := repository2.NewNotificationTemplateRepository(repositoryRepository)
+	campaignService := service.NewCampaignService(serviceService, campaignRepository, notificationTemplateRepository)
+	campaignHandler := handler.NewCampaignHandler(handlerHandler, campaignService)
+	return campaignHandler
+}
+
+func ProvidePushReceiptHandler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) *handler.PushReceiptHandler {
+	response := common.ProvideResponse()
+	handlerHandler := handler.New(response)
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.ProvideRepository(db, rdb)
+	pushReceiptRepository := repository2.NewPushReceiptRepository(repositoryRepository)
+	notificationRepository := repository2.NewNotificationRepository(repositoryRepository)
+	notificationEventRepository := repository2.NewNotificationEventRepository(repositoryRepository)
+	pushReceiptService := service.NewPushReceiptService(serviceService, pushReceiptRepository, notificationRepository, notificationEventRepository)
+	pushReceiptHandler := handler.NewPushReceiptHandler(handlerHandler, pushReceiptService)
+	return pushReceiptHandler
+}
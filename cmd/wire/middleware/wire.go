@@ -2,10 +2,14 @@ package middleware
 
 import (
 	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/repository"
 	"github.com/google/wire"
 )
 
 var WireMiddlewareSet = wire.NewSet(
 	middleware.New,
 	middleware.NewStrictAuthMiddleware,
+	repository.New,
+	repository.NewAPIKeyRepository,
+	middleware.NewAPIKeyAuthMiddleware,
 )
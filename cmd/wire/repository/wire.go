@@ -23,3 +23,48 @@ func ProvideNotificationRepository(db *gorm.DB, rdb *redis.Client) repository.No
 	wire.Build(repository.New, repository.NewNotificationRepository)
 	return nil
 }
+
+func ProvideAPIKeyRepository(db *gorm.DB, rdb *redis.Client) repository.APIKeyRepository {
+	wire.Build(repository.New, repository.NewAPIKeyRepository)
+	return nil
+}
+
+func ProvideOAuthRepository(db *gorm.DB, rdb *redis.Client) repository.OAuthRepository {
+	wire.Build(repository.New, repository.NewOAuthRepository)
+	return nil
+}
+
+func ProvidePushNotificationRepository(db *gorm.DB, rdb *redis.Client) repository.PushNotificationRepository {
+	wire.Build(repository.New, repository.NewPushNotificationRepository)
+	return nil
+}
+
+func ProvideDeviceTokenRepository(db *gorm.DB, rdb *redis.Client) repository.DeviceTokenRepository {
+	wire.Build(repository.New, repository.NewDeviceTokenRepository)
+	return nil
+}
+
+func ProvideTopicRepository(db *gorm.DB, rdb *redis.Client) repository.TopicRepository {
+	wire.Build(repository.New, repository.NewTopicRepository)
+	return nil
+}
+
+func ProvideNotificationPreferenceRepository(db *gorm.DB, rdb *redis.Client) repository.NotificationPreferenceRepository {
+	wire.Build(repository.New, repository.NewNotificationPreferenceRepository)
+	return nil
+}
+
+func ProvideNotificationTemplateRepository(db *gorm.DB, rdb *redis.Client) repository.NotificationTemplateRepository {
+	wire.Build(repository.New, repository.NewNotificationTemplateRepository)
+	return nil
+}
+
+func ProvideCampaignRepository(db *gorm.DB, rdb *redis.Client) repository.CampaignRepository {
+	wire.Build(repository.New, repository.NewCampaignRepository)
+	return nil
+}
+
+func ProvideNotificationEventRepository(db *gorm.DB, rdb *redis.Client) repository.NotificationEventRepository {
+	wire.Build(repository.New, repository.NewNotificationEventRepository)
+	return nil
+}
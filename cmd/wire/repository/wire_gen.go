@@ -30,3 +30,57 @@ func ProvideNotificationRepository(db *gorm.DB, rdb *redis.Client) repository.No
 	notificationRepository := repository.NewNotificationRepository(repositoryRepository)
 	return notificationRepository
 }
+
+func ProvideAPIKeyRepository(db *gorm.DB, rdb *redis.Client) repository.APIKeyRepository {
+	repositoryRepository := repository.New(db, rdb)
+	apiKeyRepository := repository.NewAPIKeyRepository(repositoryRepository)
+	return apiKeyRepository
+}
+
+func ProvideOAuthRepository(db *gorm.DB, rdb *redis.Client) repository.OAuthRepository {
+	repositoryRepository := repository.New(db, rdb)
+	oAuthRepository := repository.NewOAuthRepository(repositoryRepository)
+	return oAuthRepository
+}
+
+func ProvidePushNotificationRepository(db *gorm.DB, rdb *redis.Client) repository.PushNotificationRepository {
+	repositoryRepository := repository.New(db, rdb)
+	pushNotificationRepository := repository.NewPushNotificationRepository(repositoryRepository)
+	return pushNotificationRepository
+}
+
+func ProvideDeviceTokenRepository(db *gorm.DB, rdb *redis.Client) repository.DeviceTokenRepository {
+	repositoryRepository := repository.New(db, rdb)
+	deviceTokenRepository := repository.NewDeviceTokenRepository(repositoryRepository)
+	return deviceTokenRepository
+}
+
+func ProvideTopicRepository(db *gorm.DB, rdb *redis.Client) repository.TopicRepository {
+	repositoryRepository := repository.New(db, rdb)
+	topicRepository := repository.NewTopicRepository(repositoryRepository)
+	return topicRepository
+}
+
+func ProvideNotificationPreferenceRepository(db *gorm.DB, rdb *redis.Client) repository.NotificationPreferenceRepository {
+	repositoryRepository := repository.New(db, rdb)
+	notificationPreferenceRepository := repository.NewNotificationPreferenceRepository(repositoryRepository)
+	return notificationPreferenceRepository
+}
+
+func ProvideNotificationTemplateRepository(db *gorm.DB, rdb *redis.Client) repository.NotificationTemplateRepository {
+	repositoryRepository := repository.New(db, rdb)
+	notificationTemplateRepository := repository.NewNotificationTemplateRepository(repositoryRepository)
+	return notificationTemplateRepository
+}
+
+func ProvideCampaignRepository(db *gorm.DB, rdb *redis.Client) repository.CampaignRepository {
+	repositoryRepository := repository.New(db, rdb)
+	campaignRepository := repository.NewCampaignRepository(repositoryRepository)
+	return campaignRepository
+}
+
+func ProvideNotificationEventRepository(db *gorm.DB, rdb *redis.Client) repository.NotificationEventRepository {
+	repositoryRepository := repository.New(db, rdb)
+	notificationEventRepository := repository.NewNotificationEventRepository(repositoryRepository)
+	return notificationEventRepository
+}
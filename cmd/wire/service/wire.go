@@ -7,6 +7,9 @@ import (
 	"github.com/arifai/zenith/internal/repository"
 	"github.com/arifai/zenith/internal/service"
 	"github.com/arifai/zenith/pkg/logger"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/arifai/zenith/pkg/storage"
+	"github.com/arifai/zenith/pkg/utils"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -17,12 +20,77 @@ func ProvideService(cfg *config.Config, log logger.Logger) *service.Service {
 	return &service.Service{}
 }
 
-func ProvideAccountService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.AccountService {
-	wire.Build(service.New, repository.New, repository.NewAccountRepository, service.NewAccountService)
+func ProvideAccountService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, mailer utils.Mailer, store storage.Storage, topics push.TopicManager) service.AccountService {
+	wire.Build(service.New, repository.New, repository.NewAccountRepository, repository.NewDeviceTokenRepository, repository.NewTopicRepository, service.NewAccountService)
 	return nil
 }
 
 func ProvideNotificationService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.NotificationService {
-	wire.Build(service.New, repository.New, repository.NewNotificationRepository, service.NewNotificationService)
+	wire.Build(service.New, repository.New, repository.NewNotificationRepository, repository.NewNotificationPreferenceRepository, repository.NewNotificationTemplateRepository, repository.NewNotificationEventRepository, service.NewNotificationService)
+	return nil
+}
+
+func ProvideAPIKeyService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.APIKeyService {
+	wire.Build(service.New, repository.New, repository.NewAPIKeyRepository, service.NewAPIKeyService)
+	return nil
+}
+
+func ProvideOAuthService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.OAuthService {
+	wire.Build(service.New, repository.New, repository.NewOAuthRepository, repository.NewAccountRepository, service.NewOAuthService)
+	return nil
+}
+
+func ProvideTopicService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, topics push.TopicManager) service.TopicService {
+	wire.Build(service.New, repository.New, repository.NewTopicRepository, repository.NewDeviceTokenRepository, repository.NewPushNotificationRepository, service.NewTopicService)
+	return nil
+}
+
+func ProvideWebPushService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.WebPushService {
+	wire.Build(service.New, repository.New, repository.NewDeviceTokenRepository, service.NewWebPushService)
+	return nil
+}
+
+func ProvideNotificationStreamService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.NotificationStreamService {
+	wire.Build(service.New, repository.New, repository.NewNotificationEventRepository, service.NewNotificationStreamService)
+	return nil
+}
+
+func ProvideNotificationPreferenceService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.NotificationPreferenceService {
+	wire.Build(service.New, repository.New, repository.NewNotificationPreferenceRepository, service.NewNotificationPreferenceService)
+	return nil
+}
+
+func ProvideNotificationTemplateService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.NotificationTemplateService {
+	wire.Build(service.New, repository.New, repository.NewNotificationTemplateRepository, repository.NewPushReceiptRepository, service.NewNotificationTemplateService)
+	return nil
+}
+
+func ProvidePushReceiptService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.PushReceiptService {
+	wire.Build(service.New, repository.New, repository.NewPushReceiptRepository, repository.NewNotificationRepository, repository.NewNotificationEventRepository, service.NewPushReceiptService)
+	return nil
+}
+
+func ProvideCampaignService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.CampaignService {
+	wire.Build(service.New, repository.New, repository.NewCampaignRepository, repository.NewNotificationTemplateRepository, service.NewCampaignService)
+	return nil
+}
+
+func ProvideCampaignScheduler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.CampaignScheduler {
+	wire.Build(service.New, repository.New, repository.NewCampaignRepository, repository.NewNotificationPreferenceRepository, repository.NewNotificationTemplateRepository, service.NewCampaignScheduler)
+	return nil
+}
+
+func ProvideNotificationDigest(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, mailer utils.Mailer) service.NotificationDigest {
+	wire.Build(service.New, repository.New, repository.NewNotificationRepository, repository.NewNotificationPreferenceRepository, service.NewNotificationDigest)
+	return nil
+}
+
+func ProvideRetentionPurger(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.RetentionPurger {
+	wire.Build(service.New, repository.New, repository.NewRetentionRepository, service.NewRetentionPurger)
+	return nil
+}
+
+func ProvidePushDispatcher(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, provider push.Provider) service.PushDispatcher {
+	wire.Build(service.New, repository.New, repository.NewPushNotificationRepository, repository.NewDeviceTokenRepository, service.NewPushDispatcher)
 	return nil
 }
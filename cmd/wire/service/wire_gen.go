@@ -11,6 +11,9 @@ import (
 	"github.com/arifai/zenith/internal/repository"
 	"github.com/arifai/zenith/internal/service"
 	"github.com/arifai/zenith/pkg/logger"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/arifai/zenith/pkg/storage"
+	"github.com/arifai/zenith/pkg/utils"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -22,11 +25,13 @@ func ProvideService(cfg *config.Config, log logger.Logger) *service.Service {
 	return serviceService
 }
 
-func ProvideAccountService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.AccountService {
+func ProvideAccountService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, mailer utils.Mailer, store storage.Storage, topics push.TopicManager) service.AccountService {
 	serviceService := service.New(cfg, log)
 	repositoryRepository := repository.New(db, rdb)
 	accountRepository := repository.NewAccountRepository(repositoryRepository)
-	accountService := service.NewAccountService(serviceService, accountRepository)
+	deviceTokenRepository := repository.NewDeviceTokenRepository(repositoryRepository)
+	topicRepository := repository.NewTopicRepository(repositoryRepository)
+	accountService := service.NewAccountService(serviceService, accountRepository, deviceTokenRepository, topicRepository, topics, mailer, store)
 	return accountService
 }
 
@@ -34,6 +39,124 @@ func ProvideNotificationService(db *gorm.DB, rdb *redis.Client, cfg *config.Conf
 	serviceService := service.New(cfg, log)
 	repositoryRepository := repository.New(db, rdb)
 	notificationRepository := repository.NewNotificationRepository(repositoryRepository)
-	notificationService := service.NewNotificationService(serviceService, notificationRepository)
+	notificationPreferenceRepository := repository.NewNotificationPreferenceRepository(repositoryRepository)
+	notificationTemplateRepository := repository.NewNotificationTemplateRepository(repositoryRepository)
+	notificationEventRepository := repository.NewNotificationEventRepository(repositoryRepository)
+	notificationService := service.NewNotificationService(serviceService, notificationRepository, notificationPreferenceRepository, notificationTemplateRepository, notificationEventRepository)
 	return notificationService
 }
+
+func ProvideAPIKeyService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.APIKeyService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	apiKeyRepository := repository.NewAPIKeyRepository(repositoryRepository)
+	apiKeyService := service.NewAPIKeyService(serviceService, apiKeyRepository)
+	return apiKeyService
+}
+
+func ProvideOAuthService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.OAuthService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	oAuthRepository := repository.NewOAuthRepository(repositoryRepository)
+	accountRepository := repository.NewAccountRepository(repositoryRepository)
+	oAuthService := service.NewOAuthService(serviceService, oAuthRepository, accountRepository)
+	return oAuthService
+}
+
+func ProvideTopicService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, topics push.TopicManager) service.TopicService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	topicRepository := repository.NewTopicRepository(repositoryRepository)
+	deviceTokenRepository := repository.NewDeviceTokenRepository(repositoryRepository)
+	pushNotificationRepository := repository.NewPushNotificationRepository(repositoryRepository)
+	topicService := service.NewTopicService(serviceService, topicRepository, deviceTokenRepository, pushNotificationRepository, topics)
+	return topicService
+}
+
+func ProvideWebPushService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.WebPushService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	deviceTokenRepository := repository.NewDeviceTokenRepository(repositoryRepository)
+	webPushService := service.NewWebPushService(serviceService, deviceTokenRepository)
+	return webPushService
+}
+
+func ProvideNotificationStreamService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.NotificationStreamService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	notificationEventRepository := repository.NewNotificationEventRepository(repositoryRepository)
+	notificationStreamService := service.NewNotificationStreamService(serviceService, notificationEventRepository)
+	return notificationStreamService
+}
+
+func ProvideNotificationPreferenceService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.NotificationPreferenceService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	notificationPreferenceRepository := repository.NewNotificationPreferenceRepository(repositoryRepository)
+	notificationPreferenceService := service.NewNotificationPreferenceService(serviceService, notificationPreferenceRepository)
+	return notificationPreferenceService
+}
+
+func ProvideNotificationTemplateService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.NotificationTemplateService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	notificationTemplateRepository := repository.NewNotificationTemplateRepository(repositoryRepository)
+	pushReceiptRepository := repository.NewPushReceiptRepository(repositoryRepository)
+	notificationTemplateService := service.NewNotificationTemplateService(serviceService, notificationTemplateRepository, pushReceiptRepository)
+	return notificationTemplateService
+}
+
+func ProvidePushReceiptService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.PushReceiptService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	pushReceiptRepository := repository.NewPushReceiptRepository(repositoryRepository)
+	notificationRepository := repository.NewNotificationRepository(repositoryRepository)
+	notificationEventRepository := repository.NewNotificationEventRepository(repositoryRepository)
+	pushReceiptService := service.NewPushReceiptService(serviceService, pushReceiptRepository, notificationRepository, notificationEventRepository)
+	return pushReceiptService
+}
+
+func ProvideCampaignService(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.CampaignService {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	campaignRepository := repository.NewCampaignRepository(repositoryRepository)
+	notificationTemplateRepository := repository.NewNotificationTemplateRepository(repositoryRepository)
+	campaignService := service.NewCampaignService(serviceService, campaignRepository, notificationTemplateRepository)
+	return campaignService
+}
+
+func ProvideCampaignScheduler(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.CampaignScheduler {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	campaignRepository := repository.NewCampaignRepository(repositoryRepository)
+	notificationPreferenceRepository := repository.NewNotificationPreferenceRepository(repositoryRepository)
+	notificationTemplateRepository := repository.NewNotificationTemplateRepository(repositoryRepository)
+	campaignScheduler := service.NewCampaignScheduler(serviceService, campaignRepository, notificationPreferenceRepository, notificationTemplateRepository)
+	return campaignScheduler
+}
+
+func ProvideNotificationDigest(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, mailer utils.Mailer) service.NotificationDigest {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	notificationRepository := repository.NewNotificationRepository(repositoryRepository)
+	notificationPreferenceRepository := repository.NewNotificationPreferenceRepository(repositoryRepository)
+	notificationDigest := service.NewNotificationDigest(serviceService, notificationRepository, notificationPreferenceRepository, mailer)
+	return notificationDigest
+}
+
+func ProvideRetentionPurger(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger) service.RetentionPurger {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	retentionRepository := repository.NewRetentionRepository(repositoryRepository)
+	retentionPurger := service.NewRetentionPurger(serviceService, retentionRepository)
+	return retentionPurger
+}
+
+func ProvidePushDispatcher(db *gorm.DB, rdb *redis.Client, cfg *config.Config, log logger.Logger, provider push.Provider) service.PushDispatcher {
+	serviceService := service.New(cfg, log)
+	repositoryRepository := repository.New(db, rdb)
+	pushNotificationRepository := repository.NewPushNotificationRepository(repositoryRepository)
+	deviceTokenRepository := repository.NewDeviceTokenRepository(repositoryRepository)
+	pushDispatcher := service.NewPushDispatcher(serviceService, pushNotificationRepository, deviceTokenRepository, provider)
+	return pushDispatcher
+}
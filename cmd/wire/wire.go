@@ -7,17 +7,28 @@ import (
 	"github.com/arifai/zenith/cmd/wire/middleware"
 	"github.com/arifai/zenith/config"
 	"github.com/arifai/zenith/pkg/logger"
+	"github.com/arifai/zenith/pkg/push"
 	"github.com/arifai/zenith/pkg/server/http"
+	"github.com/arifai/zenith/pkg/storage"
+	"github.com/arifai/zenith/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
-func InitializeRouter(db *gorm.DB, redis *redis.Client, cfg *config.Config, log logger.Logger) *gin.Engine {
+func InitializeRouter(db *gorm.DB, redis *redis.Client, cfg *config.Config, log logger.Logger, mailer utils.Mailer, store storage.Storage, topics push.TopicManager) *gin.Engine {
 	wire.Build(
 		handler.ProvideAccountHandler,
 		handler.ProvideNotificationHandler,
+		handler.ProvideAPIKeyHandler,
+		handler.ProvideOAuthHandler,
+		handler.ProvideTopicHandler,
+		handler.ProvideWebPushHandler,
+		handler.ProvideNotificationPreferenceHandler,
+		handler.ProvideNotificationTemplateHandler,
+		handler.ProvideCampaignHandler,
+		handler.ProvidePushReceiptHandler,
 		middleware.WireMiddlewareSet,
 		http.ProvideGinEngine,
 	)
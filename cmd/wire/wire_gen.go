@@ -10,8 +10,12 @@ import (
 	"github.com/arifai/zenith/cmd/wire/handler"
 	"github.com/arifai/zenith/config"
 	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/repository"
 	"github.com/arifai/zenith/pkg/logger"
+	"github.com/arifai/zenith/pkg/push"
 	"github.com/arifai/zenith/pkg/server/http"
+	"github.com/arifai/zenith/pkg/storage"
+	"github.com/arifai/zenith/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -19,11 +23,22 @@ import (
 
 // Injectors from wire.go:
 
-func InitializeRouter(db *gorm.DB, redis2 *redis.Client, cfg *config.Config, log logger.Logger) *gin.Engine {
-	accountHandler := handler.ProvideAccountHandler(db, redis2, cfg, log)
+func InitializeRouter(db *gorm.DB, redis2 *redis.Client, cfg *config.Config, log logger.Logger, mailer utils.Mailer, store storage.Storage, topics push.TopicManager) *gin.Engine {
+	accountHandler := handler.ProvideAccountHandler(db, redis2, cfg, log, mailer, store, topics)
 	notificationHandler := handler.ProvideNotificationHandler(db, redis2, cfg, log)
+	apiKeyHandler := handler.ProvideAPIKeyHandler(db, redis2, cfg, log)
+	oAuthHandler := handler.ProvideOAuthHandler(db, redis2, cfg, log)
+	topicHandler := handler.ProvideTopicHandler(db, redis2, cfg, log, topics)
+	webPushHandler := handler.ProvideWebPushHandler(db, redis2, cfg, log)
+	notificationPreferenceHandler := handler.ProvideNotificationPreferenceHandler(db, redis2, cfg, log)
+	notificationTemplateHandler := handler.ProvideNotificationTemplateHandler(db, redis2, cfg, log)
+	campaignHandler := handler.ProvideCampaignHandler(db, redis2, cfg, log)
+	pushReceiptHandler := handler.ProvidePushReceiptHandler(db, redis2, cfg, log)
 	middlewareMiddleware := middleware.New(db, redis2)
 	strictAuthMiddleware := middleware.NewStrictAuthMiddleware(middlewareMiddleware)
-	engine := http.ProvideGinEngine(accountHandler, notificationHandler, strictAuthMiddleware)
+	repositoryRepository := repository.New(db, redis2)
+	apiKeyRepository := repository.NewAPIKeyRepository(repositoryRepository)
+	apiKeyAuthMiddleware := middleware.NewAPIKeyAuthMiddleware(middlewareMiddleware, apiKeyRepository)
+	engine := http.ProvideGinEngine(accountHandler, notificationHandler, apiKeyHandler, oAuthHandler, topicHandler, webPushHandler, notificationPreferenceHandler, notificationTemplateHandler, campaignHandler, pushReceiptHandler, strictAuthMiddleware, apiKeyAuthMiddleware)
 	return engine
 }
@@ -2,11 +2,14 @@ package config
 
 import (
 	"aidanwoods.dev/go-paseto"
+	"crypto/rand"
+	"encoding/hex"
 	"github.com/Netflix/go-env"
 	"github.com/arifai/zenith/cmd/wire/logger"
 	"github.com/arifai/zenith/pkg/errormessage"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
+	"time"
 )
 
 type (
@@ -14,6 +17,7 @@ type (
 	Config struct {
 		AppHost          string `env:"APP_HOST"`
 		AppPort          string `env:"APP_PORT"`
+		AppURL           string `env:"APP_URL"`
 		Debug            bool   `env:"DEBUG"`
 		DatabaseHost     string `env:"DB_HOST"`
 		DatabasePort     string `env:"DB_PORT"`
@@ -22,17 +26,70 @@ type (
 		DatabasePassword string `env:"DB_PASSWORD"`
 		SslMode          string `env:"SSL_MODE"`
 		Timezone         string `env:"TIMEZONE"`
+		DefaultLocale    string `env:"DEFAULT_LOCALE,default=en"`
 		PasswordSalt     string `env:"PASSWORD_SALT"`
+		CursorSecret     string `env:"CURSOR_SECRET"`
 		SMTPHost         string `env:"SMTP_HOST"`
 		SMTPPort         int    `env:"SMTP_PORT"`
 		SMTPUsername     string `env:"SMTP_USERNAME"`
 		SMTPPassword     string `env:"SMTP_PASSWORD"`
+		SMTPEncryption   string `env:"SMTP_ENCRYPTION"`
+		MailDriver       string `env:"MAIL_DRIVER,default=smtp"`
+		MailFrom         string `env:"MAIL_FROM"`
+		MailFileDir      string `env:"MAIL_FILE_DIR,default=mail"`
+		MailQueueSize    int    `env:"MAIL_QUEUE_SIZE,default=100"`
+		MailWorkers      int    `env:"MAIL_WORKERS,default=2"`
 		RedisHost        string `env:"REDIS_HOST"`
 		RedisPort        int    `env:"REDIS_PORT"`
 		RedisDatabase    int    `env:"REDIS_DB"`
 		RedisUsername    string `env:"REDIS_USERNAME"`
 		RedisPassword    string `env:"REDIS_PASSWORD"`
 		ZipkinURL        string `env:"ZIPKIN_URL"`
+		StorageDriver    string `env:"STORAGE_DRIVER,default=local"`
+		StoragePublicURL string `env:"STORAGE_PUBLIC_URL"`
+		StorageLocalRoot string `env:"STORAGE_LOCAL_ROOT,default=storage"`
+		StorageRoute     string `env:"STORAGE_ROUTE,default=/storage"`
+		S3Endpoint       string `env:"S3_ENDPOINT"`
+		S3Region         string `env:"S3_REGION"`
+		S3Bucket         string `env:"S3_BUCKET"`
+		S3AccessKey      string `env:"S3_ACCESS_KEY"`
+		S3SecretKey      string `env:"S3_SECRET_KEY"`
+		S3UsePathStyle   bool   `env:"S3_USE_PATH_STYLE"`
+		AvatarMaxSize    int64  `env:"AVATAR_MAX_SIZE,default=5242880"`
+		FirebaseCredFile string `env:"FIREBASE_CREDENTIALS_FILE"`
+		APNsKeyFile      string `env:"APNS_KEY_FILE"`
+		APNsKeyID        string `env:"APNS_KEY_ID"`
+		APNsTeamID       string `env:"APNS_TEAM_ID"`
+		APNsTopic        string `env:"APNS_TOPIC"`
+		APNsProduction   bool   `env:"APNS_PRODUCTION"`
+		VAPIDPrivateKey  string `env:"VAPID_PRIVATE_KEY"`
+		VAPIDSubject     string `env:"VAPID_SUBJECT"`
+
+		PushPollInterval time.Duration `env:"PUSH_POLL_INTERVAL,default=5s"`
+		PushBatchSize    int           `env:"PUSH_BATCH_SIZE,default=50"`
+		PushWorkers      int           `env:"PUSH_WORKERS,default=4"`
+		PushMaxRetries   int           `env:"PUSH_MAX_RETRIES,default=5"`
+		PushBackoffBase  time.Duration `env:"PUSH_BACKOFF_BASE,default=30s"`
+		PushBackoffMax   time.Duration `env:"PUSH_BACKOFF_MAX,default=1h"`
+		PushLeaseTimeout time.Duration `env:"PUSH_LEASE_TIMEOUT,default=2m"`
+
+		StreamMaxConns  int           `env:"STREAM_MAX_CONNECTIONS,default=5"`
+		StreamHeartbeat time.Duration `env:"STREAM_HEARTBEAT,default=25s"`
+
+		CampaignPoll  time.Duration `env:"CAMPAIGN_POLL_INTERVAL,default=30s"`
+		CampaignLease time.Duration `env:"CAMPAIGN_LEASE_TIMEOUT,default=10m"`
+		CampaignBatch int           `env:"CAMPAIGN_BATCH_SIZE,default=500"`
+
+		DigestInterval time.Duration `env:"DIGEST_INTERVAL,default=24h"`
+		DigestPoll     time.Duration `env:"DIGEST_POLL_INTERVAL,default=5m"`
+		DigestBatch    int           `env:"DIGEST_BATCH_SIZE,default=100"`
+
+		ReadRetention   int           `env:"NOTIFICATION_READ_RETENTION_DAYS,default=0"`
+		PushRetention   int           `env:"PUSH_RETENTION_DAYS,default=0"`
+		PurgeInterval   time.Duration `env:"PURGE_INTERVAL,default=1h"`
+		PurgeBatch      int           `env:"PURGE_BATCH_SIZE,default=1000"`
+		PurgeArchive    string        `env:"PURGE_ARCHIVE"`
+		PurgeArchiveDir string        `env:"PURGE_ARCHIVE_DIR,default=archive"`
 	}
 )
 
@@ -59,5 +116,21 @@ func loadEnvFile(filenames ...string) Config {
 		log.Fatal(errormessage.ErrFailedToLoadEnvVariableText, zap.Error(err))
 	}
 
+	if config.CursorSecret == "" {
+		config.CursorSecret = randomSecret()
+		log.Warn("CURSOR_SECRET is not set, using a random secret: pagination cursors do not survive a restart and are not accepted by other replicas")
+	}
+
 	return config
 }
+
+// randomSecret returns a random secret of 32 bytes, hex encoded. It is used in place of secrets that are not
+// configured, so they are never empty.
+func randomSecret() string {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal(errormessage.ErrFailedToGenerateSecretText, zap.Error(err))
+	}
+
+	return hex.EncodeToString(secret)
+}
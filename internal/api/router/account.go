@@ -3,28 +3,38 @@ package router
 import (
 	"github.com/arifai/zenith/internal/handler"
 	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/model"
 	"github.com/gin-gonic/gin"
 )
 
 // AccountRouter sets up routes for account operations, including registration, authorization, and current account info fetching.
-func AccountRouter(group *gin.RouterGroup, accountHandler *handler.AccountHandler, middleware *middleware.StrictAuthMiddleware) {
+// The session is public and authenticates the request only if it carries a token. Searching all accounts is reserved to
+// internal services.
+func AccountRouter(group *gin.RouterGroup, accountHandler *handler.AccountHandler, middleware *middleware.StrictAuthMiddleware, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) {
 	accountAuthGroup := group.Group("/auth/account")
 	accountGroup := group.Group("/account", middleware.StrictAuth())
 	meGroup := accountGroup.Group("/me")
 
 	setupAccountAuthRoutes(accountAuthGroup, accountHandler, middleware)
 	setupAccountRoutes(meGroup, accountHandler)
+
+	group.GET("/admin/accounts", apiKeyMiddleware.InternalAuth(model.ScopeAccountRead), accountHandler.Search)
 }
 
 func setupAccountAuthRoutes(g *gin.RouterGroup, accountHandler *handler.AccountHandler, middleware *middleware.StrictAuthMiddleware) {
 	g.POST("/registration", accountHandler.Register)
 	g.POST("/authorization", accountHandler.Authorization)
 	g.POST("/refresh", accountHandler.RefreshToken)
+	g.GET("/email/confirm", accountHandler.ConfirmEmailChange)
+	g.GET("/session", middleware.OptionalAuth(), accountHandler.Session)
 	g.POST("/unauthorization", middleware.StrictAuth(), accountHandler.Unauthorization)
 }
 
 func setupAccountRoutes(g *gin.RouterGroup, accountHandler *handler.AccountHandler) {
 	g.GET("", accountHandler.GetCurrent)
 	g.PUT("/update", accountHandler.Update)
-	g.Group("/update").PUT("/password", accountHandler.UpdatePassword)
+
+	updateGroup := g.Group("/update")
+	updateGroup.PUT("/password", accountHandler.UpdatePassword)
+	updateGroup.PUT("/avatar", accountHandler.UpdateAvatar)
 }
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/arifai/zenith/internal/handler"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyRouter sets up routes for managing the API keys of the current account.
+// These routes require a user access token, an API key cannot be used to manage API keys.
+func APIKeyRouter(group *gin.RouterGroup, apiKeyHandler *handler.APIKeyHandler, middleware *middleware.StrictAuthMiddleware) {
+	apiKeyGroup := group.Group("/account/me/api_keys", middleware.StrictAuth())
+
+	setupAPIKeyRoutes(apiKeyGroup, apiKeyHandler)
+}
+
+func setupAPIKeyRoutes(group *gin.RouterGroup, apiKeyHandler *handler.APIKeyHandler) {
+	group.GET("", apiKeyHandler.GetList)
+	group.POST("", apiKeyHandler.Create)
+	group.DELETE("/:id", apiKeyHandler.Revoke)
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/arifai/zenith/internal/handler"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// CampaignRouter sets up routes for scheduling and managing push campaigns, reserved to internal services.
+func CampaignRouter(group *gin.RouterGroup, campaignHandler *handler.CampaignHandler, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) {
+	campaignGroup := group.Group("/admin/campaigns", apiKeyMiddleware.InternalAuth(model.ScopeNotificationCampaign))
+
+	setupCampaignRoutes(campaignGroup, campaignHandler)
+}
+
+func setupCampaignRoutes(group *gin.RouterGroup, campaignHandler *handler.CampaignHandler) {
+	group.POST("", campaignHandler.Create)
+	group.GET("", campaignHandler.GetList)
+	group.GET("/:id", campaignHandler.Get)
+	group.POST("/:id/pause", campaignHandler.Pause)
+	group.POST("/:id/resume", campaignHandler.Resume)
+	group.POST("/:id/cancel", campaignHandler.Cancel)
+}
@@ -3,17 +3,34 @@ package router
 import (
 	"github.com/arifai/zenith/internal/handler"
 	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/model"
 	"github.com/gin-gonic/gin"
 )
 
 // NotificationRouter sets up routes for handling notification-related requests with required middleware.
-func NotificationRouter(group *gin.RouterGroup, notificationHandler *handler.NotificationHandler, middleware *middleware.StrictAuthMiddleware) {
-	notificationGroup := group.Group("/notification", middleware.StrictAuth())
+// Reading and changing notifications accepts either a user access token or an API key with the matching notification
+// scope, and every change is limited to the notifications of the authenticated account. Sending is reserved to internal
+// services. The stream also takes the access token from the query string, since browsers can not set headers on
+// EventSource and WebSocket requests.
+func NotificationRouter(group *gin.RouterGroup, notificationHandler *handler.NotificationHandler, middleware *middleware.StrictAuthMiddleware, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) {
+	notificationGroup := group.Group("/notification")
 
-	setupNotificationRoutes(notificationGroup, notificationHandler)
+	setupNotificationRoutes(notificationGroup, notificationHandler, middleware, apiKeyMiddleware)
 }
 
-func setupNotificationRoutes(group *gin.RouterGroup, notificationHandler *handler.NotificationHandler) {
-	group.GET("/list", notificationHandler.GetList)
-	group.POST("/mark_as_read", notificationHandler.MarkAsRead)
+func setupNotificationRoutes(group *gin.RouterGroup, notificationHandler *handler.NotificationHandler, middleware *middleware.StrictAuthMiddleware, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) {
+	readAuth := apiKeyMiddleware.APIKeyOrStrictAuth(middleware, model.ScopeNotificationRead)
+	writeAuth := apiKeyMiddleware.APIKeyOrStrictAuth(middleware, model.ScopeNotificationWrite)
+
+	group.GET("/list", readAuth, notificationHandler.GetList)
+	group.GET("/stream", middleware.QueryToken(), readAuth, notificationHandler.Stream)
+	group.GET("/unread_count", readAuth, notificationHandler.CountUnread)
+	group.POST("/mark_as_read", writeAuth, notificationHandler.MarkAsRead)
+	group.POST("/mark_as_unread", writeAuth, notificationHandler.MarkAsUnread)
+	group.POST("/mark_all_as_read", writeAuth, notificationHandler.MarkAllAsRead)
+	group.POST("/bulk_mark", writeAuth, notificationHandler.BulkMark)
+	group.PUT("/:id/archive", writeAuth, notificationHandler.Archive)
+	group.DELETE("/:id/archive", writeAuth, notificationHandler.Unarchive)
+	group.DELETE("/:id", writeAuth, notificationHandler.Delete)
+	group.POST("/send", apiKeyMiddleware.InternalAuth(model.ScopeNotificationSend), notificationHandler.Send)
 }
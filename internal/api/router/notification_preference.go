@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/arifai/zenith/internal/handler"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationPreferenceRouter sets up routes for managing the notification preferences of the current account.
+func NotificationPreferenceRouter(group *gin.RouterGroup, preferenceHandler *handler.NotificationPreferenceHandler, middleware *middleware.StrictAuthMiddleware) {
+	preferenceGroup := group.Group("/account/me/notification-preferences", middleware.StrictAuth())
+
+	setupNotificationPreferenceRoutes(preferenceGroup, preferenceHandler)
+}
+
+func setupNotificationPreferenceRoutes(group *gin.RouterGroup, preferenceHandler *handler.NotificationPreferenceHandler) {
+	group.GET("", preferenceHandler.Get)
+	group.PUT("", preferenceHandler.Update)
+}
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"github.com/arifai/zenith/internal/handler"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationTemplateRouter sets up routes for managing notification templates, reserved to internal services.
+func NotificationTemplateRouter(group *gin.RouterGroup, templateHandler *handler.NotificationTemplateHandler, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) {
+	templateGroup := group.Group("/admin/notification-templates", apiKeyMiddleware.InternalAuth(model.ScopeNotificationTemplate))
+
+	setupNotificationTemplateRoutes(templateGroup, templateHandler)
+}
+
+func setupNotificationTemplateRoutes(group *gin.RouterGroup, templateHandler *handler.NotificationTemplateHandler) {
+	group.GET("", templateHandler.GetList)
+	group.PUT("", templateHandler.Save)
+	group.DELETE("/:id", templateHandler.Delete)
+	group.GET("/:name/stats", templateHandler.Stats)
+}
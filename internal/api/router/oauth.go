@@ -0,0 +1,34 @@
+package router
+
+import (
+	"github.com/arifai/zenith/internal/handler"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthRouter sets up the routes of the OAuth2 authorization server. The token, revocation and introspection endpoints
+// authenticate the client itself, while the consent and client management routes require a user access token.
+func OAuthRouter(group *gin.RouterGroup, oauthHandler *handler.OAuthHandler, middleware *middleware.StrictAuthMiddleware) {
+	oauthGroup := group.Group("/oauth")
+
+	setupOAuthTokenRoutes(oauthGroup, oauthHandler)
+	setupOAuthAuthorizeRoutes(oauthGroup.Group("/authorize", middleware.StrictAuth()), oauthHandler)
+	setupOAuthClientRoutes(oauthGroup.Group("/clients", middleware.StrictAuth()), oauthHandler)
+}
+
+func setupOAuthTokenRoutes(group *gin.RouterGroup, oauthHandler *handler.OAuthHandler) {
+	group.POST("/token", oauthHandler.Token)
+	group.POST("/revoke", oauthHandler.Revoke)
+	group.POST("/introspect", oauthHandler.Introspect)
+}
+
+func setupOAuthAuthorizeRoutes(group *gin.RouterGroup, oauthHandler *handler.OAuthHandler) {
+	group.GET("", oauthHandler.PrepareConsent)
+	group.POST("", oauthHandler.Authorize)
+}
+
+func setupOAuthClientRoutes(group *gin.RouterGroup, oauthHandler *handler.OAuthHandler) {
+	group.GET("", oauthHandler.GetClients)
+	group.POST("", oauthHandler.RegisterClient)
+	group.DELETE("/:id", oauthHandler.RevokeClient)
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/arifai/zenith/internal/handler"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// PushReceiptRouter sets up routes for devices reporting the delivery, opening and dismissal of push notifications.
+// Reporting accepts either a user access token or an API key with the notification write scope, and is limited to the
+// pushes of the authenticated account.
+func PushReceiptRouter(group *gin.RouterGroup, receiptHandler *handler.PushReceiptHandler, middleware *middleware.StrictAuthMiddleware, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) {
+	receiptGroup := group.Group("/push")
+
+	setupPushReceiptRoutes(receiptGroup, receiptHandler, middleware, apiKeyMiddleware)
+}
+
+func setupPushReceiptRoutes(group *gin.RouterGroup, receiptHandler *handler.PushReceiptHandler, middleware *middleware.StrictAuthMiddleware, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) {
+	group.POST("/receipts", apiKeyMiddleware.APIKeyOrStrictAuth(middleware, model.ScopeNotificationWrite), receiptHandler.Record)
+}
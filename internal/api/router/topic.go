@@ -0,0 +1,28 @@
+package router
+
+import (
+	"github.com/arifai/zenith/internal/handler"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// TopicRouter sets up routes for managing the push topic subscriptions of the current account, and for broadcasting
+// pushes to topics, which is reserved to internal services.
+func TopicRouter(group *gin.RouterGroup, topicHandler *handler.TopicHandler, middleware *middleware.StrictAuthMiddleware, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) {
+	topicGroup := group.Group("/account/me/topics", middleware.StrictAuth())
+	broadcastGroup := group.Group("/admin/topics", apiKeyMiddleware.InternalAuth(model.ScopeNotificationSend))
+
+	setupTopicRoutes(topicGroup, topicHandler)
+	setupTopicBroadcastRoutes(broadcastGroup, topicHandler)
+}
+
+func setupTopicRoutes(group *gin.RouterGroup, topicHandler *handler.TopicHandler) {
+	group.GET("", topicHandler.GetList)
+	group.PUT("/:topic", topicHandler.Subscribe)
+	group.DELETE("/:topic", topicHandler.Unsubscribe)
+}
+
+func setupTopicBroadcastRoutes(group *gin.RouterGroup, topicHandler *handler.TopicHandler) {
+	group.POST("/broadcast", topicHandler.Broadcast)
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"github.com/arifai/zenith/internal/handler"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// WebPushRouter sets up routes for native Web Push. The VAPID public key is public, subscriptions require an access
+// token.
+func WebPushRouter(group *gin.RouterGroup, webPushHandler *handler.WebPushHandler, middleware *middleware.StrictAuthMiddleware) {
+	webPushGroup := group.Group("/push/web")
+
+	setupWebPushRoutes(webPushGroup, webPushHandler, middleware)
+}
+
+func setupWebPushRoutes(group *gin.RouterGroup, webPushHandler *handler.WebPushHandler, middleware *middleware.StrictAuthMiddleware) {
+	group.GET("/vapid_key", webPushHandler.GetPublicKey)
+	group.PUT("/subscription", middleware.StrictAuth(), webPushHandler.Subscribe)
+	group.DELETE("/subscription/:device_id", middleware.StrictAuth(), webPushHandler.Unsubscribe)
+}
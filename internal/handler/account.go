@@ -3,6 +3,9 @@ package handler
 import (
 	"github.com/arifai/zenith/internal/service"
 	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/internal/types/response"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
 	"github.com/arifai/zenith/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -106,6 +109,37 @@ func (a *AccountHandler) GetCurrent(ctx *gin.Context) {
 	a.response.Success(ctx, result)
 }
 
+// Session describes how the request was authenticated. It is public, so clients can check their access token and
+// anonymous requests are answered as well.
+func (a *AccountHandler) Session(ctx *gin.Context) {
+	principal := GetPrincipalFromContext(ctx)
+
+	a.response.Success(ctx, &response.AccountSessionResponse{
+		Authenticated: principal.IsAuthenticated(),
+		Method:        principal.Method,
+		AccountID:     principal.AccountID,
+		ClientID:      principal.ClientID,
+		Scopes:        principal.Scopes,
+	})
+}
+
+// Search handles the retrieval of a paginated, filtered list of accounts for internal services.
+func (a *AccountHandler) Search(ctx *gin.Context) {
+	paging, err := utils.ValidateQuery[common.Pagination](ctx)
+	if err != nil {
+		a.response.Error(ctx, err)
+		return
+	}
+
+	result, err := a.accountService.Search(paging)
+	if err != nil {
+		a.response.Error(ctx, err)
+		return
+	}
+
+	a.response.Success(ctx, result)
+}
+
 // Update handles the updating of an account's information.
 // It validates the request body, calls the account service to update the account,
 // and sends appropriate HTTP responses based on the outcome.
@@ -126,6 +160,52 @@ func (a *AccountHandler) Update(ctx *gin.Context) {
 	a.response.Success(ctx, account)
 }
 
+// ConfirmEmailChange handles the HTTP request sent from the confirmation link of a pending email change.
+// It validates the token query parameter and applies the change through the account service.
+func (a *AccountHandler) ConfirmEmailChange(ctx *gin.Context) {
+	query, err := utils.ValidateQuery[request.AccountConfirmEmailChangeRequest](ctx)
+	if err != nil {
+		a.response.Error(ctx, err)
+		return
+	}
+
+	account, err := a.accountService.ConfirmEmailChange(query)
+	if err != nil {
+		a.response.Error(ctx, err)
+		return
+	}
+
+	a.response.Success(ctx, account)
+}
+
+// UpdateAvatar handles the multipart upload of an account's avatar image from the "avatar" form field.
+// The account service validates, resizes and stores the image, and the updated account is returned.
+func (a *AccountHandler) UpdateAvatar(ctx *gin.Context) {
+	accountId := GetAccountIDFromContext(ctx)
+	fileHeader, err := ctx.FormFile("avatar")
+	if err != nil {
+		a.response.Error(ctx, errormessage.ErrAvatarFileRequired)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		a.response.Error(ctx, err)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	account, err := a.accountService.UpdateAvatar(accountId, file, fileHeader.Size)
+	if err != nil {
+		a.response.Error(ctx, err)
+		return
+	}
+
+	a.response.Success(ctx, account)
+}
+
 // UpdatePassword handles the HTTP request to update an account's password.
 // It retrieves the account ID from the context and validates the request body.
 // If validation passes, it calls the account service to update the password and sends an appropriate response.
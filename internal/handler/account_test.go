@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/internal/middleware"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFakeRedis starts a server speaking enough of the Redis protocol for the blacklist lookups of the authentication
+// middleware: GET returns the value stored for the key, every other command fails.
+func newFakeRedis(t *testing.T, values map[string]string) *redis.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeRedis(conn, values)
+		}
+	}()
+
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIndentity: true})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	return client
+}
+
+func serveFakeRedis(conn net.Conn, values map[string]string) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil || !strings.HasPrefix(line, "*") {
+			return
+		}
+
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, 0, n)
+		for range n {
+			if _, err := reader.ReadString('\n'); err != nil {
+				return
+			}
+			arg, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			args = append(args, strings.TrimSpace(arg))
+		}
+
+		reply := "-ERR unknown command\r\n"
+		if len(args) == 2 && strings.EqualFold(args[0], "GET") {
+			if value, ok := values[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			} else {
+				reply = "$-1\r\n"
+			}
+		}
+
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func newAccessToken(tokenType string) (string, *crypto.TokenPayload) {
+	now := time.Now()
+	payload := &crypto.TokenPayload{
+		Jti:       uuid.New(),
+		AccountID: uuid.New(),
+		DeviceID:  uuid.New(),
+		IssuedAt:  now,
+		NotBefore: now,
+		ExpiresAt: now.Add(time.Hour),
+		TokenType: tokenType,
+	}
+
+	return payload.GenerateToken(config.SecretKey), payload
+}
+
+func TestSessionWithOptionalAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	accessToken, payload := newAccessToken(crypto.AccessToken)
+	blacklistedToken, blacklisted := newAccessToken(crypto.AccessToken)
+	refreshToken, _ := newAccessToken(crypto.RefreshToken)
+
+	redisClient := newFakeRedis(t, map[string]string{blacklisted.Jti.String(): "blacklisted"})
+	authMiddleware := middleware.NewStrictAuthMiddleware(middleware.New(nil, redisClient))
+	accountHandler := NewAccountHandler(New(common.NewResponse()), nil)
+
+	engine := gin.New()
+	engine.GET("/session", authMiddleware.OptionalAuth(), accountHandler.Session)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantMethod    model.AuthMethod
+		wantAccountID *uuid.UUID
+	}{
+		{name: "anonymous", wantStatus: http.StatusOK, wantMethod: model.AnonymousAuth},
+		{name: "access token", authorization: "Bearer " + accessToken, wantStatus: http.StatusOK, wantMethod: model.AccessTokenAuth, wantAccountID: &payload.AccountID},
+		{name: "malformed header", authorization: "Token " + accessToken, wantStatus: http.StatusUnauthorized},
+		{name: "refresh token", authorization: "Bearer " + refreshToken, wantStatus: http.StatusUnauthorized},
+		{name: "blacklisted token", authorization: "Bearer " + blacklistedToken, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/session", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Result struct {
+					Authenticated bool             `json:"authenticated"`
+					Method        model.AuthMethod `json:"method"`
+					AccountID     *uuid.UUID       `json:"account_id"`
+				} `json:"result"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+
+			if body.Result.Method != tt.wantMethod || body.Result.Authenticated != (tt.wantMethod != model.AnonymousAuth) {
+				t.Errorf("session = %+v, want method %s", body.Result, tt.wantMethod)
+			}
+			if (body.Result.AccountID == nil) != (tt.wantAccountID == nil) || (tt.wantAccountID != nil && *body.Result.AccountID != *tt.wantAccountID) {
+				t.Errorf("account ID = %v, want %v", body.Result.AccountID, tt.wantAccountID)
+			}
+		})
+	}
+}
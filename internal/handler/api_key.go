@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"github.com/arifai/zenith/internal/service"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHandler handles HTTP requests for managing the API keys of the current account.
+type APIKeyHandler struct {
+	*Handler
+	apiKeyService service.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new instance of APIKeyHandler with the given Handler and APIKeyService.
+func NewAPIKeyHandler(handler *Handler, apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{Handler: handler, apiKeyService: apiKeyService}
+}
+
+// Create handles the creation of a new API key for the account specified in the context.
+// The plaintext key is part of the response and cannot be retrieved again.
+func (h *APIKeyHandler) Create(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	body, err := utils.ValidateBody[request.APIKeyCreateRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.apiKeyService.Create(accountID, body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Created(ctx, "API key successfully created", result)
+}
+
+// GetList retrieves the API keys of the account specified in the context.
+func (h *APIKeyHandler) GetList(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	result, err := h.apiKeyService.GetList(accountID)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Revoke revokes the API key identified by the "id" path parameter.
+func (h *APIKeyHandler) Revoke(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	founded, err := h.apiKeyService.Revoke(accountID, ctx.Param("id"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if !founded {
+		h.response.NotFound(ctx, errormessage.ErrAPIKeyNotFoundText)
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
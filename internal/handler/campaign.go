@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/service"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CampaignHandler handles HTTP requests of internal services scheduling and managing push campaigns.
+type CampaignHandler struct {
+	*Handler
+	campaignService service.CampaignService
+}
+
+// NewCampaignHandler creates a new instance of CampaignHandler with the given Handler and CampaignService.
+func NewCampaignHandler(handler *Handler, campaignService service.CampaignService) *CampaignHandler {
+	return &CampaignHandler{Handler: handler, campaignService: campaignService}
+}
+
+// Create schedules a new campaign from the request body.
+func (h *CampaignHandler) Create(ctx *gin.Context) {
+	body, err := utils.ValidateBody[request.CampaignCreateRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.campaignService.Create(body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Created(ctx, "campaign scheduled", result)
+}
+
+// GetList retrieves a paginated list of campaigns.
+func (h *CampaignHandler) GetList(ctx *gin.Context) {
+	paging, err := utils.ValidateQuery[common.Pagination](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.campaignService.GetList(paging)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Get retrieves a campaign and its delivery stats by its ID.
+func (h *CampaignHandler) Get(ctx *gin.Context) {
+	result, err := h.campaignService.Get(ctx.Param("id"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Pause pauses a scheduled campaign.
+func (h *CampaignHandler) Pause(ctx *gin.Context) {
+	h.transition(ctx, h.campaignService.Pause)
+}
+
+// Resume schedules a paused campaign again.
+func (h *CampaignHandler) Resume(ctx *gin.Context) {
+	h.transition(ctx, h.campaignService.Resume)
+}
+
+// Cancel cancels a scheduled or paused campaign.
+func (h *CampaignHandler) Cancel(ctx *gin.Context) {
+	h.transition(ctx, h.campaignService.Cancel)
+}
+
+// transition changes the status of the campaign in the path with the given service method.
+func (h *CampaignHandler) transition(ctx *gin.Context, change func(id string) (*model.Campaign, error)) {
+	result, err := change(ctx.Param("id"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
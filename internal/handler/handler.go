@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"github.com/arifai/zenith/internal/model"
 	"github.com/arifai/zenith/pkg/common"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -17,7 +18,7 @@ func New(response *common.Response) *Handler {
 }
 
 // GetAccountIDFromContext retrieves the account ID from the provided gin.Context.
-// If the account ID does not exist in the context, an empty string is returned.
+// If the account ID does not exist in the context, for example on anonymous requests passing OptionalAuth, nil is returned.
 func GetAccountIDFromContext(ctx *gin.Context) *uuid.UUID {
 	id, exists := ctx.Get("account_id")
 	if !exists {
@@ -31,3 +32,19 @@ func GetAccountIDFromContext(ctx *gin.Context) *uuid.UUID {
 
 	return accountId
 }
+
+// GetPrincipalFromContext retrieves the principal of the request from the provided gin.Context, describing how the
+// request was authenticated. Requests that did not pass any authentication middleware are anonymous.
+func GetPrincipalFromContext(ctx *gin.Context) *model.Principal {
+	value, exists := ctx.Get("principal")
+	if !exists {
+		return model.AnonymousPrincipal()
+	}
+
+	principal, ok := value.(*model.Principal)
+	if !ok {
+		return model.AnonymousPrincipal()
+	}
+
+	return principal
+}
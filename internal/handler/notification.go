@@ -3,28 +3,32 @@ package handler
 import (
 	"github.com/arifai/zenith/internal/service"
 	"github.com/arifai/zenith/internal/types/request"
-	"github.com/arifai/zenith/pkg/common"
 	"github.com/arifai/zenith/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // NotificationHandler is responsible for handling notification-related HTTP requests.
 type NotificationHandler struct {
 	*Handler
 	notificationService service.NotificationService
+	streamService       service.NotificationStreamService
 }
 
-// NewNotificationHandler creates a new instance of NotificationHandler with the given Handler and NotificationService.
-func NewNotificationHandler(handler *Handler, notificationService service.NotificationService) *NotificationHandler {
+// NewNotificationHandler creates a new instance of NotificationHandler with the given Handler, NotificationService and
+// NotificationStreamService.
+func NewNotificationHandler(handler *Handler, notificationService service.NotificationService, streamService service.NotificationStreamService) *NotificationHandler {
 	return &NotificationHandler{
 		Handler:             handler,
 		notificationService: notificationService,
+		streamService:       streamService,
 	}
 }
 
-// GetList retrieves a paginated list of notifications for the account specified in the context.
+// GetList retrieves a paginated list of notifications for the account specified in the context, from its inbox or,
+// with the archived query parameter, from its archive.
 func (h *NotificationHandler) GetList(ctx *gin.Context) {
-	paging, err := utils.ValidateQuery[common.Pagination](ctx)
+	query, err := utils.ValidateQuery[request.NotificationListRequest](ctx)
 	if err != nil {
 		h.response.Error(ctx, err)
 		return
@@ -36,7 +40,7 @@ func (h *NotificationHandler) GetList(ctx *gin.Context) {
 		return
 	}
 
-	entries, err := h.notificationService.GetList(accountID, paging)
+	entries, err := h.notificationService.GetList(accountID, &query.Pagination, query.Archived)
 	if err != nil {
 		h.response.Error(ctx, err)
 		return
@@ -45,15 +49,159 @@ func (h *NotificationHandler) GetList(ctx *gin.Context) {
 	h.response.Success(ctx, entries)
 }
 
-// MarkAsRead marks a specified notification as read.
+// MarkAsRead marks a specified notification of the account in the context as read.
 func (h *NotificationHandler) MarkAsRead(ctx *gin.Context) {
+	h.setRead(ctx, h.notificationService.MarkAsRead)
+}
+
+// MarkAsUnread marks a specified notification of the account in the context as unread.
+func (h *NotificationHandler) MarkAsUnread(ctx *gin.Context) {
+	h.setRead(ctx, h.notificationService.MarkAsUnread)
+}
+
+// MarkAllAsRead marks every notification of the account in the context as read.
+func (h *NotificationHandler) MarkAllAsRead(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	result, err := h.notificationService.MarkAllAsRead(accountID)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// BulkMark sets the read state of the notifications listed in the request body.
+func (h *NotificationHandler) BulkMark(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	body, err := utils.ValidateBody[request.NotificationBulkMarkRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.notificationService.BulkMark(accountID, body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Archive moves the notification identified by the "id" path parameter into the archive.
+func (h *NotificationHandler) Archive(ctx *gin.Context) {
+	h.setArchived(ctx, true)
+}
+
+// Unarchive moves the notification identified by the "id" path parameter back into the inbox.
+func (h *NotificationHandler) Unarchive(ctx *gin.Context) {
+	h.setArchived(ctx, false)
+}
+
+// Delete deletes the notification identified by the "id" path parameter.
+func (h *NotificationHandler) Delete(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	founded, err := h.notificationService.Delete(accountID, ctx.Param("id"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if !founded {
+		h.response.NotFound(ctx, "notification not found")
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
+
+// CountUnread retrieves the number of unread notifications in the inbox of the account specified in the context.
+func (h *NotificationHandler) CountUnread(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	result, err := h.notificationService.CountUnread(accountID)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Send adds the notification in the request body to the inbox of its account and pushes it to the devices of the account.
+func (h *NotificationHandler) Send(ctx *gin.Context) {
+	body, err := utils.ValidateBody[request.NotificationSendRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.notificationService.Send(body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Created(ctx, "Notification successfully sent", result)
+}
+
+// setRead sets the read state of the notification in the request body through the given service method.
+func (h *NotificationHandler) setRead(ctx *gin.Context, mark func(accountID *uuid.UUID, id string) (bool, error)) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
 	body, err := utils.ValidateBody[request.NotificationMarkAsReadRequest](ctx)
 	if err != nil {
 		h.response.Error(ctx, err)
 		return
 	}
 
-	founded, err := h.notificationService.MarkAsRead(body.ID)
+	founded, err := mark(accountID, body.ID)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if !founded {
+		h.response.NotFound(ctx, "notification not found")
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
+
+// setArchived archives or unarchives the notification identified by the "id" path parameter.
+func (h *NotificationHandler) setArchived(ctx *gin.Context, archived bool) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	founded, err := h.notificationService.Archive(accountID, ctx.Param("id"), archived)
 	if err != nil {
 		h.response.Error(ctx, err)
 		return
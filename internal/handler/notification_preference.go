@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"github.com/arifai/zenith/internal/service"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationPreferenceHandler handles HTTP requests for managing the notification preferences of the current account.
+type NotificationPreferenceHandler struct {
+	*Handler
+	preferenceService service.NotificationPreferenceService
+}
+
+// NewNotificationPreferenceHandler creates a new instance of NotificationPreferenceHandler with the given Handler and
+// NotificationPreferenceService.
+func NewNotificationPreferenceHandler(handler *Handler, preferenceService service.NotificationPreferenceService) *NotificationPreferenceHandler {
+	return &NotificationPreferenceHandler{Handler: handler, preferenceService: preferenceService}
+}
+
+// Get retrieves the notification preferences of the account specified in the context.
+func (h *NotificationPreferenceHandler) Get(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	result, err := h.preferenceService.Get(accountID)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Update replaces the notification preferences of the account specified in the context with the request body.
+func (h *NotificationPreferenceHandler) Update(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	body, err := utils.ValidateBody[request.NotificationPreferenceUpdateRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.preferenceService.Update(accountID, body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
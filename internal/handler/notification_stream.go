@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/service"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// streamWriteTimeout bounds writing a single event to a WebSocket.
+const streamWriteTimeout = time.Second * 10
+
+// Stream streams the notification events of the account specified in the context as server-sent events, or over a
+// WebSocket when the request asks for an upgrade. Clients resume after the event given in the Last-Event-ID header or
+// the last_event_id query parameter.
+func (h *NotificationHandler) Stream(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx.Request.Context())
+	defer cancel()
+
+	stream, err := h.streamService.Open(streamCtx, accountID, lastEventID)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if strings.EqualFold(ctx.GetHeader("Upgrade"), "websocket") {
+		streamWebSocket(ctx, stream, cancel)
+		return
+	}
+
+	streamServerSentEvents(ctx, stream)
+}
+
+// streamServerSentEvents writes the events of the stream in the text/event-stream format. Heartbeats are comments,
+// which keep proxies from closing the idle connection without reaching the EventSource of the client.
+func streamServerSentEvents(ctx *gin.Context, stream *service.NotificationStream) {
+	header := ctx.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	for event := range stream.Events {
+		var err error
+		if event.Type == model.EventHeartbeat {
+			_, err = io.WriteString(ctx.Writer, ": heartbeat\n\n")
+		} else {
+			_, err = fmt.Fprintf(ctx.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+		}
+		if err != nil {
+			return
+		}
+
+		ctx.Writer.Flush()
+	}
+}
+
+// streamWebSocket upgrades the connection and sends every event of the stream as a JSON text message. Messages of the
+// client are discarded, reading them only detects when the client goes away.
+func streamWebSocket(ctx *gin.Context, stream *service.NotificationStream, cancel context.CancelFunc) {
+	// The stream is authenticated by token rather than cookies, so requests from other origins are accepted.
+	server := websocket.Server{Handler: func(conn *websocket.Conn) {
+		defer conn.Close()
+
+		go func() {
+			defer cancel()
+			_, _ = io.Copy(io.Discard, conn)
+		}()
+
+		for event := range stream.Events {
+			_ = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := websocket.JSON.Send(conn, event); err != nil {
+				return
+			}
+		}
+	}}
+
+	server.ServeHTTP(ctx.Writer, ctx.Request)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/arifai/zenith/internal/service"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationTemplateHandler handles HTTP requests of internal services managing notification templates.
+type NotificationTemplateHandler struct {
+	*Handler
+	templateService service.NotificationTemplateService
+}
+
+// NewNotificationTemplateHandler creates a new instance of NotificationTemplateHandler with the given Handler and
+// NotificationTemplateService.
+func NewNotificationTemplateHandler(handler *Handler, templateService service.NotificationTemplateService) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{Handler: handler, templateService: templateService}
+}
+
+// GetList retrieves a paginated list of notification templates.
+func (h *NotificationTemplateHandler) GetList(ctx *gin.Context) {
+	paging, err := utils.ValidateQuery[common.Pagination](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.templateService.GetList(paging)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Save creates or replaces the notification template with the name and locale of the request body.
+func (h *NotificationTemplateHandler) Save(ctx *gin.Context) {
+	body, err := utils.ValidateBody[request.NotificationTemplateSaveRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.templateService.Save(body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Delete deletes a notification template by its ID.
+func (h *NotificationTemplateHandler) Delete(ctx *gin.Context) {
+	founded, err := h.templateService.Delete(ctx.Param("id"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if !founded {
+		h.response.NotFound(ctx, errormessage.ErrTemplateNotFoundText)
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
+
+// Stats retrieves the delivery and engagement stats of the pushes rendered from the template named by the "name" path
+// parameter.
+func (h *NotificationTemplateHandler) Stats(ctx *gin.Context) {
+	result, err := h.templateService.Stats(ctx.Param("name"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
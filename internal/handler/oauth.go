@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"errors"
+	"github.com/arifai/zenith/internal/service"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/internal/types/response"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+)
+
+// OAuthHandler handles HTTP requests of the OAuth2 authorization server. The token, revocation and introspection
+// endpoints answer with the plain JSON documents defined by the OAuth2 RFCs instead of the common response envelope.
+type OAuthHandler struct {
+	*Handler
+	oauthService service.OAuthService
+}
+
+// NewOAuthHandler creates a new instance of OAuthHandler with the given Handler and OAuthService.
+func NewOAuthHandler(handler *Handler, oauthService service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{Handler: handler, oauthService: oauthService}
+}
+
+// RegisterClient handles the registration of a new OAuth2 client owned by the account specified in the context.
+func (h *OAuthHandler) RegisterClient(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	body, err := utils.ValidateBody[request.OAuthClientCreateRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.oauthService.RegisterClient(accountID, body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Created(ctx, "OAuth client successfully registered", result)
+}
+
+// GetClients retrieves the OAuth2 clients registered by the account specified in the context.
+func (h *OAuthHandler) GetClients(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	result, err := h.oauthService.GetClients(accountID)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// RevokeClient revokes the OAuth2 client identified by the "id" path parameter.
+func (h *OAuthHandler) RevokeClient(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	founded, err := h.oauthService.RevokeClient(accountID, ctx.Param("id"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if !founded {
+		h.response.NotFound(ctx, errormessage.ErrOAuthClientNotFoundText)
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
+
+// PrepareConsent validates an authorization request given in the query and returns the data of the consent screen.
+func (h *OAuthHandler) PrepareConsent(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	body, err := utils.ValidateQuery[request.OAuthAuthorizeRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.oauthService.PrepareConsent(accountID, body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Authorize records the consent decision of the account and returns the redirect URI for the client.
+func (h *OAuthHandler) Authorize(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	body, err := utils.ValidateBody[request.OAuthAuthorizeDecisionRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.oauthService.Authorize(accountID, body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Token handles requests to the token endpoint.
+func (h *OAuthHandler) Token(ctx *gin.Context) {
+	body := new(request.OAuthTokenRequest)
+	if err := ctx.ShouldBind(body); err != nil {
+		h.oauthError(ctx, invalidOAuthRequest(err.Error()))
+		return
+	}
+	body.ClientID, body.ClientSecret = clientCredentials(ctx, body.ClientID, body.ClientSecret)
+
+	result, err := h.oauthService.Token(body)
+	if err != nil {
+		h.oauthError(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Pragma", "no-cache")
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Revoke handles requests to the token revocation endpoint.
+func (h *OAuthHandler) Revoke(ctx *gin.Context) {
+	body, ok := h.bindTokenAction(ctx)
+	if !ok {
+		return
+	}
+
+	if err := h.oauthService.Revoke(body); err != nil {
+		h.oauthError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// Introspect handles requests to the token introspection endpoint.
+func (h *OAuthHandler) Introspect(ctx *gin.Context) {
+	body, ok := h.bindTokenAction(ctx)
+	if !ok {
+		return
+	}
+
+	result, err := h.oauthService.Introspect(body)
+	if err != nil {
+		h.oauthError(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, result)
+}
+
+// bindTokenAction binds a revocation or introspection request, answering with an OAuth2 error if it is invalid.
+func (h *OAuthHandler) bindTokenAction(ctx *gin.Context) (*request.OAuthTokenActionRequest, bool) {
+	body := new(request.OAuthTokenActionRequest)
+	if err := ctx.ShouldBind(body); err != nil {
+		h.oauthError(ctx, invalidOAuthRequest(err.Error()))
+		return nil, false
+	}
+
+	if body.Token == "" {
+		h.oauthError(ctx, invalidOAuthRequest(errormessage.ErrMissingGrantParameterText+" 'token'"))
+		return nil, false
+	}
+	body.ClientID, body.ClientSecret = clientCredentials(ctx, body.ClientID, body.ClientSecret)
+
+	return body, true
+}
+
+// oauthError writes an OAuth2 error response. Errors other than service.OAuthError are reported as server_error.
+func (h *OAuthHandler) oauthError(ctx *gin.Context, err error) {
+	var oauthErr *service.OAuthError
+	if errors.As(err, &oauthErr) {
+		if oauthErr.Status == http.StatusUnauthorized {
+			ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		ctx.JSON(oauthErr.Status, response.OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, response.OAuthErrorResponse{Error: "server_error"})
+}
+
+// invalidOAuthRequest creates an invalid_request error with the given description.
+func invalidOAuthRequest(description string) *service.OAuthError {
+	return &service.OAuthError{Code: service.OAuthErrInvalidRequest, Description: description, Status: http.StatusBadRequest}
+}
+
+// clientCredentials returns the client credentials of the request. HTTP Basic authentication takes precedence over the
+// credentials given in the request body, as recommended by RFC 6749 section 2.3.1.
+func clientCredentials(ctx *gin.Context, bodyID, bodySecret string) (string, string) {
+	id, secret, ok := ctx.Request.BasicAuth()
+	if !ok {
+		return bodyID, bodySecret
+	}
+
+	if unescaped, err := url.QueryUnescape(id); err == nil {
+		id = unescaped
+	}
+	if unescaped, err := url.QueryUnescape(secret); err == nil {
+		secret = unescaped
+	}
+
+	return id, secret
+}
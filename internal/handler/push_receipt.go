@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/arifai/zenith/internal/service"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PushReceiptHandler handles HTTP requests of devices reporting what happened to the push notifications they received.
+type PushReceiptHandler struct {
+	*Handler
+	receiptService service.PushReceiptService
+}
+
+// NewPushReceiptHandler creates a new instance of PushReceiptHandler with the given Handler and PushReceiptService.
+func NewPushReceiptHandler(handler *Handler, receiptService service.PushReceiptService) *PushReceiptHandler {
+	return &PushReceiptHandler{Handler: handler, receiptService: receiptService}
+}
+
+// Record stores the push events in the request body for the account specified in the context.
+func (h *PushReceiptHandler) Record(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	body, err := utils.ValidateBody[request.PushReceiptRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.receiptService.Record(accountID, body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
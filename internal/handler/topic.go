@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"github.com/arifai/zenith/internal/service"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// TopicHandler handles HTTP requests for managing the push topic subscriptions of the current account and for
+// broadcasting pushes to topics.
+type TopicHandler struct {
+	*Handler
+	topicService service.TopicService
+}
+
+// NewTopicHandler creates a new instance of TopicHandler with the given Handler and TopicService.
+func NewTopicHandler(handler *Handler, topicService service.TopicService) *TopicHandler {
+	return &TopicHandler{Handler: handler, topicService: topicService}
+}
+
+// GetList retrieves the topic subscriptions of the account specified in the context.
+func (h *TopicHandler) GetList(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	result, err := h.topicService.GetList(accountID)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Subscribe subscribes the account specified in the context to the topic given by the "topic" path parameter.
+func (h *TopicHandler) Subscribe(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	if err := h.topicService.Subscribe(accountID, ctx.Param("topic")); err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
+
+// Unsubscribe unsubscribes the account specified in the context from the topic given by the "topic" path parameter.
+func (h *TopicHandler) Unsubscribe(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	founded, err := h.topicService.Unsubscribe(accountID, ctx.Param("topic"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if !founded {
+		h.response.NotFound(ctx, errormessage.ErrTopicNotFoundText)
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
+
+// Broadcast enqueues a push to the devices subscribed to the topic or matching the condition in the request body.
+func (h *TopicHandler) Broadcast(ctx *gin.Context) {
+	body, err := utils.ValidateBody[request.PushBroadcastRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	result, err := h.topicService.Broadcast(body)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Created(ctx, "Push successfully broadcast", result)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"github.com/arifai/zenith/internal/service"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// WebPushHandler handles HTTP requests for managing the native Web Push subscriptions of browsers.
+type WebPushHandler struct {
+	*Handler
+	webPushService service.WebPushService
+}
+
+// NewWebPushHandler creates a new instance of WebPushHandler with the given Handler and WebPushService.
+func NewWebPushHandler(handler *Handler, webPushService service.WebPushService) *WebPushHandler {
+	return &WebPushHandler{Handler: handler, webPushService: webPushService}
+}
+
+// GetPublicKey returns the VAPID public key browsers subscribe with.
+func (h *WebPushHandler) GetPublicKey(ctx *gin.Context) {
+	result, err := h.webPushService.GetPublicKey()
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, result)
+}
+
+// Subscribe stores the Web Push subscription in the request body for the account specified in the context.
+func (h *WebPushHandler) Subscribe(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	body, err := utils.ValidateBody[request.WebPushSubscriptionRequest](ctx)
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if err := h.webPushService.Subscribe(accountID, body); err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
+
+// Unsubscribe removes the Web Push subscription of the device given by the "device_id" path parameter for the account
+// specified in the context.
+func (h *WebPushHandler) Unsubscribe(ctx *gin.Context) {
+	accountID := GetAccountIDFromContext(ctx)
+	if accountID == nil {
+		h.response.NotFound(ctx, "Account ID not found in context")
+		return
+	}
+
+	founded, err := h.webPushService.Unsubscribe(accountID, ctx.Param("device_id"))
+	if err != nil {
+		h.response.Error(ctx, err)
+		return
+	}
+
+	if !founded {
+		h.response.NotFound(ctx, errormessage.ErrSubscriptionNotFoundText)
+		return
+	}
+
+	h.response.Success(ctx, nil)
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/crypto"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"slices"
+	"time"
+)
+
+// APIKeyAuthMiddleware struct provides methods for authenticating services through API keys and enforcing their scopes.
+type APIKeyAuthMiddleware struct {
+	*Middleware
+	apiKeyRepo repository.APIKeyRepository
+}
+
+const (
+	// APIKeyHeader is the request header carrying the API key.
+	APIKeyHeader = "X-API-Key"
+
+	// lastUsedInterval throttles how often the last used time of an API key is written.
+	lastUsedInterval = time.Minute
+)
+
+// NewAPIKeyAuthMiddleware initializes and returns an APIKeyAuthMiddleware with the provided Middleware and APIKeyRepository.
+func NewAPIKeyAuthMiddleware(middleware *Middleware, apiKeyRepo repository.APIKeyRepository) *APIKeyAuthMiddleware {
+	return &APIKeyAuthMiddleware{Middleware: middleware, apiKeyRepo: apiKeyRepo}
+}
+
+// APIKeyAuth is a middleware function that validates the API key from the X-API-Key header and requires all given scopes.
+// Keys owned by an account populate the account ID of the context.
+func (a *APIKeyAuthMiddleware) APIKeyAuth(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		a.authenticate(ctx, scopes)
+	}
+}
+
+// APIKeyOrStrictAuth is a middleware function that accepts either credential. Requests carrying the X-API-Key header are
+// authenticated as in APIKeyAuth, all other requests must pass ScopedAuth with the same scopes.
+func (a *APIKeyAuthMiddleware) APIKeyOrStrictAuth(strict *StrictAuthMiddleware, scopes ...string) gin.HandlerFunc {
+	strictAuth := strict.ScopedAuth(scopes...)
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader(APIKeyHeader) == "" {
+			strictAuth(ctx)
+			return
+		}
+
+		a.authenticate(ctx, scopes)
+	}
+}
+
+// InternalAuth is a middleware function for internal endpoints. It accepts only API keys of internal services, which are
+// owned by an organization, and requires all given scopes.
+func (a *APIKeyAuthMiddleware) InternalAuth(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		a.authenticate(ctx, scopes, model.OrganizationOwner)
+	}
+}
+
+// authenticate validates the API key of the request, checks its owner type if any is given and its scopes, and continues
+// the chain or aborts it.
+func (a *APIKeyAuthMiddleware) authenticate(ctx *gin.Context, scopes []string, ownerTypes ...model.OwnerType) {
+	var response common.Response
+	apiKey, err := a.validateAndExtractAPIKey(ctx)
+	if err != nil {
+		response.Unauthorized(ctx, []utils.IError{}, err.Error())
+		ctx.Abort()
+		return
+	}
+
+	if len(ownerTypes) > 0 && !slices.Contains(ownerTypes, apiKey.OwnerType) {
+		response.Forbidden(ctx, errormessage.ErrInternalAPIKeyRequiredText)
+		ctx.Abort()
+		return
+	}
+
+	for _, scope := range scopes {
+		if !apiKey.HasScope(scope) {
+			response.Forbidden(ctx, errormessage.ErrInsufficientScopeText)
+			ctx.Abort()
+			return
+		}
+	}
+
+	principal := &model.Principal{Method: model.APIKeyAuth, APIKey: apiKey, Scopes: apiKey.Scopes}
+	if apiKey.OwnerType == model.AccountOwner {
+		principal.AccountID = &apiKey.OwnerID
+	}
+	setPrincipal(ctx, principal)
+	ctx.Set("api_key", apiKey)
+	ctx.Next()
+}
+
+// validateAndExtractAPIKey looks the API key of the request up by its prefix, verifies it and records its usage.
+func (a *APIKeyAuthMiddleware) validateAndExtractAPIKey(ctx *gin.Context) (*model.APIKey, error) {
+	key := ctx.GetHeader(APIKeyHeader)
+	if key == "" {
+		return nil, errormessage.ErrMissingAPIKey
+	}
+
+	prefix, err := crypto.ParseAPIKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := a.apiKeyRepo.FindByPrefix(prefix)
+	if err != nil {
+		return nil, errormessage.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if !crypto.VerifyAPIKey(key, apiKey.KeyHash) || !apiKey.Usable(now) {
+		return nil, errormessage.ErrInvalidAPIKey
+	}
+
+	if err := a.apiKeyRepo.TouchLastUsed(apiKey.ID, now, lastUsedInterval); err != nil {
+		log.Error(errormessage.ErrFailedToTouchAPIKeyText, zap.String("prefix", prefix), zap.Error(err))
+	}
+
+	return apiKey, nil
+}
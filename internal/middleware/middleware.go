@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"github.com/arifai/zenith/cmd/wire/logger"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/gin-gonic/gin"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -11,7 +14,17 @@ type Middleware struct {
 	redis *redis.Client
 }
 
+var log = logger.ProvideLogger()
+
 // New initializes and returns a new Middleware struct with the provided database, Redis client, and account repository.
 func New(db *gorm.DB, redis *redis.Client) *Middleware {
 	return &Middleware{db: db, redis: redis}
 }
+
+// setPrincipal stores the principal of the request in the context, along with its account ID if there is one.
+func setPrincipal(ctx *gin.Context, principal *model.Principal) {
+	ctx.Set("principal", principal)
+	if principal.AccountID != nil {
+		ctx.Set("account_id", principal.AccountID)
+	}
+}
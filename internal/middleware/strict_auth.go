@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/internal/model"
 	"github.com/arifai/zenith/pkg/common"
 	"github.com/arifai/zenith/pkg/crypto"
 	"github.com/arifai/zenith/pkg/errormessage"
@@ -11,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"slices"
 	"strings"
 )
 
@@ -31,13 +33,76 @@ func (s *StrictAuthMiddleware) StrictAuth() gin.HandlerFunc {
 			ctx.Abort()
 			return
 		} else {
-			ctx.Set("account_id", id)
+			setPrincipal(ctx, &model.Principal{AccountID: id, Method: model.AccessTokenAuth})
 			ctx.Next()
 			return
 		}
 	}
 }
 
+// OptionalAuth is a middleware function for endpoints that are public but personalized for signed-in accounts.
+// Requests without an authorization header continue anonymously, while present tokens must be valid access tokens
+// of an account or an OAuth2 client; malformed, expired or blacklisted tokens are rejected.
+func (s *StrictAuthMiddleware) OptionalAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetHeader("Authorization") == "" {
+			setPrincipal(ctx, model.AnonymousPrincipal())
+			ctx.Next()
+			return
+		}
+
+		var response common.Response
+		tokenPayload, err := s.validateToken(ctx, crypto.AccessToken, crypto.OAuthAccessToken)
+		if err != nil {
+			response.Unauthorized(ctx, []utils.IError{}, err.Error())
+			ctx.Abort()
+			return
+		}
+
+		setPrincipal(ctx, tokenPrincipal(tokenPayload))
+		ctx.Next()
+	}
+}
+
+// ScopedAuth is a middleware function that accepts the access tokens of accounts as well as OAuth2 access tokens
+// carrying all the given scopes. Tokens issued through the client credentials grant do not set an account.
+func (s *StrictAuthMiddleware) ScopedAuth(scopes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var response common.Response
+		tokenPayload, err := s.validateToken(ctx, crypto.AccessToken, crypto.OAuthAccessToken)
+		if err != nil {
+			response.Unauthorized(ctx, []utils.IError{}, err.Error())
+			ctx.Abort()
+			return
+		}
+
+		for _, scope := range scopes {
+			if tokenPayload.IsOAuth() && !tokenPayload.HasScope(scope) {
+				response.Forbidden(ctx, errormessage.ErrInsufficientScopeText)
+				ctx.Abort()
+				return
+			}
+		}
+
+		setPrincipal(ctx, tokenPrincipal(tokenPayload))
+		ctx.Next()
+	}
+}
+
+// QueryToken is a middleware function for endpoints opened by browser APIs that can not set headers, such as EventSource
+// and WebSocket. It moves the access token of the access_token query parameter into the authorization header, for the
+// authentication middleware that follows. Requests with an authorization header are left unchanged. The access log
+// of the server redacts the parameter, so the token is not written to it.
+func (s *StrictAuthMiddleware) QueryToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if token := ctx.Query("access_token"); token != "" && ctx.GetHeader("Authorization") == "" {
+			ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		ctx.Next()
+	}
+}
+
 // IsTokenBlacklisted checks if a given token's jti is present in the Redis blacklist.
 func (s *StrictAuthMiddleware) IsTokenBlacklisted(jti string) (bool, error) {
 	value, err := s.getRedisValue(jti)
@@ -52,6 +117,17 @@ func (s *StrictAuthMiddleware) IsTokenBlacklisted(jti string) (bool, error) {
 
 // validateAndExtractAccount validates the authorization header and extracts the associated account.
 func (s *StrictAuthMiddleware) validateAndExtractAccount(ctx *gin.Context) (*uuid.UUID, error) {
+	tokenPayload, err := s.validateToken(ctx, crypto.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenPayload.AccountID, nil
+}
+
+// validateToken validates the bearer token of the authorization header, which must be of one of the given token types
+// and must not be blacklisted. Tokens of OAuth2 clients are rejected once their client is revoked.
+func (s *StrictAuthMiddleware) validateToken(ctx *gin.Context, tokenTypes ...string) (*crypto.TokenPayload, error) {
 	authHeader := ctx.GetHeader("Authorization")
 	if authHeader == "" {
 		return nil, errormessage.ErrMissingAuthorizationHeader
@@ -65,7 +141,7 @@ func (s *StrictAuthMiddleware) validateAndExtractAccount(ctx *gin.Context) (*uui
 	tokenPayload, err := crypto.VerifyToken(tokenString, config.PublicKey)
 	if err != nil {
 		return nil, err
-	} else if tokenPayload.TokenType != crypto.AccessToken {
+	} else if !slices.Contains(tokenTypes, tokenPayload.TokenType) {
 		return nil, errormessage.ErrInvalidTokenType
 	}
 
@@ -76,7 +152,32 @@ func (s *StrictAuthMiddleware) validateAndExtractAccount(ctx *gin.Context) (*uui
 		return nil, errormessage.ErrInvalidAccessToken
 	}
 
-	return &tokenPayload.AccountID, nil
+	if tokenPayload.IsOAuth() {
+		var clients int64
+		if err := s.db.Model(&model.OAuthClient{}).
+			Where("id = ? AND revoked_at IS NULL", tokenPayload.ClientID).
+			Count(&clients).Error; err != nil {
+			return nil, err
+		} else if clients == 0 {
+			return nil, errormessage.ErrOAuthClientRevoked
+		}
+	}
+
+	return tokenPayload, nil
+}
+
+// tokenPrincipal creates the principal of a request authenticated with the given access token.
+func tokenPrincipal(tokenPayload *crypto.TokenPayload) *model.Principal {
+	if !tokenPayload.IsOAuth() {
+		return &model.Principal{AccountID: &tokenPayload.AccountID, Method: model.AccessTokenAuth}
+	}
+
+	principal := &model.Principal{Method: model.OAuthAuth, ClientID: &tokenPayload.ClientID, Scopes: tokenPayload.Scopes}
+	if tokenPayload.AccountID != uuid.Nil {
+		principal.AccountID = &tokenPayload.AccountID
+	}
+
+	return principal
 }
 
 // extractToken splits the authorization header to extract the token.
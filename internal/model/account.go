@@ -13,11 +13,13 @@ type (
 		FullName          string            `json:"full_name" gorm:"not null;column:full_name;type:varchar"`
 		Email             string            `json:"email" gorm:"not null;column:email;type:varchar;uniqueIndex:idx_account_email"`
 		Avatar            string            `json:"avatar" gorm:"column:avatar;type:varchar;default:null"`
+		AvatarThumbnails  map[string]string `json:"avatar_thumbnails" gorm:"column:avatar_thumbnails;type:jsonb;serializer:json"`
+		AvatarObjects     []string          `json:"-" gorm:"column:avatar_objects;type:jsonb;serializer:json"`
 		Active            bool              `json:"active" gorm:"column:active;type:boolean;default:false"`
-		FcmToken          string            `json:"fcm_token" gorm:"column:fcm_token;type:varchar;default:null"`
 		CreatedAt         time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
 		UpdatedAt         *time.Time        `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 		AccountPassHashed AccountPassHashed `json:"-" gorm:"foreignKey:AccountID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+		PendingEmail      string            `json:"pending_email,omitempty" gorm:"-"`
 	}
 
 	// AccountPassHashed represents a hashed password associated with an account.
@@ -28,4 +30,15 @@ type (
 		CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
 		UpdatedAt  *time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 	}
+
+	// AccountEmailChange represents a pending change of an account's email address that awaits confirmation
+	// from the new address. Only the hash of the confirmation token is stored.
+	AccountEmailChange struct {
+		ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		AccountID uuid.UUID `json:"account_id" gorm:"not null;column:account_id;type:uuid;index:idx_account_email_change_account_id,hash"`
+		NewEmail  string    `json:"new_email" gorm:"not null;column:new_email;type:varchar"`
+		TokenHash string    `json:"-" gorm:"not null;column:token_hash;type:varchar;uniqueIndex:idx_account_email_change_token_hash"`
+		ExpiresAt time.Time `json:"expires_at" gorm:"not null;column:expires_at;type:timestamp"`
+		CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+	}
 )
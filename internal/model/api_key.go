@@ -0,0 +1,75 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type (
+	OwnerType string
+
+	// APIKey represents a hashed, prefixed key used by services to authenticate without a user password.
+	// The key is owned by an account or an organization and is limited to the granted scopes.
+	APIKey struct {
+		ID         uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		OwnerType  OwnerType  `json:"owner_type" gorm:"not null;column:owner_type;type:varchar"`
+		OwnerID    uuid.UUID  `json:"owner_id" gorm:"not null;column:owner_id;type:uuid;index:idx_api_key_owner_id,hash"`
+		Name       string     `json:"name" gorm:"not null;column:name;type:varchar"`
+		Prefix     string     `json:"prefix" gorm:"not null;column:prefix;type:varchar;uniqueIndex:idx_api_key_prefix"`
+		KeyHash    string     `json:"-" gorm:"not null;column:key_hash;type:varchar"`
+		Scopes     []string   `json:"scopes" gorm:"not null;column:scopes;type:jsonb;serializer:json"`
+		ExpiresAt  *time.Time `json:"expires_at" gorm:"column:expires_at;type:timestamp"`
+		LastUsedAt *time.Time `json:"last_used_at" gorm:"column:last_used_at;type:timestamp"`
+		RevokedAt  *time.Time `json:"revoked_at" gorm:"column:revoked_at;type:timestamp"`
+		CreatedAt  time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+	}
+)
+
+const (
+	AccountOwner      OwnerType = "account"
+	OrganizationOwner OwnerType = "organization"
+
+	ScopeAccountRead       = "account:read"
+	ScopeNotificationRead  = "notification:read"
+	ScopeNotificationWrite = "notification:write"
+
+	// ScopeNotificationSend allows sending notifications to any account. It is only honored for keys of internal
+	// services, which are owned by an organization.
+	ScopeNotificationSend = "notification:send"
+
+	// ScopeNotificationTemplate allows managing notification templates, it is only honored for keys of internal
+	// services as well.
+	ScopeNotificationTemplate = "notification:template"
+
+	// ScopeNotificationCampaign allows scheduling and managing push campaigns, for keys of internal services only.
+	ScopeNotificationCampaign = "notification:campaign"
+)
+
+// Scopes lists every scope an API key can be granted.
+var Scopes = []string{
+	ScopeAccountRead,
+	ScopeNotificationRead,
+	ScopeNotificationWrite,
+	ScopeNotificationSend,
+	ScopeNotificationTemplate,
+	ScopeNotificationCampaign,
+}
+
+// KnownScope reports whether the scope is one of Scopes.
+func KnownScope(scope string) bool {
+	return containsAll(Scopes, []string{scope})
+}
+
+// HasScope reports whether the API key was granted the given scope.
+func (k *APIKey) HasScope(scope string) bool {
+	return containsAll(k.Scopes, []string{scope})
+}
+
+// Usable reports whether the API key is neither revoked nor expired at the given time.
+func (k *APIKey) Usable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
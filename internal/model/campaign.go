@@ -0,0 +1,226 @@
+package model
+
+import (
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/google/uuid"
+	"slices"
+	"time"
+)
+
+type (
+	CampaignStatus string
+
+	CampaignFrequency string
+
+	// CampaignRecurrence repeats a campaign every Interval days, weeks or months, starting from its first occurrence.
+	// Weekly campaigns run on the given Weekdays, lower-case English day names, or on the weekday of the first
+	// occurrence without any. Monthly campaigns skip months without the day of the first occurrence. Until is a wall
+	// clock time like the occurrences, no occurrence is scheduled after it.
+	CampaignRecurrence struct {
+		Frequency CampaignFrequency `json:"frequency"`
+		Interval  int               `json:"interval"`
+		Weekdays  []string          `json:"weekdays,omitempty"`
+		Until     *time.Time        `json:"until,omitempty"`
+	}
+
+	// Campaign is a push notification sent to an audience of accounts at a scheduled time, once or recurring. The
+	// audience are the accounts listed in AccountIDs, those matching the AudienceFilters, or those listed and matching
+	// both. StartsAt and Occurrence are wall clock times, kept in UTC: in the Timezone of the campaign, or in the
+	// timezone of every recipient if LocalTime is set, so "Tuesday 9:00" reaches every account at 9:00 local time.
+	// Occurrence is the next wall clock time the campaign runs at and NextRunAt the instant the scheduler picks it up,
+	// which for LocalTime campaigns is when the first timezone reaches the occurrence. Every run enqueues one push per
+	// active device of each recipient, linked to the campaign through CampaignID. The copy is either given or rendered
+	// from the Template in the locale of every recipient. Schedulers lease the campaign they run through LockedBy and
+	// LockedUntil, and enqueue the pushes of a run batch by batch, recording the last account enqueued in RunCursor so an
+	// interrupted run resumes after it.
+	Campaign struct {
+		ID              uuid.UUID              `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		Name            string                 `json:"name" gorm:"not null;column:name;type:varchar"`
+		Status          CampaignStatus         `json:"status" gorm:"not null;column:status;type:varchar;default:'scheduled';index:idx_campaign_due,priority:1"`
+		Category        NotificationCategory   `json:"category" gorm:"not null;column:category;type:varchar;default:'marketing'"`
+		Template        string                 `json:"template,omitempty" gorm:"column:template;type:varchar"`
+		Variables       map[string]interface{} `json:"variables,omitempty" gorm:"column:variables;type:jsonb;serializer:json"`
+		Title           string                 `json:"title" gorm:"column:title;type:varchar"`
+		Message         string                 `json:"message" gorm:"column:message;type:varchar"`
+		Image           string                 `json:"image,omitempty" gorm:"column:image;type:varchar"`
+		Data            map[string]string      `json:"data,omitempty" gorm:"column:data;type:jsonb;serializer:json"`
+		Options         *push.Options          `json:"options,omitempty" gorm:"column:options;type:jsonb;serializer:json"`
+		AccountIDs      []uuid.UUID            `json:"account_ids,omitempty" gorm:"column:account_ids;type:jsonb;serializer:json"`
+		AudienceFilters []string               `json:"audience_filters,omitempty" gorm:"column:audience_filters;type:jsonb;serializer:json"`
+		StartsAt        time.Time              `json:"starts_at" gorm:"not null;column:starts_at;type:timestamp"`
+		Timezone        string                 `json:"timezone" gorm:"not null;column:timezone;type:varchar;default:'UTC'"`
+		LocalTime       bool                   `json:"local_time" gorm:"not null;column:local_time;type:boolean;default:false"`
+		Recurrence      *CampaignRecurrence    `json:"recurrence,omitempty" gorm:"column:recurrence;type:jsonb;serializer:json"`
+		Occurrence      time.Time              `json:"occurrence" gorm:"not null;column:occurrence;type:timestamp"`
+		NextRunAt       *time.Time             `json:"next_run_at" gorm:"column:next_run_at;type:timestamp;index:idx_campaign_due,priority:2"`
+		LockedBy        *uuid.UUID             `json:"-" gorm:"column:locked_by;type:uuid"`
+		LockedUntil     *time.Time             `json:"-" gorm:"column:locked_until;type:timestamp"`
+		RunCursor       *uuid.UUID             `json:"-" gorm:"column:run_cursor;type:uuid"`
+		Runs            int                    `json:"runs" gorm:"not null;column:runs;type:integer;default:0"`
+		Recipients      int64                  `json:"recipients" gorm:"not null;column:recipients;type:bigint;default:0"`
+		Skipped         int64                  `json:"skipped" gorm:"not null;column:skipped;type:bigint;default:0"`
+		LastRunAt       *time.Time             `json:"last_run_at" gorm:"column:last_run_at;type:timestamp"`
+		CreatedAt       time.Time              `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+		UpdatedAt       *time.Time             `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	}
+
+	// CampaignStats holds the PushStats of a campaign, next to the recipients reached and the accounts skipped because
+	// they disabled pushes of the category or have no active device, over all runs.
+	CampaignStats struct {
+		Runs       int   `json:"runs"`
+		Recipients int64 `json:"recipients"`
+		Skipped    int64 `json:"skipped"`
+		PushStats
+	}
+)
+
+const (
+	CampaignScheduled CampaignStatus = "scheduled"
+	CampaignPaused    CampaignStatus = "paused"
+	CampaignCompleted CampaignStatus = "completed"
+	CampaignCanceled  CampaignStatus = "canceled"
+
+	FrequencyDaily   CampaignFrequency = "daily"
+	FrequencyWeekly  CampaignFrequency = "weekly"
+	FrequencyMonthly CampaignFrequency = "monthly"
+
+	// PushDataCampaignID is the data key of a push notification carrying the ID of its campaign.
+	PushDataCampaignID = "campaign_id"
+
+	// maxRecurrenceSteps bounds the search for the next occurrence of a recurrence.
+	maxRecurrenceSteps = 1000
+)
+
+var (
+	// earliestZone and latestZone are the first and the last timezones to reach a wall clock time.
+	earliestZone = time.FixedZone("UTC+14", 14*60*60)
+	latestZone   = time.FixedZone("UTC-12", -12*60*60)
+)
+
+// Next returns the first occurrence of the recurrence after the given occurrence, for a recurrence starting at start.
+// It returns false once the recurrence ends.
+func (r *CampaignRecurrence) Next(start, occurrence time.Time) (time.Time, bool) {
+	interval := max(1, r.Interval)
+
+	var next time.Time
+	found := false
+	switch r.Frequency {
+	case FrequencyDaily:
+		next, found = occurrence.AddDate(0, 0, interval), true
+	case FrequencyWeekly:
+		if len(r.Weekdays) == 0 {
+			next, found = occurrence.AddDate(0, 0, 7*interval), true
+			break
+		}
+
+		firstWeek := start.AddDate(0, 0, -int(start.Weekday()))
+		for step := 1; step <= maxRecurrenceSteps && !found; step++ {
+			candidate := occurrence.AddDate(0, 0, step)
+			week := int(candidate.AddDate(0, 0, -int(candidate.Weekday())).Sub(firstWeek).Hours()/24) / 7
+			if week%interval == 0 && slices.Contains(r.Weekdays, weekdayName(candidate.Weekday())) {
+				next, found = candidate, true
+			}
+		}
+	case FrequencyMonthly:
+		for step := 1; step <= maxRecurrenceSteps && !found; step++ {
+			candidate := start.AddDate(0, step*interval, 0)
+			if candidate.Day() == start.Day() && candidate.After(occurrence) {
+				next, found = candidate, true
+			}
+		}
+	}
+
+	if !found || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+// RunAt returns the instant the scheduler runs the given occurrence.
+func (c *Campaign) RunAt(occurrence time.Time) time.Time {
+	if c.LocalTime {
+		return inZone(occurrence, earliestZone)
+	}
+
+	return inZone(occurrence, c.location())
+}
+
+// DeliverAt returns the instant the given occurrence reaches a recipient in the given timezone. Campaigns not sent
+// in local time reach every recipient at the same instant.
+func (c *Campaign) DeliverAt(occurrence time.Time, timezone string) time.Time {
+	if !c.LocalTime {
+		return inZone(occurrence, c.location())
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		location = c.location()
+	}
+
+	return inZone(occurrence, location)
+}
+
+// Reschedule moves the occurrence past the occurrences that every recipient already missed at now, e.g. after the
+// campaign was paused, and schedules the next run. Campaigns that do not recur keep their occurrence and run right
+// away, resuming an interrupted run after its RunCursor. It returns false if the recurrence ended meanwhile.
+func (c *Campaign) Reschedule(now time.Time) bool {
+	if c.Recurrence != nil {
+		for c.lastDeliveryAt(c.Occurrence).Before(now) {
+			next, ok := c.Recurrence.Next(c.StartsAt, c.Occurrence)
+			if !ok {
+				return false
+			}
+			c.Occurrence, c.RunCursor = next, nil
+		}
+	}
+
+	runAt := c.RunAt(c.Occurrence).Local()
+	c.NextRunAt = &runAt
+
+	return true
+}
+
+// Advance moves the campaign to the occurrence after the current one, completing it if there is none.
+func (c *Campaign) Advance() {
+	c.RunCursor = nil
+	if c.Recurrence != nil {
+		if next, ok := c.Recurrence.Next(c.StartsAt, c.Occurrence); ok {
+			c.Occurrence = next
+			runAt := c.RunAt(next).Local()
+			c.NextRunAt = &runAt
+			return
+		}
+	}
+
+	c.Status, c.NextRunAt = CampaignCompleted, nil
+}
+
+// lastDeliveryAt returns the last instant the given occurrence reaches any recipient.
+func (c *Campaign) lastDeliveryAt(occurrence time.Time) time.Time {
+	if c.LocalTime {
+		return inZone(occurrence, latestZone)
+	}
+
+	return inZone(occurrence, c.location())
+}
+
+// location returns the timezone of the campaign, UTC if it is unknown.
+func (c *Campaign) location() *time.Location {
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// inZone returns the instant a wall clock time is reached in the given timezone.
+func inZone(wallClock time.Time, location *time.Location) time.Time {
+	return time.Date(wallClock.Year(), wallClock.Month(), wallClock.Day(), wallClock.Hour(), wallClock.Minute(), wallClock.Second(), 0, location)
+}
+
+// weekdayName returns the lower-case English name of a weekday.
+func weekdayName(weekday time.Weekday) string {
+	return [...]string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}[weekday]
+}
@@ -0,0 +1,330 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func wallClock(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	until := wallClock(2024, time.January, 2, 9, 0)
+
+	tests := []struct {
+		name       string
+		recurrence CampaignRecurrence
+		start      time.Time
+		occurrence time.Time
+		want       time.Time
+		wantOK     bool
+	}{
+		{
+			name:       "daily",
+			recurrence: CampaignRecurrence{Frequency: FrequencyDaily},
+			start:      wallClock(2024, time.January, 1, 9, 0),
+			occurrence: wallClock(2024, time.January, 1, 9, 0),
+			want:       wallClock(2024, time.January, 2, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "daily across the start of daylight saving time keeps the wall clock",
+			recurrence: CampaignRecurrence{Frequency: FrequencyDaily},
+			start:      wallClock(2024, time.March, 9, 2, 30),
+			occurrence: wallClock(2024, time.March, 9, 2, 30),
+			want:       wallClock(2024, time.March, 10, 2, 30),
+			wantOK:     true,
+		},
+		{
+			name:       "every third day",
+			recurrence: CampaignRecurrence{Frequency: FrequencyDaily, Interval: 3},
+			start:      wallClock(2024, time.January, 1, 9, 0),
+			occurrence: wallClock(2024, time.January, 1, 9, 0),
+			want:       wallClock(2024, time.January, 4, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "every other week on the weekday of the start",
+			recurrence: CampaignRecurrence{Frequency: FrequencyWeekly, Interval: 2},
+			start:      wallClock(2024, time.January, 1, 9, 0),
+			occurrence: wallClock(2024, time.January, 1, 9, 0),
+			want:       wallClock(2024, time.January, 15, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "every other week, next weekday of the same week",
+			recurrence: CampaignRecurrence{Frequency: FrequencyWeekly, Interval: 2, Weekdays: []string{"monday", "wednesday"}},
+			start:      wallClock(2024, time.January, 1, 9, 0),
+			occurrence: wallClock(2024, time.January, 1, 9, 0),
+			want:       wallClock(2024, time.January, 3, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "every other week, skips the week in between",
+			recurrence: CampaignRecurrence{Frequency: FrequencyWeekly, Interval: 2, Weekdays: []string{"monday", "wednesday"}},
+			start:      wallClock(2024, time.January, 1, 9, 0),
+			occurrence: wallClock(2024, time.January, 3, 9, 0),
+			want:       wallClock(2024, time.January, 15, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "weekly on sunday, which starts a week",
+			recurrence: CampaignRecurrence{Frequency: FrequencyWeekly, Weekdays: []string{"sunday", "saturday"}},
+			start:      wallClock(2024, time.January, 6, 9, 0),
+			occurrence: wallClock(2024, time.January, 6, 9, 0),
+			want:       wallClock(2024, time.January, 7, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "monthly on the 15th every quarter",
+			recurrence: CampaignRecurrence{Frequency: FrequencyMonthly, Interval: 3},
+			start:      wallClock(2024, time.January, 15, 9, 0),
+			occurrence: wallClock(2024, time.January, 15, 9, 0),
+			want:       wallClock(2024, time.April, 15, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "monthly on the 31st skips months without it",
+			recurrence: CampaignRecurrence{Frequency: FrequencyMonthly},
+			start:      wallClock(2024, time.January, 31, 9, 0),
+			occurrence: wallClock(2024, time.January, 31, 9, 0),
+			want:       wallClock(2024, time.March, 31, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "monthly on the 31st after march skips april",
+			recurrence: CampaignRecurrence{Frequency: FrequencyMonthly},
+			start:      wallClock(2024, time.January, 31, 9, 0),
+			occurrence: wallClock(2024, time.March, 31, 9, 0),
+			want:       wallClock(2024, time.May, 31, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "monthly on the 29th skips february of common years",
+			recurrence: CampaignRecurrence{Frequency: FrequencyMonthly},
+			start:      wallClock(2023, time.January, 29, 9, 0),
+			occurrence: wallClock(2023, time.January, 29, 9, 0),
+			want:       wallClock(2023, time.March, 29, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "monthly on the 29th keeps february of leap years",
+			recurrence: CampaignRecurrence{Frequency: FrequencyMonthly},
+			start:      wallClock(2024, time.January, 29, 9, 0),
+			occurrence: wallClock(2024, time.January, 29, 9, 0),
+			want:       wallClock(2024, time.February, 29, 9, 0),
+			wantOK:     true,
+		},
+		{
+			name:       "until is inclusive",
+			recurrence: CampaignRecurrence{Frequency: FrequencyDaily, Until: &until},
+			start:      wallClock(2024, time.January, 1, 9, 0),
+			occurrence: wallClock(2024, time.January, 1, 9, 0),
+			want:       until,
+			wantOK:     true,
+		},
+		{
+			name:       "ends after until",
+			recurrence: CampaignRecurrence{Frequency: FrequencyDaily, Until: &until},
+			start:      wallClock(2024, time.January, 1, 9, 0),
+			occurrence: until,
+		},
+		{
+			name:       "unknown frequency",
+			recurrence: CampaignRecurrence{Frequency: "yearly"},
+			start:      wallClock(2024, time.January, 1, 9, 0),
+			occurrence: wallClock(2024, time.January, 1, 9, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.recurrence.Next(tt.start, tt.occurrence)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCampaignRunAtAndDeliverAt(t *testing.T) {
+	tests := []struct {
+		name          string
+		campaign      Campaign
+		occurrence    time.Time
+		timezone      string
+		wantRunAt     time.Time
+		wantDeliverAt time.Time
+	}{
+		{
+			name:          "campaign timezone",
+			campaign:      Campaign{Timezone: "Asia/Jakarta"},
+			occurrence:    wallClock(2024, time.January, 1, 9, 0),
+			timezone:      "America/New_York",
+			wantRunAt:     wallClock(2024, time.January, 1, 2, 0),
+			wantDeliverAt: wallClock(2024, time.January, 1, 2, 0),
+		},
+		{
+			name:          "unknown campaign timezone falls back to UTC",
+			campaign:      Campaign{Timezone: "Mars/Olympus"},
+			occurrence:    wallClock(2024, time.January, 1, 9, 0),
+			wantRunAt:     wallClock(2024, time.January, 1, 9, 0),
+			wantDeliverAt: wallClock(2024, time.January, 1, 9, 0),
+		},
+		{
+			name:          "campaign timezone in daylight saving time",
+			campaign:      Campaign{Timezone: "America/New_York"},
+			occurrence:    wallClock(2024, time.July, 1, 9, 0),
+			wantRunAt:     wallClock(2024, time.July, 1, 13, 0),
+			wantDeliverAt: wallClock(2024, time.July, 1, 13, 0),
+		},
+		{
+			// A wall clock skipped by the transition is resolved with the daylight saving offset, 2:30 EDT being 1:30 EST.
+			name:          "skipped wall clock at the start of daylight saving time",
+			campaign:      Campaign{Timezone: "America/New_York"},
+			occurrence:    wallClock(2024, time.March, 10, 2, 30),
+			wantRunAt:     wallClock(2024, time.March, 10, 6, 30),
+			wantDeliverAt: wallClock(2024, time.March, 10, 6, 30),
+		},
+		{
+			// A wall clock repeated by the transition is resolved to its first instance, in daylight saving time.
+			name:          "repeated wall clock at the end of daylight saving time",
+			campaign:      Campaign{Timezone: "America/New_York"},
+			occurrence:    wallClock(2024, time.November, 3, 1, 30),
+			wantRunAt:     wallClock(2024, time.November, 3, 5, 30),
+			wantDeliverAt: wallClock(2024, time.November, 3, 5, 30),
+		},
+		{
+			name:          "local time runs when the earliest timezone reaches the occurrence",
+			campaign:      Campaign{Timezone: "UTC", LocalTime: true},
+			occurrence:    wallClock(2024, time.January, 1, 9, 0),
+			timezone:      "America/New_York",
+			wantRunAt:     wallClock(2023, time.December, 31, 19, 0),
+			wantDeliverAt: wallClock(2024, time.January, 1, 14, 0),
+		},
+		{
+			name:          "local time before and after the start of daylight saving time",
+			campaign:      Campaign{Timezone: "UTC", LocalTime: true},
+			occurrence:    wallClock(2024, time.March, 10, 9, 0),
+			timezone:      "America/New_York",
+			wantRunAt:     wallClock(2024, time.March, 9, 19, 0),
+			wantDeliverAt: wallClock(2024, time.March, 10, 13, 0),
+		},
+		{
+			name:          "local time with an unknown recipient timezone uses the campaign timezone",
+			campaign:      Campaign{Timezone: "Asia/Jakarta", LocalTime: true},
+			occurrence:    wallClock(2024, time.January, 1, 9, 0),
+			timezone:      "Mars/Olympus",
+			wantRunAt:     wallClock(2023, time.December, 31, 19, 0),
+			wantDeliverAt: wallClock(2024, time.January, 1, 2, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.campaign.RunAt(tt.occurrence); !got.Equal(tt.wantRunAt) {
+				t.Errorf("RunAt() = %v, want %v", got.UTC(), tt.wantRunAt)
+			}
+			if got := tt.campaign.DeliverAt(tt.occurrence, tt.timezone); !got.Equal(tt.wantDeliverAt) {
+				t.Errorf("DeliverAt() = %v, want %v", got.UTC(), tt.wantDeliverAt)
+			}
+		})
+	}
+}
+
+func TestCampaignReschedule(t *testing.T) {
+	daily := &CampaignRecurrence{Frequency: FrequencyDaily}
+	until := wallClock(2024, time.January, 1, 9, 0)
+	cursor := uuid.New()
+
+	tests := []struct {
+		name           string
+		campaign       Campaign
+		now            time.Time
+		want           bool
+		wantOccurrence time.Time
+		wantNextRunAt  time.Time
+		wantCursor     bool
+	}{
+		{
+			name:           "once keeps its occurrence and runs right away",
+			campaign:       Campaign{Timezone: "UTC", Occurrence: wallClock(2024, time.January, 1, 9, 0), RunCursor: &cursor},
+			now:            wallClock(2024, time.January, 5, 9, 0),
+			want:           true,
+			wantOccurrence: wallClock(2024, time.January, 1, 9, 0),
+			wantNextRunAt:  wallClock(2024, time.January, 1, 9, 0),
+			wantCursor:     true,
+		},
+		{
+			name:           "recurring skips the missed occurrences",
+			campaign:       Campaign{Timezone: "UTC", Recurrence: daily, StartsAt: wallClock(2024, time.January, 1, 9, 0), Occurrence: wallClock(2024, time.January, 1, 9, 0), RunCursor: &cursor},
+			now:            wallClock(2024, time.January, 3, 10, 0),
+			want:           true,
+			wantOccurrence: wallClock(2024, time.January, 4, 9, 0),
+			wantNextRunAt:  wallClock(2024, time.January, 4, 9, 0),
+		},
+		{
+			name:           "local time keeps an occurrence the latest timezone has not reached",
+			campaign:       Campaign{Timezone: "UTC", LocalTime: true, Recurrence: daily, StartsAt: wallClock(2024, time.January, 1, 9, 0), Occurrence: wallClock(2024, time.January, 1, 9, 0), RunCursor: &cursor},
+			now:            wallClock(2024, time.January, 1, 20, 0),
+			want:           true,
+			wantOccurrence: wallClock(2024, time.January, 1, 9, 0),
+			wantNextRunAt:  wallClock(2023, time.December, 31, 19, 0),
+			wantCursor:     true,
+		},
+		{
+			name:           "local time skips an occurrence the latest timezone has passed",
+			campaign:       Campaign{Timezone: "UTC", LocalTime: true, Recurrence: daily, StartsAt: wallClock(2024, time.January, 1, 9, 0), Occurrence: wallClock(2024, time.January, 1, 9, 0)},
+			now:            wallClock(2024, time.January, 1, 22, 0),
+			want:           true,
+			wantOccurrence: wallClock(2024, time.January, 2, 9, 0),
+			wantNextRunAt:  wallClock(2024, time.January, 1, 19, 0),
+		},
+		{
+			name:     "recurrence ended meanwhile",
+			campaign: Campaign{Timezone: "UTC", Recurrence: &CampaignRecurrence{Frequency: FrequencyDaily, Until: &until}, StartsAt: until, Occurrence: until},
+			now:      wallClock(2024, time.January, 2, 9, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaign := tt.campaign
+			if got := campaign.Reschedule(tt.now); got != tt.want {
+				t.Fatalf("Reschedule() = %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+
+			if !campaign.Occurrence.Equal(tt.wantOccurrence) {
+				t.Errorf("occurrence = %v, want %v", campaign.Occurrence, tt.wantOccurrence)
+			}
+			if campaign.NextRunAt == nil || !campaign.NextRunAt.Equal(tt.wantNextRunAt) {
+				t.Errorf("next run at = %v, want %v", campaign.NextRunAt, tt.wantNextRunAt)
+			}
+			if (campaign.RunCursor != nil) != tt.wantCursor {
+				t.Errorf("run cursor kept = %v, want %v", campaign.RunCursor != nil, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestCampaignAdvance(t *testing.T) {
+	until := wallClock(2024, time.January, 2, 9, 0)
+	cursor := uuid.New()
+
+	campaign := Campaign{Status: CampaignScheduled, Timezone: "UTC", Recurrence: &CampaignRecurrence{Frequency: FrequencyDaily, Until: &until}, StartsAt: wallClock(2024, time.January, 1, 9, 0), Occurrence: wallClock(2024, time.January, 1, 9, 0), RunCursor: &cursor}
+
+	campaign.Advance()
+	if campaign.Status != CampaignScheduled || !campaign.Occurrence.Equal(until) || campaign.NextRunAt == nil || campaign.RunCursor != nil {
+		t.Errorf("after the first run = %s at %v, next run %v, cursor %v", campaign.Status, campaign.Occurrence, campaign.NextRunAt, campaign.RunCursor)
+	}
+
+	campaign.Advance()
+	if campaign.Status != CampaignCompleted || campaign.NextRunAt != nil {
+		t.Errorf("after the last run = %s, next run %v, want completed", campaign.Status, campaign.NextRunAt)
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type (
+	// TokenProvider is the push service that issued a device token, which its pushes are delivered through.
+	TokenProvider string
+
+	// DeviceToken represents the push registration token of one device of an account. An account has one token per
+	// device and platform, so signing in on a phone and a tablet keeps both devices reachable. Native Web Push
+	// subscriptions store their endpoint as Token together with the encryption keys P256dh and Auth. Provider tells which
+	// push service issued the token, so an iOS device may register an FCM token or a native APNs device token.
+	DeviceToken struct {
+		ID         uuid.UUID     `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		AccountID  uuid.UUID     `json:"account_id" gorm:"not null;column:account_id;type:uuid;uniqueIndex:idx_device_token_account_device_platform,priority:1"`
+		DeviceID   uuid.UUID     `json:"device_id" gorm:"not null;column:device_id;type:uuid;uniqueIndex:idx_device_token_account_device_platform,priority:2"`
+		Platform   Platform      `json:"platform" gorm:"not null;column:platform;type:platform;uniqueIndex:idx_device_token_account_device_platform,priority:3"`
+		Token      string        `json:"-" gorm:"not null;column:token;type:varchar;index:idx_device_token_token,hash"`
+		Provider   TokenProvider `json:"provider" gorm:"not null;column:provider;type:varchar;default:fcm"`
+		P256dh     string        `json:"-" gorm:"column:p256dh;type:varchar"`
+		Auth       string        `json:"-" gorm:"column:auth;type:varchar"`
+		Active     bool          `json:"active" gorm:"not null;column:active;type:boolean;default:true"`
+		LastSeenAt time.Time     `json:"last_seen_at" gorm:"not null;column:last_seen_at;type:timestamp;default:CURRENT_TIMESTAMP"`
+		CreatedAt  time.Time     `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+		UpdatedAt  *time.Time    `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	}
+)
+
+const (
+	ProviderFCM     TokenProvider = "fcm"
+	ProviderAPNs    TokenProvider = "apns"
+	ProviderWebPush TokenProvider = "webpush"
+)
+
+// IsFCM reports whether the token is an FCM registration token, which alone can be subscribed to topics.
+func (d *DeviceToken) IsFCM() bool {
+	return d.Provider == ProviderFCM
+}
+
+// IsWebPush reports whether the token is a native Web Push subscription rather than a push service registration token.
+func (d *DeviceToken) IsWebPush() bool {
+	return d.P256dh != "" && d.Auth != ""
+}
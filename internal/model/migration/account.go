@@ -41,12 +41,13 @@ func (m *Migration) AccountMigration() {
 	}
 }
 
-// migrateAccount performs the migration of Account and AccountPassHashed tables within a transaction, ensuring atomicity.
+// migrateAccount performs the migration of Account, AccountPassHashed and AccountEmailChange tables within a transaction,
+// ensuring atomicity.
 func migrateAccount(tx *gorm.DB) error {
-	if err := tx.Migrator().DropTable(&model.Account{}, &model.AccountPassHashed{}); err != nil {
+	if err := tx.Migrator().DropTable(&model.Account{}, &model.AccountPassHashed{}, &model.AccountEmailChange{}); err != nil {
 		return err
 	}
-	if err := tx.AutoMigrate(&model.Account{}, &model.AccountPassHashed{}); err != nil {
+	if err := tx.AutoMigrate(&model.Account{}, &model.AccountPassHashed{}, &model.AccountEmailChange{}); err != nil {
 		return err
 	}
 	return nil
@@ -0,0 +1,14 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// APIKeyMigration creates or updates the APIKey table. Existing keys are kept, so services don't lose their credentials.
+func (m *Migration) APIKeyMigration() {
+	if err := m.AutoMigrate(&model.APIKey{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "api_key"), zap.Error(err))
+	}
+}
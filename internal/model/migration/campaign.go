@@ -0,0 +1,14 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// CampaignMigration creates or updates the Campaign table.
+func (m *Migration) CampaignMigration() {
+	if err := m.AutoMigrate(&model.Campaign{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "campaign"), zap.Error(err))
+	}
+}
@@ -0,0 +1,23 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// DeviceTokenMigration creates or updates the DeviceToken table. It relies on the platform enum, so it must run after
+// NotificationMigration. Web Push subscriptions stored before the provider column existed are marked as such, every
+// other token was registered as an FCM token.
+func (m *Migration) DeviceTokenMigration() {
+	if err := m.AutoMigrate(&model.DeviceToken{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "device_token"), zap.Error(err))
+		return
+	}
+
+	if err := m.Model(&model.DeviceToken{}).
+		Where("provider = ? AND p256dh <> '' AND auth <> ''", model.ProviderFCM).
+		Update("provider", model.ProviderWebPush).Error; err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "device_token"), zap.Error(err))
+	}
+}
@@ -9,18 +9,24 @@ import (
 	"time"
 )
 
-// NotificationMigration performs the internal of the PushNotification table by dropping the existing table and creating a new one.
+// NotificationMigration creates or updates the PushNotification and Notification tables, keeping their rows. The dummy
+// notifications are only inserted when the Notification table is created.
 func (m *Migration) NotificationMigration() {
 	if err := createEnums(m.DB); err != nil {
 		m.Logger.Error(errormessage.ErrCreatingEnumsText, zap.String("migration_name", "notification"), zap.Error(err))
 		return
 	}
 
+	seed := !m.Migrator().HasTable(&model.Notification{})
 	if err := migrateNotification(m, &model.PushNotification{}, &model.Notification{}); err != nil {
 		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "notification"), zap.Error(err))
 		return
 	}
 
+	if !seed {
+		return
+	}
+
 	notifications := createDummiesNotifications(m.UUID)
 
 	for _, notification := range notifications {
@@ -31,15 +37,10 @@ func (m *Migration) NotificationMigration() {
 	}
 }
 
-// migrateNotification handles the internal for the given model by dropping the existing table and creating a new one.
+// migrateNotification creates the tables of the given models or migrates their schema in place. The tables are never
+// dropped, since they hold pending pushes and the state of campaigns, digests and retention.
 func migrateNotification(m *Migration, models ...interface{}) error {
 	for _, i := range models {
-		if m.Migrator().HasTable(i) {
-			if err := m.Migrator().DropTable(i); err != nil {
-				return err
-			}
-		}
-
 		if err := m.AutoMigrate(i); err != nil {
 			return err
 		}
@@ -0,0 +1,14 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// NotificationPreferenceMigration creates or updates the NotificationPreference table.
+func (m *Migration) NotificationPreferenceMigration() {
+	if err := m.AutoMigrate(&model.NotificationPreference{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "notification_preference"), zap.Error(err))
+	}
+}
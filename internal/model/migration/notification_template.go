@@ -0,0 +1,14 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// NotificationTemplateMigration creates or updates the NotificationTemplate table.
+func (m *Migration) NotificationTemplateMigration() {
+	if err := m.AutoMigrate(&model.NotificationTemplate{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "notification_template"), zap.Error(err))
+	}
+}
@@ -0,0 +1,15 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// OAuthMigration creates or updates the OAuthClient, OAuthAuthorizationCode and OAuthConsent tables.
+// Registered clients and granted consents are kept across restarts.
+func (m *Migration) OAuthMigration() {
+	if err := m.AutoMigrate(&model.OAuthClient{}, &model.OAuthAuthorizationCode{}, &model.OAuthConsent{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "oauth"), zap.Error(err))
+	}
+}
@@ -0,0 +1,14 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// PurgedRecordMigration creates or updates the PurgedRecord table.
+func (m *Migration) PurgedRecordMigration() {
+	if err := m.AutoMigrate(&model.PurgedRecord{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "purged_record"), zap.Error(err))
+	}
+}
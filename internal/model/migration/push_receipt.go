@@ -0,0 +1,14 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// PushReceiptMigration creates or updates the PushReceipt table.
+func (m *Migration) PushReceiptMigration() {
+	if err := m.AutoMigrate(&model.PushReceipt{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "push_receipt"), zap.Error(err))
+	}
+}
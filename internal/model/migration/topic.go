@@ -0,0 +1,14 @@
+package migration
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+)
+
+// TopicMigration creates or updates the TopicSubscription table.
+func (m *Migration) TopicMigration() {
+	if err := m.AutoMigrate(&model.TopicSubscription{}); err != nil {
+		m.Logger.Error(errormessage.ErrMigrationText, zap.String("migration_name", "topic"), zap.Error(err))
+	}
+}
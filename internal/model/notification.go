@@ -2,7 +2,9 @@ package model
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"github.com/arifai/zenith/pkg/push"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"time"
@@ -14,31 +16,77 @@ type (
 	Status string
 
 	// PushNotification represents a system or application event message that can be presented to users.
+	// Pending notifications are delivered by the push dispatcher, which leases them through LockedBy and LockedUntil
+	// and schedules retries through NextAttemptAt. A notification without DeviceTokenID targets the whole account and
+	// is fanned out into one notification per active device, linked to it through ParentID, which are then delivered
+	// as a multicast. Notifications with a Topic or Condition are broadcast by the push service instead, they are not
+	// tied to an account and carry uuid.Nil as AccountID. Options holds the platform-specific delivery options, which
+	// are copied to the notifications of a fan-out. Pushes of an inbox entry are linked to it through NotificationID
+	// and carry its ID in their data under PushDataNotificationID, so opening the push can mark the entry as read.
+	// Pushes of a campaign are linked to it through CampaignID, pushes rendered from a template carry its name in
+	// Template. Clients report the delivery and opening of a push as PushReceipt.
 	PushNotification struct {
-		ID        uuid.UUID         `json:"id" gorm:"not null;primaryKey;type:uuid;default:uuid_generate_v4()"`
-		AccountID uuid.UUID         `json:"account_id" gorm:"not null;column:account_id;type:uuid;index:idx_push_notification_account_id,hash"`
-		Title     string            `json:"title" gorm:"not null;column:title;type:varchar"`
-		Message   string            `json:"message" gorm:"not null;column:message;type:varchar"`
-		Image     string            `json:"image" gorm:"column:image;type:varchar"`
-		Data      map[string]string `json:"data" gorm:"not null;column:data;type:jsonb;serializer:json"`
-		Platform  Platform          `json:"platform" gorm:"not null;column:platform;type:platform"`
-		Status    Status            `json:"status" gorm:"not null;column:status;type:status;default:'Pending'"`
-		Retries   int8              `json:"retries" gorm:"not null;column:retries;type:smallint;default:0"`
-		CreatedAt time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+		ID               uuid.UUID         `json:"id" gorm:"not null;primaryKey;type:uuid;default:uuid_generate_v4()"`
+		AccountID        uuid.UUID         `json:"account_id" gorm:"not null;column:account_id;type:uuid;index:idx_push_notification_account_id,hash"`
+		Title            string            `json:"title" gorm:"not null;column:title;type:varchar"`
+		Message          string            `json:"message" gorm:"not null;column:message;type:varchar"`
+		Image            string            `json:"image" gorm:"column:image;type:varchar"`
+		Data             map[string]string `json:"data" gorm:"not null;column:data;type:jsonb;serializer:json"`
+		Platform         Platform          `json:"platform" gorm:"not null;column:platform;type:platform"`
+		Status           Status            `json:"status" gorm:"not null;column:status;type:status;default:'Pending';index:idx_push_notification_dispatch,priority:1"`
+		Retries          int8              `json:"retries" gorm:"not null;column:retries;type:smallint;default:0"`
+		ParentID         *uuid.UUID        `json:"parent_id" gorm:"column:parent_id;type:uuid;index:idx_push_notification_parent_id,hash"`
+		DeviceTokenID    *uuid.UUID        `json:"device_token_id" gorm:"column:device_token_id;type:uuid"`
+		NotificationID   *uuid.UUID        `json:"notification_id" gorm:"column:notification_id;type:uuid;index:idx_push_notification_notification_id,hash"`
+		CampaignID       *uuid.UUID        `json:"campaign_id,omitempty" gorm:"column:campaign_id;type:uuid;index:idx_push_notification_campaign_id,hash"`
+		Template         string            `json:"template,omitempty" gorm:"column:template;type:varchar;index:idx_push_notification_template,hash"`
+		Topic            string            `json:"topic,omitempty" gorm:"column:topic;type:varchar"`
+		Condition        string            `json:"condition,omitempty" gorm:"column:condition;type:varchar"`
+		Options          *push.Options     `json:"options,omitempty" gorm:"column:options;type:jsonb;serializer:json"`
+		NextAttemptAt    time.Time         `json:"next_attempt_at" gorm:"not null;column:next_attempt_at;type:timestamp;default:CURRENT_TIMESTAMP;index:idx_push_notification_dispatch,priority:2"`
+		LockedBy         *uuid.UUID        `json:"-" gorm:"column:locked_by;type:uuid"`
+		LockedUntil      *time.Time        `json:"-" gorm:"column:locked_until;type:timestamp"`
+		LastError        string            `json:"last_error" gorm:"column:last_error;type:text"`
+		ProviderResponse string            `json:"provider_response" gorm:"column:provider_response;type:text"`
+		SentAt           *time.Time        `json:"sent_at" gorm:"column:sent_at;type:timestamp"`
+		CreatedAt        time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+		UpdatedAt        *time.Time        `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	}
+
+	// NotificationPriority tells how urgent a notification is. High priority pushes wake sleeping devices.
+	NotificationPriority string
+
+	// NotificationAction is a button shown with a notification. The payload is handed back to the app when the button
+	// is pressed, e.g. a deep link or an identifier the app understands.
+	NotificationAction struct {
+		Label   string `json:"label"`
+		Payload string `json:"payload"`
 	}
 
 	// Notification represents a notification sent to a user in the system.
-	// It contains information such as title, description, and read status.
+	// It contains information such as title, description, category, priority and read status. Opening a notification
+	// leads to its ActionURL, a deep link or web URL, and its Actions are shown as buttons. Notifications past their
+	// ExpiresAt are hidden from the inbox. Archived notifications leave the inbox but can still be listed, deleted
+	// notifications are soft deleted and hidden from every query. Notifications of a category the account only receives
+	// by email are stored with EmailOnly for the email digest and never show up in the inbox.
 	Notification struct {
-		ID               uuid.UUID  `json:"id" gorm:"not null;primaryKey;type:uuid;default:uuid_generate_v4()"`
-		AccountID        uuid.UUID  `json:"account_id" gorm:"not null;column:account_id;type:uuid;index:idx_notification_account_id,hash"`
-		Title            string     `json:"title" gorm:"not null;column:title;type:varchar"`
-		Image            string     `json:"image" gorm:"column:image;type:varchar"`
-		ShortDescription string     `json:"short_description" gorm:"not null;column:short_description;type:varchar"`
-		Description      string     `json:"description" gorm:"not null;column:description;type:text"`
-		Read             bool       `json:"read" gorm:"not null;column:read;type:boolean;default:false"`
-		ReadAt           *time.Time `json:"read_at" gorm:"column:read_at;type:timestamp"`
-		CreatedAt        time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+		ID               uuid.UUID            `json:"id" gorm:"not null;primaryKey;type:uuid;default:uuid_generate_v4();index:idx_notification_keyset,priority:3"`
+		AccountID        uuid.UUID            `json:"account_id" gorm:"not null;column:account_id;type:uuid;index:idx_notification_account_id,hash;index:idx_notification_keyset,priority:1"`
+		Title            string               `json:"title" gorm:"not null;column:title;type:varchar"`
+		Image            string               `json:"image" gorm:"column:image;type:varchar"`
+		Category         NotificationCategory `json:"category" gorm:"not null;column:category;type:varchar;default:'general'"`
+		Priority         NotificationPriority `json:"priority" gorm:"not null;column:priority;type:varchar;default:'normal'"`
+		ShortDescription string               `json:"short_description" gorm:"not null;column:short_description;type:varchar"`
+		Description      string               `json:"description" gorm:"not null;column:description;type:text"`
+		ActionURL        string               `json:"action_url,omitempty" gorm:"column:action_url;type:varchar"`
+		Actions          []NotificationAction `json:"actions,omitempty" gorm:"column:actions;type:jsonb;serializer:json"`
+		ExpiresAt        *time.Time           `json:"expires_at,omitempty" gorm:"column:expires_at;type:timestamp"`
+		Read             bool                 `json:"read" gorm:"not null;column:read;type:boolean;default:false"`
+		ReadAt           *time.Time           `json:"read_at" gorm:"column:read_at;type:timestamp"`
+		ArchivedAt       *time.Time           `json:"archived_at" gorm:"column:archived_at;type:timestamp"`
+		EmailOnly        bool                 `json:"-" gorm:"not null;column:email_only;type:boolean;default:false"`
+		CreatedAt        time.Time            `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP;index:idx_notification_keyset,priority:2"`
+		DeletedAt        gorm.DeletedAt       `json:"-" gorm:"column:deleted_at;type:timestamp;index"`
 	}
 )
 
@@ -49,6 +97,22 @@ const (
 	Pending Status   = "Pending"
 	Success Status   = "Success"
 	Failure Status   = "Failure"
+
+	PriorityLow    NotificationPriority = "low"
+	PriorityNormal NotificationPriority = "normal"
+	PriorityHigh   NotificationPriority = "high"
+
+	// PushDataNotificationID is the data key of a push notification carrying the ID of its inbox entry.
+	PushDataNotificationID = "notification_id"
+
+	// PushDataCategory, PushDataPriority, PushDataActionURL, PushDataActions and PushDataExpiresAt are the data keys of
+	// a push notification carrying the metadata of its notification. Actions are encoded as a JSON array and the expiry
+	// in RFC 3339, since push data only holds strings.
+	PushDataCategory  = "category"
+	PushDataPriority  = "priority"
+	PushDataActionURL = "action_url"
+	PushDataActions   = "actions"
+	PushDataExpiresAt = "expires_at"
 )
 
 func (p *Platform) Scan(value interface{}) error {
@@ -77,6 +141,29 @@ func (s Status) Value() (driver.Value, error) {
 	return string(s), nil
 }
 
+// PushData returns the metadata of the notification as push data, to be merged into the data of its pushes. Empty
+// fields are left out.
+func (n *Notification) PushData() map[string]string {
+	data := map[string]string{
+		PushDataCategory: string(n.Category),
+		PushDataPriority: string(n.Priority),
+	}
+
+	if n.ActionURL != "" {
+		data[PushDataActionURL] = n.ActionURL
+	}
+	if len(n.Actions) > 0 {
+		if actions, err := json.Marshal(n.Actions); err == nil {
+			data[PushDataActions] = string(actions)
+		}
+	}
+	if n.ExpiresAt != nil {
+		data[PushDataExpiresAt] = n.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	return data
+}
+
 func (n *PushNotification) BeforeSave(db *gorm.DB) error {
 	if n.Platform != Android && n.Platform != IOS && n.Platform != Web {
 		return errors.New("invalid platform")
@@ -86,5 +173,9 @@ func (n *PushNotification) BeforeSave(db *gorm.DB) error {
 		return errors.New("invalid status")
 	}
 
+	if n.Options != nil {
+		return n.Options.Validate()
+	}
+
 	return nil
 }
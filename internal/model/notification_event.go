@@ -0,0 +1,101 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// NotificationEventType names the kind of change a NotificationEvent announces.
+	NotificationEventType string
+
+	// NotificationEvent announces a change of the notifications of an account to its open streams. ID is assigned when
+	// the event is published and increases with every event of the account, clients resume a stream from it. Data holds
+	// the Notification of created events, the NotificationReadState of read events and the NotificationArchiveState of
+	// archived and deleted events.
+	NotificationEvent struct {
+		ID        string                `json:"id,omitempty"`
+		Type      NotificationEventType `json:"type"`
+		AccountID uuid.UUID             `json:"-"`
+		Data      json.RawMessage       `json:"data,omitempty"`
+	}
+
+	// NotificationReadState tells which notifications changed their read state. All is set instead of IDs when every
+	// notification of the account was marked as read.
+	NotificationReadState struct {
+		IDs    []uuid.UUID `json:"ids,omitempty"`
+		All    bool        `json:"all,omitempty"`
+		Read   bool        `json:"read"`
+		ReadAt *time.Time  `json:"read_at"`
+	}
+
+	// NotificationArchiveState tells which notifications were archived, unarchived or deleted.
+	NotificationArchiveState struct {
+		IDs      []uuid.UUID `json:"ids"`
+		Archived bool        `json:"archived"`
+	}
+)
+
+const (
+	EventNotificationCreated  NotificationEventType = "notification.created"
+	EventNotificationRead     NotificationEventType = "notification.read"
+	EventNotificationArchived NotificationEventType = "notification.archived"
+	EventNotificationDeleted  NotificationEventType = "notification.deleted"
+
+	// EventHeartbeat keeps idle streams open, it is never published.
+	EventHeartbeat NotificationEventType = "heartbeat"
+)
+
+// NewNotificationEvent creates an event of the given type for an account, encoding data as its payload.
+func NewNotificationEvent(eventType NotificationEventType, accountID uuid.UUID, data interface{}) (*NotificationEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationEvent{Type: eventType, AccountID: accountID, Data: payload}, nil
+}
+
+// After reports whether the event was published after the event with the given ID. Event IDs are Redis stream IDs, a
+// millisecond timestamp and a sequence number joined by a dash.
+func (e *NotificationEvent) After(id string) bool {
+	eventTime, eventSequence, ok := parseEventID(e.ID)
+	if !ok {
+		return false
+	}
+
+	otherTime, otherSequence, ok := parseEventID(id)
+	if !ok {
+		return true
+	}
+
+	return eventTime > otherTime || (eventTime == otherTime && eventSequence > otherSequence)
+}
+
+// ValidEventID reports whether id is a well-formed event ID.
+func ValidEventID(id string) bool {
+	_, _, ok := parseEventID(id)
+	return ok
+}
+
+// parseEventID splits an event ID into its timestamp and sequence number.
+func parseEventID(id string) (timestamp, sequence uint64, ok bool) {
+	timestampPart, sequencePart, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	timestamp, err := strconv.ParseUint(timestampPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	sequence, err = strconv.ParseUint(sequencePart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return timestamp, sequence, true
+}
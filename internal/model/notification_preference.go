@@ -0,0 +1,130 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type (
+	// NotificationCategory classifies notifications, accounts choose the channels of each category.
+	NotificationCategory string
+
+	// ChannelPreference tells through which channels notifications of a category reach an account: as push
+	// notifications, by email and in the in-app inbox. Emailed notifications are collected into a periodic digest.
+	ChannelPreference struct {
+		Push  bool `json:"push"`
+		Email bool `json:"email"`
+		InApp bool `json:"in_app"`
+	}
+
+	// NotificationPreference holds the notification settings of an account: the channels of every category, the quiet
+	// hours, given as "15:04" wall clock times in the timezone of the account, and the locale templated notifications
+	// are rendered in. Pushes falling into the quiet hours are deferred until they end. Categories without an entry use
+	// DefaultChannelPreference, an empty locale uses the default locale of the server. LastDigestAt records when the
+	// last email digest was sent, the next one collects the notifications created since.
+	NotificationPreference struct {
+		AccountID       uuid.UUID                                  `json:"account_id" gorm:"primaryKey;type:uuid"`
+		Timezone        string                                     `json:"timezone" gorm:"not null;column:timezone;type:varchar;default:'UTC'"`
+		Locale          string                                     `json:"locale" gorm:"column:locale;type:varchar"`
+		QuietHoursStart string                                     `json:"quiet_hours_start" gorm:"column:quiet_hours_start;type:varchar"`
+		QuietHoursEnd   string                                     `json:"quiet_hours_end" gorm:"column:quiet_hours_end;type:varchar"`
+		Categories      map[NotificationCategory]ChannelPreference `json:"categories" gorm:"not null;column:categories;type:jsonb;serializer:json"`
+		LastDigestAt    *time.Time                                 `json:"-" gorm:"column:last_digest_at;type:timestamp"`
+		UpdatedAt       *time.Time                                 `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	}
+
+	// DigestRecipient is an account due for an email digest, together with the preferences the digest is built from.
+	DigestRecipient struct {
+		NotificationPreference `gorm:"embedded"`
+		Email                  string `gorm:"column:email"`
+		FullName               string `gorm:"column:full_name"`
+	}
+)
+
+const (
+	CategoryGeneral   NotificationCategory = "general"
+	CategoryAccount   NotificationCategory = "account"
+	CategorySecurity  NotificationCategory = "security"
+	CategoryMarketing NotificationCategory = "marketing"
+
+	// quietHoursLayout is the layout of the start and end of quiet hours.
+	quietHoursLayout = "15:04"
+)
+
+var (
+	// NotificationCategories lists every notification category.
+	NotificationCategories = []NotificationCategory{CategoryGeneral, CategoryAccount, CategorySecurity, CategoryMarketing}
+
+	// DefaultChannelPreference enables push notifications and the inbox, email is opt-in.
+	DefaultChannelPreference = ChannelPreference{Push: true, InApp: true}
+)
+
+// DefaultNotificationPreference returns the preferences of an account that never changed them.
+func DefaultNotificationPreference(accountID uuid.UUID) *NotificationPreference {
+	return &NotificationPreference{AccountID: accountID, Timezone: "UTC", Categories: map[NotificationCategory]ChannelPreference{}}
+}
+
+// Channels returns the channels of a category, falling back to DefaultChannelPreference.
+func (p *NotificationPreference) Channels(category NotificationCategory) ChannelPreference {
+	if channels, ok := p.Categories[category]; ok {
+		return channels
+	}
+
+	return DefaultChannelPreference
+}
+
+// EmailCategories returns the categories whose notifications are mailed to the account.
+func (p *NotificationPreference) EmailCategories() []NotificationCategory {
+	var categories []NotificationCategory
+	for _, category := range NotificationCategories {
+		if p.Channels(category).Email {
+			categories = append(categories, category)
+		}
+	}
+
+	return categories
+}
+
+// QuietUntil reports whether now falls into the quiet hours and returns their end. Security notifications are never
+// held back. Quiet hours may span midnight, e.g. from "22:00" to "07:00".
+func (p *NotificationPreference) QuietUntil(category NotificationCategory, now time.Time) (time.Time, bool) {
+	if category == CategorySecurity || p.QuietHoursStart == "" || p.QuietHoursEnd == "" {
+		return time.Time{}, false
+	}
+
+	start, err := time.Parse(quietHoursLayout, p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse(quietHoursLayout, p.QuietHoursEnd)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	location, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute, endMinute := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+
+	quiet := false
+	switch {
+	case startMinute < endMinute:
+		quiet = minute >= startMinute && minute < endMinute
+	case startMinute > endMinute:
+		quiet = minute >= startMinute || minute < endMinute
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end.Hour(), end.Minute(), 0, 0, location)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+
+	return until, true
+}
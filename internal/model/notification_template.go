@@ -0,0 +1,93 @@
+package model
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"strings"
+	"text/template"
+	"time"
+)
+
+type (
+	// NotificationTemplate is the copy of a notification in one locale, identified by its name and locale. The title,
+	// short description and description are Go text/template templates rendered with the variables of a send request.
+	// The title and short description are also the title and body of the pushes.
+	NotificationTemplate struct {
+		ID               uuid.UUID            `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		Name             string               `json:"name" gorm:"not null;column:name;type:varchar;uniqueIndex:idx_notification_template_name_locale,priority:1"`
+		Locale           string               `json:"locale" gorm:"not null;column:locale;type:varchar;uniqueIndex:idx_notification_template_name_locale,priority:2"`
+		Category         NotificationCategory `json:"category" gorm:"not null;column:category;type:varchar;default:'general'"`
+		Title            string               `json:"title" gorm:"not null;column:title;type:text"`
+		ShortDescription string               `json:"short_description" gorm:"not null;column:short_description;type:text"`
+		Description      string               `json:"description" gorm:"not null;column:description;type:text"`
+		CreatedAt        time.Time            `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+		UpdatedAt        *time.Time           `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	}
+
+	// RenderedTemplate is the copy of a notification rendered from a NotificationTemplate.
+	RenderedTemplate struct {
+		Title            string
+		ShortDescription string
+		Description      string
+	}
+)
+
+// Parse checks that the title, short description and description of the template are valid templates.
+func (t *NotificationTemplate) Parse() error {
+	for _, text := range []string{t.Title, t.ShortDescription, t.Description} {
+		if _, err := parseTemplate(text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Render renders the template with the given variables. Variables used by the template but missing from variables are
+// an error, so no notification goes out with a placeholder left empty.
+func (t *NotificationTemplate) Render(variables map[string]interface{}) (*RenderedTemplate, error) {
+	rendered := make([]string, 0, 3)
+	for _, text := range []string{t.Title, t.ShortDescription, t.Description} {
+		parsed, err := parseTemplate(text)
+		if err != nil {
+			return nil, err
+		}
+
+		var out bytes.Buffer
+		if err := parsed.Execute(&out, variables); err != nil {
+			return nil, err
+		}
+		rendered = append(rendered, out.String())
+	}
+
+	return &RenderedTemplate{Title: rendered[0], ShortDescription: rendered[1], Description: rendered[2]}, nil
+}
+
+// LocaleFallbacks returns the locales a template is looked up in, most specific first: the locale itself, its base
+// language and the default locale. "pt-BR" falls back to "pt" and then to the default.
+func LocaleFallbacks(locale, defaultLocale string) []string {
+	var locales []string
+	add := func(candidate string) {
+		for _, existing := range locales {
+			if existing == candidate {
+				return
+			}
+		}
+		if candidate != "" {
+			locales = append(locales, candidate)
+		}
+	}
+
+	add(locale)
+	if base, _, found := strings.Cut(locale, "-"); found {
+		add(base)
+	}
+	add(defaultLocale)
+
+	return locales
+}
+
+// parseTemplate parses a single text of a template.
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("notification").Option("missingkey=error").Parse(text)
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type (
+	// OAuthClient represents a third-party application registered to access accounts through the OAuth2 flows.
+	// Confidential clients authenticate with a secret, of which only the hash is stored. Public clients must use PKCE.
+	OAuthClient struct {
+		ID             uuid.UUID  `json:"client_id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		OwnerAccountID uuid.UUID  `json:"owner_account_id" gorm:"not null;column:owner_account_id;type:uuid;index:idx_oauth_client_owner_account_id,hash"`
+		Name           string     `json:"name" gorm:"not null;column:name;type:varchar"`
+		SecretHash     string     `json:"-" gorm:"column:secret_hash;type:varchar"`
+		Confidential   bool       `json:"confidential" gorm:"not null;column:confidential;type:boolean;default:false"`
+		RedirectURIs   []string   `json:"redirect_uris" gorm:"not null;column:redirect_uris;type:jsonb;serializer:json"`
+		Scopes         []string   `json:"scopes" gorm:"not null;column:scopes;type:jsonb;serializer:json"`
+		RevokedAt      *time.Time `json:"revoked_at" gorm:"column:revoked_at;type:timestamp"`
+		CreatedAt      time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+	}
+
+	// OAuthAuthorizationCode represents a single-use authorization code issued after an account approved a client.
+	// Only the hash of the code is stored, together with the PKCE challenge it must be redeemed with.
+	OAuthAuthorizationCode struct {
+		ID                  uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		CodeHash            string    `json:"-" gorm:"not null;column:code_hash;type:varchar;uniqueIndex:idx_oauth_authorization_code_code_hash"`
+		ClientID            uuid.UUID `json:"client_id" gorm:"not null;column:client_id;type:uuid"`
+		AccountID           uuid.UUID `json:"account_id" gorm:"not null;column:account_id;type:uuid"`
+		RedirectURI         string    `json:"redirect_uri" gorm:"not null;column:redirect_uri;type:varchar"`
+		Scopes              []string  `json:"scopes" gorm:"not null;column:scopes;type:jsonb;serializer:json"`
+		CodeChallenge       string    `json:"-" gorm:"column:code_challenge;type:varchar"`
+		CodeChallengeMethod string    `json:"-" gorm:"column:code_challenge_method;type:varchar"`
+		ExpiresAt           time.Time `json:"expires_at" gorm:"not null;column:expires_at;type:timestamp"`
+		CreatedAt           time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+	}
+
+	// OAuthConsent represents the scopes an account has granted to a client, so that the consent screen can be skipped.
+	OAuthConsent struct {
+		ID        uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		AccountID uuid.UUID  `json:"account_id" gorm:"not null;column:account_id;type:uuid;uniqueIndex:idx_oauth_consent_account_client"`
+		ClientID  uuid.UUID  `json:"client_id" gorm:"not null;column:client_id;type:uuid;uniqueIndex:idx_oauth_consent_account_client"`
+		Scopes    []string   `json:"scopes" gorm:"not null;column:scopes;type:jsonb;serializer:json"`
+		CreatedAt time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+		UpdatedAt *time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	}
+)
+
+// HasRedirectURI reports whether the redirect URI was registered by the client. URIs are compared exactly.
+func (c *OAuthClient) HasRedirectURI(redirectURI string) bool {
+	for _, uri := range c.RedirectURIs {
+		if uri == redirectURI {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsScopes reports whether all given scopes were registered by the client.
+func (c *OAuthClient) AllowsScopes(scopes []string) bool {
+	return containsAll(c.Scopes, scopes)
+}
+
+// Covers reports whether the consent already grants all given scopes.
+func (c *OAuthConsent) Covers(scopes []string) bool {
+	return containsAll(c.Scopes, scopes)
+}
+
+// containsAll reports whether every value of subset is part of set.
+func containsAll(set, subset []string) bool {
+	for _, value := range subset {
+		found := false
+		for _, s := range set {
+			if s == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,47 @@
+package model
+
+import "github.com/google/uuid"
+
+type (
+	AuthMethod string
+
+	// Principal describes who issued a request and how the request was authenticated. Anonymous requests carry a
+	// principal without an account.
+	Principal struct {
+		AccountID *uuid.UUID
+		Method    AuthMethod
+		ClientID  *uuid.UUID
+		APIKey    *APIKey
+		Scopes    []string
+	}
+)
+
+const (
+	AnonymousAuth   AuthMethod = "anonymous"
+	AccessTokenAuth AuthMethod = "access_token"
+	OAuthAuth       AuthMethod = "oauth"
+	APIKeyAuth      AuthMethod = "api_key"
+)
+
+// AnonymousPrincipal returns the principal of a request without credentials.
+func AnonymousPrincipal() *Principal {
+	return &Principal{Method: AnonymousAuth}
+}
+
+// IsAuthenticated reports whether the request carried valid credentials.
+func (p *Principal) IsAuthenticated() bool {
+	return p.Method != AnonymousAuth
+}
+
+// HasScope reports whether the principal was granted the given scope. Access tokens of accounts are not limited by
+// scopes, anonymous principals have none.
+func (p *Principal) HasScope(scope string) bool {
+	switch p.Method {
+	case AccessTokenAuth:
+		return true
+	case AnonymousAuth:
+		return false
+	default:
+		return containsAll(p.Scopes, []string{scope})
+	}
+}
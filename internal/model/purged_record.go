@@ -0,0 +1,32 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+type (
+	// PurgeArchive tells where rows are kept before the retention purge deletes them.
+	PurgeArchive string
+
+	// PurgedRecord is a row deleted by the retention purge, kept in its JSON representation together with the table it
+	// was deleted from.
+	PurgedRecord struct {
+		Source   string          `json:"source" gorm:"primaryKey;column:source;type:varchar"`
+		ID       uuid.UUID       `json:"id" gorm:"primaryKey;column:id;type:uuid"`
+		Data     json.RawMessage `json:"data" gorm:"not null;column:data;type:jsonb"`
+		PurgedAt time.Time       `json:"purged_at" gorm:"not null;column:purged_at;type:timestamp;index:idx_purged_record_purged_at"`
+	}
+)
+
+const (
+	// ArchiveNone deletes purged rows without keeping them.
+	ArchiveNone PurgeArchive = ""
+
+	// ArchiveTable keeps purged rows in the PurgedRecord table.
+	ArchiveTable PurgeArchive = "table"
+
+	// ArchiveFile appends purged rows to daily JSONL files, one per table.
+	ArchiveFile PurgeArchive = "file"
+)
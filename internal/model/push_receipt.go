@@ -0,0 +1,59 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type (
+	// PushEvent is what happened to a push notification on the device it was sent to.
+	PushEvent string
+
+	// PushReceipt records an event a client reported for a push notification, identified by the ID the push carries in
+	// its data under PushDataPushID, at the time it occurred on the device, together with the device it was reported
+	// from. A push has at most one receipt per event, repeated reports are
+	// ignored.
+	PushReceipt struct {
+		ID                 uuid.UUID  `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		PushNotificationID uuid.UUID  `json:"push_notification_id" gorm:"not null;column:push_notification_id;type:uuid;uniqueIndex:idx_push_receipt_push_event,priority:1"`
+		AccountID          uuid.UUID  `json:"account_id" gorm:"not null;column:account_id;type:uuid;index:idx_push_receipt_account_id,hash"`
+		Event              PushEvent  `json:"event" gorm:"not null;column:event;type:varchar;uniqueIndex:idx_push_receipt_push_event,priority:2"`
+		OccurredAt         time.Time  `json:"occurred_at" gorm:"not null;column:occurred_at;type:timestamp"`
+		DeviceTokenID      *uuid.UUID `json:"device_token_id" gorm:"column:device_token_id;type:uuid"`
+		DeviceID           *uuid.UUID `json:"device_id,omitempty" gorm:"column:device_id;type:uuid"`
+		Platform           string     `json:"platform,omitempty" gorm:"column:platform;type:varchar"`
+		DeviceModel        string     `json:"device_model,omitempty" gorm:"column:device_model;type:varchar"`
+		OSVersion          string     `json:"os_version,omitempty" gorm:"column:os_version;type:varchar"`
+		AppVersion         string     `json:"app_version,omitempty" gorm:"column:app_version;type:varchar"`
+		CreatedAt          time.Time  `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+	}
+
+	// PushStats counts the pushes sent to devices by their delivery status and by the events reported for them. Pushes
+	// broadcast to a topic or condition are not counted, as no receipts are recorded for them.
+	// Delivered counts the pushes with any receipt, since opening or dismissing a push implies it arrived. OpenRate is
+	// the share of the sent pushes that were opened.
+	PushStats struct {
+		Pending   int64   `json:"pending"`
+		Sent      int64   `json:"sent"`
+		Failed    int64   `json:"failed"`
+		Delivered int64   `json:"delivered"`
+		Opened    int64   `json:"opened"`
+		Dismissed int64   `json:"dismissed"`
+		OpenRate  float64 `json:"open_rate"`
+	}
+
+	// TemplateStats holds the PushStats of the pushes rendered from a template, in any locale.
+	TemplateStats struct {
+		Template string `json:"template"`
+		PushStats
+	}
+)
+
+const (
+	PushDelivered PushEvent = "delivered"
+	PushOpened    PushEvent = "opened"
+	PushDismissed PushEvent = "dismissed"
+)
+
+// PushDataPushID is the data key of a push notification carrying its own ID, which clients report receipts for.
+const PushDataPushID = "push_id"
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type (
+	// TopicSubscription represents the subscription of an account to a push topic. The push tokens of all devices of
+	// the account are subscribed to the topic at the push service.
+	TopicSubscription struct {
+		ID        uuid.UUID `json:"id" gorm:"primaryKey;type:uuid;default:uuid_generate_v4()"`
+		AccountID uuid.UUID `json:"account_id" gorm:"not null;column:account_id;type:uuid;uniqueIndex:idx_topic_subscription_account_topic,priority:1"`
+		Topic     string    `json:"topic" gorm:"not null;column:topic;type:varchar;uniqueIndex:idx_topic_subscription_account_topic,priority:2"`
+		CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime;default:CURRENT_TIMESTAMP"`
+	}
+)
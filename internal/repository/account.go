@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 	"time"
@@ -27,14 +31,29 @@ type (
 		// UpdatePassword updates the hashed password of an account in the database. Returns an error if the update operation fails.
 		UpdatePassword(account *model.Account) error
 
-		// SetFCMToken updates the FCM token for an account identified by the provided email address. Returns an error if the update fails.
-		SetFCMToken(email, fcmToken string) error
-
-		// UnsetFCMToken unsets the FCM token for the account associated with the specified UUID, removing the existing token if present.
-		UnsetFCMToken(id uuid.UUID) error
-
 		// BlacklistToken adds the token identified by jti to the blacklist, exp indicates the token's expiration time.
 		BlacklistToken(jti string, exp time.Time) error
+
+		// IsTokenBlacklisted checks if the token identified by jti is present in the blacklist.
+		IsTokenBlacklisted(jti string) (bool, error)
+
+		// ClaimToken blacklists the token identified by jti unless it already is, reporting whether this call blacklisted
+		// it. Single-use tokens such as OAuth2 refresh tokens are claimed before they are redeemed, so concurrent requests
+		// can not redeem the same token twice.
+		ClaimToken(jti string, exp time.Time) (bool, error)
+
+		// CreateEmailChange stores a pending email change, replacing any previous pending change of the same account.
+		CreateEmailChange(change *model.AccountEmailChange) error
+
+		// FindEmailChangeByTokenHash retrieves a pending email change by the hash of its confirmation token.
+		FindEmailChangeByTokenHash(tokenHash string) (*model.AccountEmailChange, error)
+
+		// ConfirmEmailChange applies a pending email change to its account and removes all pending changes of that account.
+		ConfirmEmailChange(change *model.AccountEmailChange) (*model.Account, error)
+
+		// Search fetches a page of accounts matching the search term, filtered and sorted by the query, and the total
+		// count of matching accounts. The search term matches the full name and the email.
+		Search(paging *common.Pagination, query *common.ListQuery) (accounts []*model.Account, count int64, err error)
 	}
 
 	// accountRepository encapsulates a Repository to provide specific methods for handling account data.
@@ -81,7 +100,14 @@ func (a *accountRepository) FindByID(id *uuid.UUID) (*model.Account, error) {
 }
 
 func (a *accountRepository) Update(account *model.Account) error {
-	return a.db.Clauses(clause.Returning{}).Save(account).Error
+	if err := a.db.Clauses(clause.Returning{}).Save(account).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errormessage.ErrEmailAlreadyExists
+		}
+		return err
+	}
+
+	return nil
 }
 
 func (a *accountRepository) UpdatePassword(account *model.Account) error {
@@ -91,16 +117,6 @@ func (a *accountRepository) UpdatePassword(account *model.Account) error {
 		Update("pass_hashed", account.AccountPassHashed.PassHashed).Error
 }
 
-func (a *accountRepository) SetFCMToken(email, fcmToken string) error {
-	return a.db.Model(&model.Account{}).Where(&model.Account{Email: email}).
-		Update("fcm_token", fcmToken).Error
-}
-
-func (a *accountRepository) UnsetFCMToken(id uuid.UUID) error {
-	return a.db.Model(&model.Account{}).Where(&model.Account{ID: id}).
-		Update("fcm_token", nil).Error
-}
-
 func (a *accountRepository) BlacklistToken(jti string, exp time.Time) error {
 	ttl := time.Until(exp)
 
@@ -110,3 +126,80 @@ func (a *accountRepository) BlacklistToken(jti string, exp time.Time) error {
 
 	return nil
 }
+
+func (a *accountRepository) ClaimToken(jti string, exp time.Time) (bool, error) {
+	return a.redis.SetNX(context.Background(), jti, "blacklisted", time.Until(exp)).Result()
+}
+
+func (a *accountRepository) IsTokenBlacklisted(jti string) (bool, error) {
+	value, err := a.redis.Get(context.Background(), jti).Result()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return value == "blacklisted", nil
+}
+
+func (a *accountRepository) CreateEmailChange(change *model.AccountEmailChange) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("account_id = ?", change.AccountID).Delete(&model.AccountEmailChange{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(change).Error
+	})
+}
+
+func (a *accountRepository) FindEmailChangeByTokenHash(tokenHash string) (*model.AccountEmailChange, error) {
+	var change model.AccountEmailChange
+	if err := a.db.Where("token_hash = ?", tokenHash).First(&change).Error; err != nil {
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+func (a *accountRepository) ConfirmEmailChange(change *model.AccountEmailChange) (*model.Account, error) {
+	var account model.Account
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&account).
+			Clauses(clause.Returning{}).
+			Where("id = ?", change.AccountID).
+			Update("email", change.NewEmail)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return errormessage.ErrEmailAlreadyExists
+			}
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return errormessage.ErrAccountNotFound
+		}
+
+		return tx.Where("account_id = ?", change.AccountID).Delete(&model.AccountEmailChange{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (a *accountRepository) Search(paging *common.Pagination, query *common.ListQuery) (accounts []*model.Account, count int64, err error) {
+	if err = a.db.Model(&model.Account{}).
+		Scopes(common.Filtered(paging, query, "full_name", "email")).
+		Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err = a.db.Model(&model.Account{}).
+		Scopes(common.Paginate(paging, query, "full_name", "email")).
+		Find(&accounts).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return accounts, count, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"time"
+)
+
+type (
+	// APIKeyRepository defines methods to interact with API keys in the database.
+	APIKeyRepository interface {
+		// Create inserts a new API key into the database.
+		Create(apiKey *model.APIKey) error
+
+		// FindByPrefix retrieves an API key by its unique public prefix.
+		FindByPrefix(prefix string) (*model.APIKey, error)
+
+		// GetList retrieves all API keys of the given owner, newest first.
+		GetList(ownerType model.OwnerType, ownerID uuid.UUID) ([]*model.APIKey, error)
+
+		// Revoke marks the API key of the given owner as revoked and returns if it was found and updated successfully.
+		Revoke(ownerType model.OwnerType, ownerID, id uuid.UUID) (founded bool, err error)
+
+		// TouchLastUsed records the time the API key was last used. Updates are throttled to once per interval.
+		TouchLastUsed(id uuid.UUID, usedAt time.Time, interval time.Duration) error
+	}
+
+	// apiKeyRepository implements APIKeyRepository interface, provides repository functions for API keys.
+	apiKeyRepository struct{ *Repository }
+)
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository with the provided Repository parameter.
+func NewAPIKeyRepository(r *Repository) APIKeyRepository {
+	return &apiKeyRepository{r}
+}
+
+func (r *apiKeyRepository) Create(apiKey *model.APIKey) error {
+	return r.db.Create(apiKey).Error
+}
+
+func (r *apiKeyRepository) FindByPrefix(prefix string) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	if err := r.db.Where("prefix = ?", prefix).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) GetList(ownerType model.OwnerType, ownerID uuid.UUID) ([]*model.APIKey, error) {
+	var apiKeys []*model.APIKey
+	if err := r.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).
+		Order("created_at DESC").
+		Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ownerType model.OwnerType, ownerID, id uuid.UUID) (founded bool, err error) {
+	result := r.db.Model(&model.APIKey{}).
+		Where("id = ? AND owner_type = ? AND owner_id = ? AND revoked_at IS NULL", id, ownerType, ownerID).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id uuid.UUID, usedAt time.Time, interval time.Duration) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, usedAt.Add(-interval)).
+		Update("last_used_at", usedAt).Error
+}
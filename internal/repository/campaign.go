@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"errors"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"slices"
+	"time"
+)
+
+type (
+	// CampaignRepository defines methods for managing campaigns and enqueuing their pushes.
+	CampaignRepository interface {
+		// Create inserts a new campaign.
+		Create(campaign *model.Campaign) error
+
+		// GetList fetches a page of campaigns filtered and sorted by the query, and the total count of matching
+		// campaigns. The search term matches the name.
+		GetList(paging *common.Pagination, query *common.ListQuery) (campaigns []*model.Campaign, count int64, err error)
+
+		// FindByID retrieves a campaign by its ID, returning errormessage.ErrCampaignNotFound if it does not exist.
+		FindByID(id uuid.UUID) (*model.Campaign, error)
+
+		// Transition applies update to a campaign under a row lock if its status is one of from, returning
+		// errormessage.ErrCampaignStatus otherwise. Pending pushes of canceled campaigns are failed in the same
+		// transaction.
+		Transition(id uuid.UUID, from []model.CampaignStatus, update func(campaign *model.Campaign)) (*model.Campaign, error)
+
+		// ClaimDue leases a scheduled campaign whose next run is due for the lease duration, returning nil if there is
+		// none. Campaigns locked by a concurrent claim are skipped instead of waited for. Every claim is identified by the
+		// LockedBy of the returned campaign.
+		ClaimDue(now time.Time, lease time.Duration) (*model.Campaign, error)
+
+		// Audience returns up to limit IDs of the accounts in the audience of a campaign, filtered by the query and
+		// ordered by ID, starting after the given ID.
+		Audience(campaign *model.Campaign, query *common.ListQuery, after uuid.UUID, limit int) ([]uuid.UUID, error)
+
+		// EnqueueBatch enqueues the pushes of a batch of the audience of a claimed campaign, one per active device of each
+		// of their accounts, adds the batch to the counters, moves the RunCursor to cursor and extends the lease. It
+		// returns the number of accounts reached, or errormessage.ErrCampaignClaimLost if the claim no longer holds
+		// because the lease was taken over, the occurrence changed or the campaign was paused or canceled meanwhile.
+		EnqueueBatch(campaign *model.Campaign, pushes []*model.PushNotification, skipped int64, cursor uuid.UUID, lease time.Duration) (int64, error)
+
+		// CompleteRun advances a claimed campaign to its next occurrence once all of its batches are enqueued, counts the
+		// run and releases the lease. It returns errormessage.ErrCampaignClaimLost like EnqueueBatch.
+		CompleteRun(campaign *model.Campaign) error
+
+		// Stats counts the pushes of a campaign by their delivery status and by the events reported for them.
+		Stats(campaign *model.Campaign) (*model.CampaignStats, error)
+	}
+
+	// campaignRepository implements CampaignRepository interface.
+	campaignRepository struct{ *Repository }
+)
+
+const (
+	// campaignInsertBatch is the number of pushes inserted per statement when a campaign runs.
+	campaignInsertBatch = 1000
+
+	// canceledCampaignError is recorded on the pending pushes of a canceled campaign.
+	canceledCampaignError = "campaign canceled"
+)
+
+// NewCampaignRepository creates a new instance of CampaignRepository with the provided Repository parameter.
+func NewCampaignRepository(r *Repository) CampaignRepository {
+	return &campaignRepository{r}
+}
+
+func (r *campaignRepository) Create(campaign *model.Campaign) error {
+	return r.db.Create(campaign).Error
+}
+
+func (r *campaignRepository) GetList(paging *common.Pagination, query *common.ListQuery) (campaigns []*model.Campaign, count int64, err error) {
+	if err = r.db.Model(&model.Campaign{}).
+		Scopes(common.Filtered(paging, query, "name")).
+		Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err = r.db.Model(&model.Campaign{}).
+		Scopes(common.Paginate(paging, query, "name")).
+		Find(&campaigns).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return campaigns, count, nil
+}
+
+func (r *campaignRepository) FindByID(id uuid.UUID) (*model.Campaign, error) {
+	var campaign model.Campaign
+	if err := r.db.Where("id = ?", id).First(&campaign).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errormessage.ErrCampaignNotFound
+		}
+		return nil, err
+	}
+
+	return &campaign, nil
+}
+
+func (r *campaignRepository) Transition(id uuid.UUID, from []model.CampaignStatus, update func(campaign *model.Campaign)) (*model.Campaign, error) {
+	var campaign model.Campaign
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&campaign).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errormessage.ErrCampaignNotFound
+			}
+			return err
+		}
+
+		if !slices.Contains(from, campaign.Status) {
+			return errormessage.ErrCampaignStatus
+		}
+
+		update(&campaign)
+		if err := tx.Model(&campaign).
+			Select("status", "occurrence", "run_cursor", "next_run_at", "updated_at").
+			Updates(&campaign).Error; err != nil {
+			return err
+		}
+
+		if campaign.Status != model.CampaignCanceled {
+			return nil
+		}
+
+		return tx.Model(&model.PushNotification{}).
+			Where("campaign_id = ? AND status = ?", id, model.Pending).
+			Where("locked_until IS NULL OR locked_until <= ?", time.Now()).
+			Updates(map[string]interface{}{"status": model.Failure, "last_error": canceledCampaignError}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &campaign, nil
+}
+
+func (r *campaignRepository) ClaimDue(now time.Time, lease time.Duration) (*model.Campaign, error) {
+	var campaign model.Campaign
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_run_at <= ?", model.CampaignScheduled, now).
+			Where("locked_until IS NULL OR locked_until <= ?", now).
+			Order("next_run_at").
+			First(&campaign).Error; err != nil {
+			return err
+		}
+
+		claim := uuid.New()
+		lockedUntil := now.Add(lease)
+		campaign.LockedBy, campaign.LockedUntil = &claim, &lockedUntil
+
+		return tx.Model(&campaign).Updates(map[string]interface{}{"locked_by": claim, "locked_until": lockedUntil}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &campaign, nil
+}
+
+func (r *campaignRepository) Audience(campaign *model.Campaign, query *common.ListQuery, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	db := r.db.Model(&model.Account{}).Scopes(query.Where)
+	if len(campaign.AccountIDs) > 0 {
+		db = db.Where("id IN ?", campaign.AccountIDs)
+	}
+
+	var ids []uuid.UUID
+	if err := db.Where("id > ?", after).Order("id").Limit(limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (r *campaignRepository) EnqueueBatch(campaign *model.Campaign, pushes []*model.PushNotification, skipped int64, cursor uuid.UUID, lease time.Duration) (int64, error) {
+	var recipients int64
+	err := r.claimed(campaign, func(tx *gorm.DB, current *model.Campaign) error {
+		expanded, err := r.expand(tx, pushes)
+		if err != nil {
+			return err
+		}
+		if len(expanded) > 0 {
+			if err := tx.CreateInBatches(expanded, campaignInsertBatch).Error; err != nil {
+				return err
+			}
+		}
+
+		reached := make(map[uuid.UUID]struct{}, len(pushes))
+		for _, push := range expanded {
+			reached[push.AccountID] = struct{}{}
+		}
+		recipients = int64(len(reached))
+		skipped += int64(len(pushes)) - recipients
+
+		lockedUntil := time.Now().Add(lease)
+		if err := tx.Model(current).Updates(map[string]interface{}{
+			"run_cursor":   cursor,
+			"locked_until": lockedUntil,
+			"recipients":   gorm.Expr("recipients + ?", recipients),
+			"skipped":      gorm.Expr("skipped + ?", skipped),
+		}).Error; err != nil {
+			return err
+		}
+
+		campaign.RunCursor, campaign.LockedUntil = &cursor, &lockedUntil
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return recipients, nil
+}
+
+func (r *campaignRepository) CompleteRun(campaign *model.Campaign) error {
+	return r.claimed(campaign, func(tx *gorm.DB, current *model.Campaign) error {
+		campaign.Advance()
+		return tx.Model(current).Updates(map[string]interface{}{
+			"status":       campaign.Status,
+			"occurrence":   campaign.Occurrence,
+			"next_run_at":  campaign.NextRunAt,
+			"run_cursor":   nil,
+			"locked_by":    nil,
+			"locked_until": nil,
+			"runs":         gorm.Expr("runs + 1"),
+			"last_run_at":  time.Now(),
+		}).Error
+	})
+}
+
+func (r *campaignRepository) Stats(campaign *model.Campaign) (*model.CampaignStats, error) {
+	stats, err := pushStats(r.db, "campaign_id = ?", campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CampaignStats{Runs: campaign.Runs, Recipients: campaign.Recipients, Skipped: campaign.Skipped, PushStats: *stats}, nil
+}
+
+// claimed applies update to a campaign under a row lock if the claim of the given campaign still holds: the campaign
+// is still leased by the claim, at the same occurrence and scheduled. A campaign paused or canceled during the run keeps
+// its status and the lease of the claim is released, so the pushes of the remaining batches are never enqueued.
+func (r *campaignRepository) claimed(campaign *model.Campaign, update func(tx *gorm.DB, current *model.Campaign) error) error {
+	lost := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var current model.Campaign
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", campaign.ID).
+			First(&current).Error; err != nil {
+			return err
+		}
+
+		if current.LockedBy == nil || campaign.LockedBy == nil || *current.LockedBy != *campaign.LockedBy || !current.Occurrence.Equal(campaign.Occurrence) {
+			lost = true
+			return nil
+		}
+
+		if current.Status != model.CampaignScheduled {
+			lost = true
+			return tx.Model(&current).Updates(map[string]interface{}{"locked_by": nil, "locked_until": nil}).Error
+		}
+
+		return update(tx, &current)
+	})
+	if err != nil {
+		return err
+	} else if lost {
+		return errormessage.ErrCampaignClaimLost
+	}
+
+	return nil
+}
+
+// expand creates one push per active device of the account of each given push, the given pushes carry the content
+// and the time of delivery.
+func (r *campaignRepository) expand(tx *gorm.DB, pushes []*model.PushNotification) ([]*model.PushNotification, error) {
+	if len(pushes) == 0 {
+		return nil, nil
+	}
+
+	accountIDs := make([]uuid.UUID, 0, len(pushes))
+	for _, push := range pushes {
+		accountIDs = append(accountIDs, push.AccountID)
+	}
+
+	var deviceTokens []*model.DeviceToken
+	if err := tx.Where("account_id IN ? AND active = ?", accountIDs, true).
+		Find(&deviceTokens).Error; err != nil {
+		return nil, err
+	}
+
+	byAccount := make(map[uuid.UUID][]*model.DeviceToken, len(pushes))
+	for _, deviceToken := range deviceTokens {
+		byAccount[deviceToken.AccountID] = append(byAccount[deviceToken.AccountID], deviceToken)
+	}
+
+	expanded := make([]*model.PushNotification, 0, len(deviceTokens))
+	for _, push := range pushes {
+		for _, deviceToken := range byAccount[push.AccountID] {
+			expanded = append(expanded, &model.PushNotification{
+				AccountID:     push.AccountID,
+				Title:         push.Title,
+				Message:       push.Message,
+				Image:         push.Image,
+				Data:          push.Data,
+				Options:       push.Options,
+				Platform:      deviceToken.Platform,
+				Status:        model.Pending,
+				DeviceTokenID: &deviceToken.ID,
+				CampaignID:    push.CampaignID,
+				Template:      push.Template,
+				NextAttemptAt: push.NextAttemptAt,
+			})
+		}
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type (
+	// DeviceTokenRepository defines methods for managing the push registration tokens of account devices.
+	DeviceTokenRepository interface {
+		// Register stores the push token of a device, replacing the previous token of the same account, device and platform.
+		// The token is detached from any other device or account that registered it before.
+		Register(deviceToken *model.DeviceToken) error
+
+		// Remove deletes the push tokens of a single device of an account and returns them.
+		Remove(accountID, deviceID uuid.UUID) ([]*model.DeviceToken, error)
+
+		// Touch updates the last seen time of the push tokens of a device of an account.
+		Touch(accountID, deviceID uuid.UUID, seenAt time.Time) error
+
+		// FindByID retrieves a push token by its unique identifier.
+		FindByID(id uuid.UUID) (*model.DeviceToken, error)
+
+		// FindByIDs retrieves the push tokens with the given unique identifiers, skipping identifiers that do not exist.
+		FindByIDs(ids []uuid.UUID) ([]*model.DeviceToken, error)
+
+		// GetActive retrieves the active push tokens of an account.
+		GetActive(accountID uuid.UUID) ([]*model.DeviceToken, error)
+
+		// Deactivate marks a push token as dead, so it is no longer targeted until the device registers again.
+		Deactivate(id uuid.UUID) error
+	}
+
+	// deviceTokenRepository implements DeviceTokenRepository interface.
+	deviceTokenRepository struct{ *Repository }
+)
+
+// NewDeviceTokenRepository creates a new instance of DeviceTokenRepository with the provided Repository parameter.
+func NewDeviceTokenRepository(r *Repository) DeviceTokenRepository {
+	return &deviceTokenRepository{r}
+}
+
+func (r *deviceTokenRepository) Register(deviceToken *model.DeviceToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token = ? AND NOT (account_id = ? AND device_id = ? AND platform = ?)",
+			deviceToken.Token, deviceToken.AccountID, deviceToken.DeviceID, deviceToken.Platform).
+			Delete(&model.DeviceToken{}).Error; err != nil {
+			return err
+		}
+
+		deviceToken.Active = true
+		deviceToken.LastSeenAt = time.Now()
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "account_id"}, {Name: "device_id"}, {Name: "platform"}},
+			DoUpdates: clause.AssignmentColumns([]string{"token", "provider", "p256dh", "auth", "active", "last_seen_at", "updated_at"}),
+		}).Create(deviceToken).Error
+	})
+}
+
+func (r *deviceTokenRepository) Remove(accountID, deviceID uuid.UUID) ([]*model.DeviceToken, error) {
+	var deviceTokens []*model.DeviceToken
+	if err := r.db.Clauses(clause.Returning{}).
+		Where("account_id = ? AND device_id = ?", accountID, deviceID).
+		Delete(&deviceTokens).Error; err != nil {
+		return nil, err
+	}
+
+	return deviceTokens, nil
+}
+
+func (r *deviceTokenRepository) Touch(accountID, deviceID uuid.UUID, seenAt time.Time) error {
+	return r.db.Model(&model.DeviceToken{}).
+		Where("account_id = ? AND device_id = ?", accountID, deviceID).
+		Update("last_seen_at", seenAt).Error
+}
+
+func (r *deviceTokenRepository) FindByID(id uuid.UUID) (*model.DeviceToken, error) {
+	var deviceToken model.DeviceToken
+	if err := r.db.Where("id = ?", id).First(&deviceToken).Error; err != nil {
+		return nil, err
+	}
+
+	return &deviceToken, nil
+}
+
+func (r *deviceTokenRepository) FindByIDs(ids []uuid.UUID) ([]*model.DeviceToken, error) {
+	var deviceTokens []*model.DeviceToken
+	if err := r.db.Where("id IN ?", ids).Find(&deviceTokens).Error; err != nil {
+		return nil, err
+	}
+
+	return deviceTokens, nil
+}
+
+func (r *deviceTokenRepository) GetActive(accountID uuid.UUID) ([]*model.DeviceToken, error) {
+	var deviceTokens []*model.DeviceToken
+	if err := r.db.Where("account_id = ? AND active = ?", accountID, true).
+		Order("last_seen_at DESC").
+		Find(&deviceTokens).Error; err != nil {
+		return nil, err
+	}
+
+	return deviceTokens, nil
+}
+
+func (r *deviceTokenRepository) Deactivate(id uuid.UUID) error {
+	return r.db.Model(&model.DeviceToken{}).
+		Where("id = ?", id).
+		Update("active", false).Error
+}
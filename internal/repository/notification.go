@@ -1,67 +1,416 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"github.com/arifai/zenith/internal/model"
 	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"maps"
+	"strconv"
 	"time"
 )
 
 type (
 	// NotificationRepository defines methods for interacting with notifications in a data store.
 	// GetList fetches a list of notifications and the total count based on account ID and pagination info.
-	// MarkAsRead marks a specific notification as read by its ID and returns if it was found and updated successfully.
+	// Every mutation is scoped to the account owning the notifications and keeps the cached unread count in step.
 	NotificationRepository interface {
 
-		// GetList fetches a list of notifications and the total count for a given account ID and pagination parameters.
-		GetList(id *uuid.UUID, paging *common.Pagination) (notifications []*model.Notification, count int64, err error)
+		// GetList fetches a list of notifications and the total count for a given account ID and pagination parameters,
+		// listing either the inbox or the archived notifications filtered and sorted by the query. Expired notifications
+		// are left out. With a cursor the list is fetched by keyset as described by common.Keyset, otherwise by offset.
+		GetList(id *uuid.UUID, paging *common.Pagination, query *common.ListQuery, cursor *common.Cursor, archived bool) (notifications []*model.Notification, count int64, err error)
 
-		// MarkAsRead marks a specific notification as read by its ID and returns if it was found and updated successfully.
-		MarkAsRead(id uuid.UUID) (founded bool, err error)
+		// SetRead sets the read state of the notifications of an account with the given IDs. It returns how many of them
+		// were found and the notifications whose state changed.
+		SetRead(accountID uuid.UUID, ids []uuid.UUID, read bool) (found int, changed []*model.Notification, err error)
+
+		// MarkAllAsRead marks every unread notification of an account as read and returns how many were changed.
+		MarkAllAsRead(accountID uuid.UUID, readAt time.Time) (int64, error)
+
+		// SetArchived archives or unarchives a notification of an account, returning if it was found.
+		SetArchived(accountID, id uuid.UUID, archived bool) (founded bool, err error)
+
+		// Delete soft deletes a notification of an account, returning if it was found.
+		Delete(accountID, id uuid.UUID) (founded bool, err error)
+
+		// CountUnread returns the number of unread notifications in the inbox of an account, cached in Redis. Expired
+		// notifications are not counted, a cached count catches up with them when it is recounted.
+		CountUnread(accountID uuid.UUID) (int64, error)
+
+		// Unread fetches up to limit of the newest unread notifications of an account in the inbox or stored for email
+		// only, of the given categories and created after since and at or before until, together with their total count.
+		// Expired notifications are left out.
+		Unread(accountID uuid.UUID, categories []model.NotificationCategory, since, until time.Time, limit int) (notifications []*model.Notification, count int64, err error)
+
+		// Send creates an inbox entry together with one pending push per active device of the account, built from the given
+		// push, in a single transaction. Either may be nil to skip that channel. The pushes are linked to the entry, wait
+		// until the NextAttemptAt of the given push when it is set, and are returned. An entry with EmailOnly is stored
+		// for the email digest only, it is neither counted as unread nor linked to the pushes.
+		Send(accountID uuid.UUID, notification *model.Notification, push *model.PushNotification) ([]*model.PushNotification, error)
 	}
 
 	// notificationRepository implements NotificationRepository interface, provides repository functions for notifications.
 	notificationRepository struct{ *Repository }
 )
 
+const (
+	// unreadCountPrefix prefixes the Redis key caching the unread count of an account.
+	unreadCountPrefix = "notification:unread:"
+
+	// unreadCountTTL bounds how long a cached unread count may drift from the table, e.g. after a failed adjustment.
+	unreadCountTTL = time.Hour
+)
+
+// adjustScript adjusts a cached unread count, leaving missing counts to be recounted on the next read.
+var adjustScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return redis.call('INCRBY', KEYS[1], ARGV[1])
+end
+return false
+`)
+
 // NewNotificationRepository creates a new instance of NotificationRepository with the provided Repository parameter.
 func NewNotificationRepository(r *Repository) NotificationRepository {
 	return &notificationRepository{r}
 }
 
-func (r *notificationRepository) GetList(id *uuid.UUID, paging *common.Pagination) (notifications []*model.Notification, count int64, err error) {
+func (r *notificationRepository) GetList(id *uuid.UUID, paging *common.Pagination, query *common.ListQuery, cursor *common.Cursor, archived bool) (notifications []*model.Notification, count int64, err error) {
+	now := time.Now()
 	if err = r.db.Model(&model.Notification{}).
+		Scopes(common.Filtered(paging, query, "title"), inboxScope, archivedScope(archived), unexpiredScope(now)).
 		Where("account_id = ?", id).
 		Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
 
+	page := common.Paginate(paging, query, "title")
+	if cursor != nil {
+		page = common.Keyset(paging, cursor, query, "title")
+	}
+
 	if err = r.db.Model(&model.Notification{}).
-		Scopes(common.Paginate(paging, "title")).
+		Scopes(page, inboxScope, archivedScope(archived), unexpiredScope(now)).
 		Where("account_id = ?", id).
 		Find(&notifications).Error; err != nil {
 		return nil, 0, err
 	}
 
-	count = int64(len(notifications))
-
 	return notifications, count, nil
 }
 
-func (r *notificationRepository) MarkAsRead(id uuid.UUID) (founded bool, err error) {
+func (r *notificationRepository) SetRead(accountID uuid.UUID, ids []uuid.UUID, read bool) (found int, changed []*model.Notification, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var notifications []*model.Notification
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(inboxScope).
+			Where("account_id = ? AND id IN ?", accountID, ids).
+			Find(&notifications).Error; err != nil {
+			return err
+		}
+		found = len(notifications)
+
+		changedIDs := make([]uuid.UUID, 0, len(notifications))
+		for _, notification := range notifications {
+			if notification.Read != read {
+				changed = append(changed, notification)
+				changedIDs = append(changedIDs, notification.ID)
+			}
+		}
+		if len(changed) == 0 {
+			return nil
+		}
+
+		var readAt *time.Time
+		if read {
+			now := time.Now()
+			readAt = &now
+		}
+		for _, notification := range changed {
+			notification.Read, notification.ReadAt = read, readAt
+		}
+
+		return tx.Model(&model.Notification{}).
+			Where("id IN ?", changedIDs).
+			Updates(map[string]interface{}{"read": read, "read_at": readAt}).Error
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	delta := int64(0)
+	now := time.Now()
+	for _, notification := range changed {
+		if countedAsUnread(notification, now) {
+			delta++
+		}
+	}
+	if read {
+		delta = -delta
+	}
+	r.adjustUnread(accountID, delta)
+
+	return found, changed, nil
+}
+
+func (r *notificationRepository) MarkAllAsRead(accountID uuid.UUID, readAt time.Time) (int64, error) {
 	result := r.db.Model(&model.Notification{}).
-		Clauses(clause.Returning{}).
-		Where(&model.Notification{ID: id}).
-		Updates(map[string]interface{}{"read": true, "read_at": time.Now()})
+		Scopes(inboxScope).
+		Where("account_id = ? AND read = ?", accountID, false).
+		Updates(map[string]interface{}{"read": true, "read_at": readAt})
+	if result.Error != nil {
+		return 0, result.Error
+	}
 
+	if result.RowsAffected > 0 {
+		r.resetUnread(accountID)
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (r *notificationRepository) SetArchived(accountID, id uuid.UUID, archived bool) (founded bool, err error) {
+	var notification model.Notification
+	changed := false
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(inboxScope).
+			Where("account_id = ? AND id = ?", accountID, id).
+			First(&notification).Error; err != nil {
+			return err
+		}
+
+		if (notification.ArchivedAt != nil) == archived {
+			return nil
+		}
+		changed = true
+
+		var archivedAt *time.Time
+		if archived {
+			now := time.Now()
+			archivedAt = &now
+		}
+
+		return tx.Model(&notification).Update("archived_at", archivedAt).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if changed && !notification.Read && unexpired(&notification, time.Now()) {
+		if archived {
+			r.adjustUnread(accountID, -1)
+		} else {
+			r.adjustUnread(accountID, 1)
+		}
+	}
+
+	return true, nil
+}
+
+func (r *notificationRepository) Delete(accountID, id uuid.UUID) (founded bool, err error) {
+	var notifications []*model.Notification
+	result := r.db.Clauses(clause.Returning{}).
+		Scopes(inboxScope).
+		Where("account_id = ? AND id = ?", accountID, id).
+		Delete(&notifications)
 	if result.Error != nil {
 		return false, result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return false, err
+		return false, nil
+	}
+
+	now := time.Now()
+	for _, notification := range notifications {
+		if !notification.Read && countedAsUnread(notification, now) {
+			r.adjustUnread(accountID, -1)
+		}
 	}
 
 	return true, nil
 }
+
+func (r *notificationRepository) CountUnread(accountID uuid.UUID) (int64, error) {
+	ctx, key := context.Background(), unreadCountPrefix+accountID.String()
+
+	cached, err := r.redis.Get(ctx, key).Result()
+	if err == nil {
+		if count, err := strconv.ParseInt(cached, 10, 64); err == nil && count >= 0 {
+			return count, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.Model(&model.Notification{}).
+		Scopes(inboxScope, archivedScope(false), unexpiredScope(time.Now())).
+		Where("account_id = ? AND read = ?", accountID, false).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	// The recount is only stored if no concurrent change stored a count meanwhile, which is adjusted already.
+	if err := r.redis.SetNX(ctx, key, count, unreadCountTTL).Err(); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *notificationRepository) Unread(accountID uuid.UUID, categories []model.NotificationCategory, since, until time.Time, limit int) (notifications []*model.Notification, count int64, err error) {
+	scope := func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(archivedScope(false), unexpiredScope(until)).
+			Where("account_id = ? AND read = ? AND category IN ?", accountID, false, categories).
+			Where("created_at > ? AND created_at <= ?", since, until)
+	}
+
+	if err = r.db.Model(&model.Notification{}).Scopes(scope).Count(&count).Error; err != nil || count == 0 {
+		return nil, 0, err
+	}
+
+	if err = r.db.Scopes(scope).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, count, nil
+}
+
+func (r *notificationRepository) Send(accountID uuid.UUID, notification *model.Notification, push *model.PushNotification) ([]*model.PushNotification, error) {
+	var pushes []*model.PushNotification
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var accounts int64
+		if err := tx.Model(&model.Account{}).Where("id = ?", accountID).Count(&accounts).Error; err != nil {
+			return err
+		}
+
+		if accounts == 0 {
+			return errormessage.ErrAccountNotFound
+		}
+
+		if notification != nil {
+			notification.AccountID = accountID
+			if err := tx.Create(notification).Error; err != nil {
+				return err
+			}
+		}
+
+		if push == nil {
+			return nil
+		}
+
+		var deviceTokens []*model.DeviceToken
+		if err := tx.Where("account_id = ? AND active = ?", accountID, true).
+			Find(&deviceTokens).Error; err != nil || len(deviceTokens) == 0 {
+			return err
+		}
+
+		data := maps.Clone(push.Data)
+		var notificationID *uuid.UUID
+		if notification != nil && !notification.EmailOnly {
+			if data == nil {
+				data = make(map[string]string, 1)
+			}
+			data[model.PushDataNotificationID] = notification.ID.String()
+			notificationID = &notification.ID
+		}
+
+		nextAttemptAt := push.NextAttemptAt
+		if nextAttemptAt.IsZero() {
+			nextAttemptAt = time.Now()
+		}
+
+		for _, deviceToken := range deviceTokens {
+			pushes = append(pushes, &model.PushNotification{
+				AccountID:      accountID,
+				Title:          push.Title,
+				Message:        push.Message,
+				Image:          push.Image,
+				Data:           data,
+				Options:        push.Options,
+				Platform:       deviceToken.Platform,
+				Status:         model.Pending,
+				DeviceTokenID:  &deviceToken.ID,
+				NotificationID: notificationID,
+				Template:       push.Template,
+				NextAttemptAt:  nextAttemptAt,
+			})
+		}
+
+		return tx.Create(&pushes).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if notification != nil && !notification.EmailOnly {
+		r.adjustUnread(accountID, 1)
+	}
+
+	return pushes, nil
+}
+
+// adjustUnread adjusts the cached unread count of an account by delta. The count is dropped if that fails, so it is
+// recounted from the table instead of drifting.
+func (r *notificationRepository) adjustUnread(accountID uuid.UUID, delta int64) {
+	if delta == 0 {
+		return
+	}
+
+	key := unreadCountPrefix + accountID.String()
+	if err := adjustScript.Run(context.Background(), r.redis, []string{key}, delta).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		r.resetUnread(accountID)
+	}
+}
+
+// resetUnread drops the cached unread count of an account, the next read recounts it from the table.
+func (r *notificationRepository) resetUnread(accountID uuid.UUID) {
+	_ = r.redis.Del(context.Background(), unreadCountPrefix+accountID.String()).Err()
+}
+
+// archivedScope limits a query to the archived notifications or to those in the inbox.
+func archivedScope(archived bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if archived {
+			return db.Where("archived_at IS NOT NULL")
+		}
+
+		return db.Where("archived_at IS NULL")
+	}
+}
+
+// countedAsUnread reports whether a notification is counted by the cached unread count when it is unread, which like
+// CountUnread leaves out archived notifications and those expired at the given time.
+func countedAsUnread(notification *model.Notification, now time.Time) bool {
+	return notification.ArchivedAt == nil && unexpired(notification, now)
+}
+
+// unexpired reports whether a notification has not expired at the given time, matching unexpiredScope.
+func unexpired(notification *model.Notification, now time.Time) bool {
+	return notification.ExpiresAt == nil || notification.ExpiresAt.After(now)
+}
+
+// inboxScope limits a query to the notifications shown in the inbox, leaving out those stored for email only.
+func inboxScope(db *gorm.DB) *gorm.DB {
+	return db.Where("email_only = ?", false)
+}
+
+// unexpiredScope limits a query to the notifications that have not expired at the given time.
+func unexpiredScope(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(expires_at IS NULL OR expires_at > ?)", now)
+	}
+}
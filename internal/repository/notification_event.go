@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// NotificationEventRepository defines methods for publishing the events of notification streams through Redis,
+	// which fans them out to the streams open on every replica, and for limiting the streams of an account.
+	NotificationEventRepository interface {
+		// Publish appends the event to the history of its account, assigning its ID, and announces it to all replicas.
+		Publish(event *model.NotificationEvent) error
+
+		// Since retrieves the events of an account published after the event with the given ID, as far as they are
+		// still in the history.
+		Since(accountID uuid.UUID, lastEventID string) ([]*model.NotificationEvent, error)
+
+		// Subscribe starts receiving the events published for an account. The channel is closed by the returned
+		// unsubscribe function, or early when the receiver does not keep up with the events.
+		Subscribe(accountID uuid.UUID) (events <-chan *model.NotificationEvent, unsubscribe func(), err error)
+
+		// AcquireConnection registers a stream of an account unless it already has limit streams open. The registration
+		// expires after ttl unless refreshed.
+		AcquireConnection(accountID uuid.UUID, connectionID string, limit int, ttl time.Duration) (acquired bool, err error)
+
+		// RefreshConnection extends the registration of a stream by ttl.
+		RefreshConnection(accountID uuid.UUID, connectionID string, ttl time.Duration) error
+
+		// ReleaseConnection removes the registration of a stream.
+		ReleaseConnection(accountID uuid.UUID, connectionID string) error
+	}
+
+	// notificationEventRepository implements NotificationEventRepository interface.
+	notificationEventRepository struct {
+		*Repository
+		hub *eventHub
+	}
+
+	// eventHub holds the single Redis subscription of a replica and hands the received events to the local
+	// subscribers of their account.
+	eventHub struct {
+		redis *redis.Client
+
+		mu          sync.Mutex
+		pubsub      *redis.PubSub
+		subscribers map[uuid.UUID]map[chan *model.NotificationEvent]struct{}
+	}
+
+	// eventEnvelope is the encoding of an event in the history and on the channel.
+	eventEnvelope struct {
+		AccountID uuid.UUID                   `json:"account_id"`
+		Type      model.NotificationEventType `json:"type"`
+		Data      json.RawMessage             `json:"data,omitempty"`
+	}
+)
+
+const (
+	// notificationEventChannel is the Redis channel events are published on, each message is the ID of the event
+	// followed by a space and its envelope.
+	notificationEventChannel = "notification:events"
+
+	// eventHistoryPrefix prefixes the Redis stream holding the recent events of an account.
+	eventHistoryPrefix = "notification:events:"
+
+	// streamConnectionsPrefix prefixes the Redis sorted set of the open streams of an account, scored by expiry.
+	streamConnectionsPrefix = "notification:streams:"
+
+	// eventHistoryLength is the approximate number of events kept per account for resuming streams.
+	eventHistoryLength = 1000
+
+	// eventHistoryTTL is how long the history of an account is kept after its last event.
+	eventHistoryTTL = time.Hour * 24
+
+	// subscriberBuffer is the number of events buffered for a subscriber before it is dropped as too slow.
+	subscriberBuffer = 64
+)
+
+var (
+	// publishScript appends an event to the history and publishes it in one step, so events reach the channel in the
+	// order of their IDs.
+	publishScript = redis.NewScript(`
+local id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'event', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+redis.call('PUBLISH', ARGV[4], id .. ' ' .. ARGV[2])
+return id
+`)
+
+	// acquireScript drops expired registrations and registers the connection if the limit is not reached yet.
+	acquireScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+return 1
+`)
+)
+
+// NewNotificationEventRepository creates a new instance of NotificationEventRepository with the provided Repository
+// parameter.
+func NewNotificationEventRepository(r *Repository) NotificationEventRepository {
+	return &notificationEventRepository{
+		Repository: r,
+		hub:        &eventHub{redis: r.redis, subscribers: make(map[uuid.UUID]map[chan *model.NotificationEvent]struct{})},
+	}
+}
+
+func (r *notificationEventRepository) Publish(event *model.NotificationEvent) error {
+	envelope, err := json.Marshal(&eventEnvelope{AccountID: event.AccountID, Type: event.Type, Data: event.Data})
+	if err != nil {
+		return err
+	}
+
+	id, err := publishScript.Run(context.Background(), r.redis,
+		[]string{eventHistoryPrefix + event.AccountID.String()},
+		eventHistoryLength, envelope, eventHistoryTTL.Milliseconds(), notificationEventChannel,
+	).Text()
+	if err != nil {
+		return err
+	}
+	event.ID = id
+
+	return nil
+}
+
+func (r *notificationEventRepository) Since(accountID uuid.UUID, lastEventID string) ([]*model.NotificationEvent, error) {
+	messages, err := r.redis.XRangeN(context.Background(), eventHistoryPrefix+accountID.String(), "("+lastEventID, "+", eventHistoryLength).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*model.NotificationEvent, 0, len(messages))
+	for _, message := range messages {
+		payload, _ := message.Values["event"].(string)
+		if event, err := decodeEvent(message.ID, payload); err == nil {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (r *notificationEventRepository) Subscribe(accountID uuid.UUID) (<-chan *model.NotificationEvent, func(), error) {
+	return r.hub.subscribe(accountID)
+}
+
+func (r *notificationEventRepository) AcquireConnection(accountID uuid.UUID, connectionID string, limit int, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	acquired, err := acquireScript.Run(context.Background(), r.redis,
+		[]string{streamConnectionsPrefix + accountID.String()},
+		now.UnixMilli(), limit, now.Add(ttl).UnixMilli(), connectionID, ttl.Milliseconds(),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return acquired == 1, nil
+}
+
+func (r *notificationEventRepository) RefreshConnection(accountID uuid.UUID, connectionID string, ttl time.Duration) error {
+	ctx, key := context.Background(), streamConnectionsPrefix+accountID.String()
+
+	_, err := r.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAddXX(ctx, key, redis.Z{Score: float64(time.Now().Add(ttl).UnixMilli()), Member: connectionID})
+		pipe.PExpire(ctx, key, ttl)
+		return nil
+	})
+
+	return err
+}
+
+func (r *notificationEventRepository) ReleaseConnection(accountID uuid.UUID, connectionID string) error {
+	return r.redis.ZRem(context.Background(), streamConnectionsPrefix+accountID.String(), connectionID).Err()
+}
+
+// subscribe registers a subscriber of an account, subscribing to the channel on first use.
+func (h *eventHub) subscribe(accountID uuid.UUID) (<-chan *model.NotificationEvent, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.pubsub == nil {
+		pubsub := h.redis.Subscribe(context.Background(), notificationEventChannel)
+		if _, err := pubsub.Receive(context.Background()); err != nil {
+			_ = pubsub.Close()
+			return nil, nil, err
+		}
+
+		h.pubsub = pubsub
+		go h.run(pubsub.Channel())
+	}
+
+	events := make(chan *model.NotificationEvent, subscriberBuffer)
+	if h.subscribers[accountID] == nil {
+		h.subscribers[accountID] = make(map[chan *model.NotificationEvent]struct{})
+	}
+	h.subscribers[accountID][events] = struct{}{}
+
+	return events, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(accountID, events)
+	}, nil
+}
+
+// run hands the messages of the channel to the subscribers of their account. Subscribers whose buffer is full are
+// dropped, they resume from the history once they reconnect.
+func (h *eventHub) run(messages <-chan *redis.Message) {
+	for message := range messages {
+		id, payload, _ := strings.Cut(message.Payload, " ")
+		event, err := decodeEvent(id, payload)
+		if err != nil {
+			continue
+		}
+
+		h.mu.Lock()
+		for events := range h.subscribers[event.AccountID] {
+			select {
+			case events <- event:
+			default:
+				h.remove(event.AccountID, events)
+			}
+		}
+		h.mu.Unlock()
+	}
+}
+
+// remove closes a subscriber if it is still registered. The caller must hold the lock.
+func (h *eventHub) remove(accountID uuid.UUID, events chan *model.NotificationEvent) {
+	if _, ok := h.subscribers[accountID][events]; !ok {
+		return
+	}
+
+	delete(h.subscribers[accountID], events)
+	if len(h.subscribers[accountID]) == 0 {
+		delete(h.subscribers, accountID)
+	}
+	close(events)
+}
+
+// decodeEvent decodes the envelope of the event with the given ID.
+func decodeEvent(id, payload string) (*model.NotificationEvent, error) {
+	var envelope eventEnvelope
+	if err := json.Unmarshal([]byte(payload), &envelope); err != nil {
+		return nil, err
+	}
+
+	return &model.NotificationEvent{ID: id, Type: envelope.Type, AccountID: envelope.AccountID, Data: envelope.Data}, nil
+}
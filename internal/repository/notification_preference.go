@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type (
+	// NotificationPreferenceRepository defines methods for managing the notification preferences of accounts.
+	NotificationPreferenceRepository interface {
+		// Get retrieves the notification preferences of an account, returning the defaults if it never changed them.
+		Get(accountID uuid.UUID) (*model.NotificationPreference, error)
+
+		// GetMany retrieves the notification preferences of the given accounts by their ID, with the defaults for the
+		// accounts that never changed them.
+		GetMany(accountIDs []uuid.UUID) (map[uuid.UUID]*model.NotificationPreference, error)
+
+		// Save creates or replaces the notification preferences of an account. The time of the last digest is kept.
+		Save(preference *model.NotificationPreference) error
+
+		// DueDigests fetches up to limit active accounts with email enabled for any category whose last digest was sent
+		// at or before dueBefore, or never. Accounts are ordered by ID and start after the given one, so every batch
+		// continues the previous one.
+		DueDigests(dueBefore time.Time, after uuid.UUID, limit int) ([]*model.DigestRecipient, error)
+
+		// ClaimDigest records that a digest was sent to an account at the given time, provided its last digest is still
+		// the given one. It returns false if another digest was recorded in the meantime.
+		ClaimDigest(accountID uuid.UUID, last *time.Time, at time.Time) (bool, error)
+	}
+
+	// notificationPreferenceRepository implements NotificationPreferenceRepository interface.
+	notificationPreferenceRepository struct{ *Repository }
+)
+
+// NewNotificationPreferenceRepository creates a new instance of NotificationPreferenceRepository with the provided
+// Repository parameter.
+func NewNotificationPreferenceRepository(r *Repository) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{r}
+}
+
+func (r *notificationPreferenceRepository) Get(accountID uuid.UUID) (*model.NotificationPreference, error) {
+	var preference model.NotificationPreference
+	if err := r.db.Where("account_id = ?", accountID).First(&preference).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.DefaultNotificationPreference(accountID), nil
+		}
+		return nil, err
+	}
+
+	return &preference, nil
+}
+
+func (r *notificationPreferenceRepository) GetMany(accountIDs []uuid.UUID) (map[uuid.UUID]*model.NotificationPreference, error) {
+	var preferences []*model.NotificationPreference
+	if err := r.db.Where("account_id IN ?", accountIDs).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+
+	byAccount := make(map[uuid.UUID]*model.NotificationPreference, len(accountIDs))
+	for _, preference := range preferences {
+		byAccount[preference.AccountID] = preference
+	}
+	for _, accountID := range accountIDs {
+		if _, ok := byAccount[accountID]; !ok {
+			byAccount[accountID] = model.DefaultNotificationPreference(accountID)
+		}
+	}
+
+	return byAccount, nil
+}
+
+func (r *notificationPreferenceRepository) Save(preference *model.NotificationPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timezone", "locale", "quiet_hours_start", "quiet_hours_end", "categories", "updated_at"}),
+	}).Create(preference).Error
+}
+
+func (r *notificationPreferenceRepository) DueDigests(dueBefore time.Time, after uuid.UUID, limit int) ([]*model.DigestRecipient, error) {
+	var recipients []*model.DigestRecipient
+	if err := r.db.Model(&model.NotificationPreference{}).
+		Select("notification_preferences.*, accounts.email, accounts.full_name").
+		Joins("JOIN accounts ON accounts.id = notification_preferences.account_id AND accounts.active = ?", true).
+		Where("(notification_preferences.last_digest_at IS NULL OR notification_preferences.last_digest_at <= ?)", dueBefore).
+		Where("EXISTS (SELECT 1 FROM jsonb_each(notification_preferences.categories) AS c WHERE c.value->>'email' = 'true')").
+		Where("notification_preferences.account_id > ?", after).
+		Order("notification_preferences.account_id").
+		Limit(limit).
+		Find(&recipients).Error; err != nil {
+		return nil, err
+	}
+
+	return recipients, nil
+}
+
+func (r *notificationPreferenceRepository) ClaimDigest(accountID uuid.UUID, last *time.Time, at time.Time) (bool, error) {
+	query := r.db.Model(&model.NotificationPreference{}).Where("account_id = ?", accountID)
+	if last == nil {
+		query = query.Where("last_digest_at IS NULL")
+	} else {
+		query = query.Where("last_digest_at = ?", *last)
+	}
+
+	result := query.UpdateColumn("last_digest_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
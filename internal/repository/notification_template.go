@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// NotificationTemplateRepository defines methods for managing notification templates.
+	NotificationTemplateRepository interface {
+		// GetList fetches a page of templates filtered and sorted by the query, and the total count of matching
+		// templates. The search term matches the name.
+		GetList(paging *common.Pagination, query *common.ListQuery) (templates []*model.NotificationTemplate, count int64, err error)
+
+		// Find retrieves the template with the given name in the first of the locales it exists in, returning
+		// errormessage.ErrTemplateNotFound if it exists in none of them.
+		Find(name string, locales []string) (*model.NotificationTemplate, error)
+
+		// Save creates the template or replaces the template with the same name and locale.
+		Save(template *model.NotificationTemplate) error
+
+		// Delete deletes a template by its ID, returning if it was found.
+		Delete(id uuid.UUID) (founded bool, err error)
+	}
+
+	// notificationTemplateRepository implements NotificationTemplateRepository interface.
+	notificationTemplateRepository struct{ *Repository }
+)
+
+// NewNotificationTemplateRepository creates a new instance of NotificationTemplateRepository with the provided
+// Repository parameter.
+func NewNotificationTemplateRepository(r *Repository) NotificationTemplateRepository {
+	return &notificationTemplateRepository{r}
+}
+
+func (r *notificationTemplateRepository) GetList(paging *common.Pagination, query *common.ListQuery) (templates []*model.NotificationTemplate, count int64, err error) {
+	if err = r.db.Model(&model.NotificationTemplate{}).
+		Scopes(common.Filtered(paging, query, "name")).
+		Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err = r.db.Model(&model.NotificationTemplate{}).
+		Scopes(common.Paginate(paging, query, "name")).
+		Find(&templates).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return templates, count, nil
+}
+
+func (r *notificationTemplateRepository) Find(name string, locales []string) (*model.NotificationTemplate, error) {
+	var templates []*model.NotificationTemplate
+	if err := r.db.Where("name = ? AND locale IN ?", name, locales).Find(&templates).Error; err != nil {
+		return nil, err
+	}
+
+	for _, locale := range locales {
+		for _, template := range templates {
+			if template.Locale == locale {
+				return template, nil
+			}
+		}
+	}
+
+	return nil, errormessage.ErrTemplateNotFound
+}
+
+func (r *notificationTemplateRepository) Save(template *model.NotificationTemplate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"category", "title", "short_description", "description", "updated_at"}),
+	}, clause.Returning{}).Create(template).Error
+}
+
+func (r *notificationTemplateRepository) Delete(id uuid.UUID) (founded bool, err error) {
+	result := r.db.Where("id = ?", id).Delete(&model.NotificationTemplate{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"testing"
+	"time"
+)
+
+func TestCountedAsUnread(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name         string
+		notification *model.Notification
+		want         bool
+	}{
+		{name: "inbox", notification: &model.Notification{}, want: true},
+		{name: "not yet expired", notification: &model.Notification{ExpiresAt: &future}, want: true},
+		{name: "expired", notification: &model.Notification{ExpiresAt: &past}, want: false},
+		{name: "expiring now", notification: &model.Notification{ExpiresAt: &now}, want: false},
+		{name: "archived", notification: &model.Notification{ArchivedAt: &past}, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := countedAsUnread(tt.notification, now); got != tt.want {
+			t.Errorf("%s: countedAsUnread() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
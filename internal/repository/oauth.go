@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type (
+	// OAuthRepository defines methods to interact with OAuth2 clients, authorization codes and consents in the database.
+	OAuthRepository interface {
+		// CreateClient inserts a new OAuth2 client into the database.
+		CreateClient(client *model.OAuthClient) error
+
+		// FindClient retrieves an OAuth2 client that has not been revoked by its ID.
+		FindClient(id uuid.UUID) (*model.OAuthClient, error)
+
+		// GetClients retrieves all clients registered by the given account, newest first.
+		GetClients(ownerAccountID uuid.UUID) ([]*model.OAuthClient, error)
+
+		// RevokeClient marks a client registered by the given account as revoked and returns if it was found.
+		RevokeClient(ownerAccountID, id uuid.UUID) (founded bool, err error)
+
+		// FindConsent retrieves the consent the account has granted to the client.
+		FindConsent(accountID, clientID uuid.UUID) (*model.OAuthConsent, error)
+
+		// SaveConsent inserts the consent or replaces the scopes of an existing consent of the same account and client.
+		SaveConsent(consent *model.OAuthConsent) error
+
+		// CreateAuthorizationCode inserts a new authorization code into the database.
+		CreateAuthorizationCode(code *model.OAuthAuthorizationCode) error
+
+		// ConsumeAuthorizationCode deletes the authorization code identified by its hash and returns it, so that it can
+		// only be redeemed once.
+		ConsumeAuthorizationCode(codeHash string) (*model.OAuthAuthorizationCode, error)
+	}
+
+	// oauthRepository implements OAuthRepository interface, provides repository functions for OAuth2.
+	oauthRepository struct{ *Repository }
+)
+
+// NewOAuthRepository creates a new instance of OAuthRepository with the provided Repository parameter.
+func NewOAuthRepository(r *Repository) OAuthRepository {
+	return &oauthRepository{r}
+}
+
+func (r *oauthRepository) CreateClient(client *model.OAuthClient) error {
+	return r.db.Create(client).Error
+}
+
+func (r *oauthRepository) FindClient(id uuid.UUID) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	if err := r.db.Where("id = ? AND revoked_at IS NULL", id).First(&client).Error; err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+func (r *oauthRepository) GetClients(ownerAccountID uuid.UUID) ([]*model.OAuthClient, error) {
+	var clients []*model.OAuthClient
+	if err := r.db.Where("owner_account_id = ?", ownerAccountID).
+		Order("created_at DESC").
+		Find(&clients).Error; err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+func (r *oauthRepository) RevokeClient(ownerAccountID, id uuid.UUID) (founded bool, err error) {
+	result := r.db.Model(&model.OAuthClient{}).
+		Where("id = ? AND owner_account_id = ? AND revoked_at IS NULL", id, ownerAccountID).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *oauthRepository) FindConsent(accountID, clientID uuid.UUID) (*model.OAuthConsent, error) {
+	var consent model.OAuthConsent
+	if err := r.db.Where("account_id = ? AND client_id = ?", accountID, clientID).First(&consent).Error; err != nil {
+		return nil, err
+	}
+
+	return &consent, nil
+}
+
+func (r *oauthRepository) SaveConsent(consent *model.OAuthConsent) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scopes", "updated_at"}),
+	}).Create(consent).Error
+}
+
+func (r *oauthRepository) CreateAuthorizationCode(code *model.OAuthAuthorizationCode) error {
+	return r.db.Create(code).Error
+}
+
+func (r *oauthRepository) ConsumeAuthorizationCode(codeHash string) (*model.OAuthAuthorizationCode, error) {
+	var codes []*model.OAuthAuthorizationCode
+	if err := r.db.Clauses(clause.Returning{}).
+		Where("code_hash = ?", codeHash).
+		Delete(&codes).Error; err != nil {
+		return nil, err
+	}
+
+	if len(codes) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return codes[0], nil
+}
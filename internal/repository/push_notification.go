@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type (
+	// PushNotificationRepository defines methods used by the push dispatcher to claim and settle push notifications.
+	// A claimed notification is leased to a single dispatcher until its lease expires, so concurrent dispatchers on
+	// several replicas never deliver the same notification twice, while notifications of a crashed dispatcher are
+	// picked up again once their lease has expired.
+	PushNotificationRepository interface {
+		// Create stores a pending notification for the dispatcher to deliver.
+		Create(notification *model.PushNotification) error
+
+		// ClaimPending leases up to limit pending notifications that are due to the given dispatcher for the lease duration.
+		// Rows locked by a concurrent claim are skipped instead of waited for.
+		ClaimPending(dispatcherID uuid.UUID, limit int, lease time.Duration) ([]*model.PushNotification, error)
+
+		// MarkSent marks a notification leased by the dispatcher as successfully delivered.
+		MarkSent(id, dispatcherID uuid.UUID, response string) error
+
+		// MarkRetry releases a notification leased by the dispatcher and schedules its next delivery attempt.
+		MarkRetry(id, dispatcherID uuid.UUID, retries int8, nextAttemptAt time.Time, response, lastError string) error
+
+		// MarkFailed marks a notification leased by the dispatcher as permanently failed.
+		MarkFailed(id, dispatcherID uuid.UUID, retries int8, response, lastError string) error
+
+		// FanOut replaces an account-wide notification leased by the dispatcher with one pending notification per device
+		// token, settling the account-wide notification as sent.
+		FanOut(notification *model.PushNotification, dispatcherID uuid.UUID, deviceTokens []*model.DeviceToken) error
+	}
+
+	// pushNotificationRepository implements PushNotificationRepository interface.
+	pushNotificationRepository struct{ *Repository }
+)
+
+// NewPushNotificationRepository creates a new instance of PushNotificationRepository with the provided Repository parameter.
+func NewPushNotificationRepository(r *Repository) PushNotificationRepository {
+	return &pushNotificationRepository{r}
+}
+
+func (r *pushNotificationRepository) Create(notification *model.PushNotification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *pushNotificationRepository) ClaimPending(dispatcherID uuid.UUID, limit int, lease time.Duration) ([]*model.PushNotification, error) {
+	var notifications []*model.PushNotification
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var ids []uuid.UUID
+		if err := tx.Model(&model.PushNotification{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.Pending, now).
+			Where("locked_until IS NULL OR locked_until <= ?", now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(&model.PushNotification{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"locked_by": dispatcherID, "locked_until": now.Add(lease)}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id IN ?", ids).Order("next_attempt_at").Find(&notifications).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *pushNotificationRepository) MarkSent(id, dispatcherID uuid.UUID, response string) error {
+	return r.settle(id, dispatcherID, map[string]interface{}{
+		"status":            model.Success,
+		"provider_response": response,
+		"last_error":        "",
+		"sent_at":           time.Now(),
+	})
+}
+
+func (r *pushNotificationRepository) MarkRetry(id, dispatcherID uuid.UUID, retries int8, nextAttemptAt time.Time, response, lastError string) error {
+	return r.settle(id, dispatcherID, map[string]interface{}{
+		"retries":           retries,
+		"next_attempt_at":   nextAttemptAt,
+		"provider_response": response,
+		"last_error":        lastError,
+	})
+}
+
+func (r *pushNotificationRepository) MarkFailed(id, dispatcherID uuid.UUID, retries int8, response, lastError string) error {
+	return r.settle(id, dispatcherID, map[string]interface{}{
+		"status":            model.Failure,
+		"retries":           retries,
+		"provider_response": response,
+		"last_error":        lastError,
+	})
+}
+
+func (r *pushNotificationRepository) FanOut(notification *model.PushNotification, dispatcherID uuid.UUID, deviceTokens []*model.DeviceToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.PushNotification{}).
+			Where("id = ? AND locked_by = ? AND status = ?", notification.ID, dispatcherID, model.Pending).
+			Updates(map[string]interface{}{
+				"status":            model.Success,
+				"provider_response": fmt.Sprintf("fanned out to %d devices", len(deviceTokens)),
+				"last_error":        "",
+				"sent_at":           time.Now(),
+				"locked_by":         nil,
+				"locked_until":      nil,
+			})
+		if result.Error != nil || result.RowsAffected == 0 || len(deviceTokens) == 0 {
+			return result.Error
+		}
+
+		children := make([]*model.PushNotification, 0, len(deviceTokens))
+		for _, deviceToken := range deviceTokens {
+			children = append(children, &model.PushNotification{
+				AccountID:      notification.AccountID,
+				Title:          notification.Title,
+				Message:        notification.Message,
+				Image:          notification.Image,
+				Data:           notification.Data,
+				Options:        notification.Options,
+				NotificationID: notification.NotificationID,
+				CampaignID:     notification.CampaignID,
+				Template:       notification.Template,
+				Platform:       deviceToken.Platform,
+				Status:         model.Pending,
+				ParentID:       &notification.ID,
+				DeviceTokenID:  &deviceToken.ID,
+				NextAttemptAt:  time.Now(),
+			})
+		}
+
+		return tx.Create(&children).Error
+	})
+}
+
+// settle applies the given values to a notification and releases its lease. Notifications whose lease was taken over
+// by another dispatcher are left untouched.
+func (r *pushNotificationRepository) settle(id, dispatcherID uuid.UUID, values map[string]interface{}) error {
+	values["locked_by"] = nil
+	values["locked_until"] = nil
+
+	return r.db.Model(&model.PushNotification{}).
+		Where("id = ? AND locked_by = ? AND status = ?", id, dispatcherID, model.Pending).
+		Updates(values).Error
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// PushReceiptRepository defines methods for recording the events clients report for push notifications and for
+	// aggregating them.
+	PushReceiptRepository interface {
+		// Record stores the given receipts for the pushes of an account, linking each to the device token its push was
+		// sent to. Receipts for pushes of other accounts or unknown pushes and events already recorded are skipped. It
+		// returns the number of receipts stored and the IDs of the inbox entries of the pushes opened for the first
+		// time, taken from the NotificationID of the push or else from its data under PushDataNotificationID.
+		Record(accountID uuid.UUID, receipts []*model.PushReceipt) (recorded int64, opened []uuid.UUID, err error)
+
+		// TemplateStats aggregates the pushes rendered from the template with the given name and their receipts.
+		TemplateStats(template string) (*model.PushStats, error)
+	}
+
+	// pushReceiptRepository implements PushReceiptRepository interface.
+	pushReceiptRepository struct{ *Repository }
+)
+
+// NewPushReceiptRepository creates a new instance of PushReceiptRepository with the provided Repository parameter.
+func NewPushReceiptRepository(r *Repository) PushReceiptRepository {
+	return &pushReceiptRepository{r}
+}
+
+func (r *pushReceiptRepository) Record(accountID uuid.UUID, receipts []*model.PushReceipt) (recorded int64, opened []uuid.UUID, err error) {
+	ids := make([]uuid.UUID, 0, len(receipts))
+	for _, receipt := range receipts {
+		ids = append(ids, receipt.PushNotificationID)
+	}
+
+	var pushes []*model.PushNotification
+	if err := r.db.Select("id", "device_token_id", "notification_id", "data").
+		Where("account_id = ? AND id IN ?", accountID, ids).
+		Find(&pushes).Error; err != nil {
+		return 0, nil, err
+	}
+
+	byID := make(map[uuid.UUID]*model.PushNotification, len(pushes))
+	for _, push := range pushes {
+		byID[push.ID] = push
+	}
+
+	var existing []*model.PushReceipt
+	if err := r.db.Select("push_notification_id", "event").
+		Where("push_notification_id IN ?", ids).
+		Find(&existing).Error; err != nil {
+		return 0, nil, err
+	}
+
+	type key struct {
+		push  uuid.UUID
+		event model.PushEvent
+	}
+	seen := make(map[key]bool, len(existing)+len(receipts))
+	for _, receipt := range existing {
+		seen[key{receipt.PushNotificationID, receipt.Event}] = true
+	}
+
+	accepted := make([]*model.PushReceipt, 0, len(receipts))
+	for _, receipt := range receipts {
+		push, ok := byID[receipt.PushNotificationID]
+		if !ok || seen[key{receipt.PushNotificationID, receipt.Event}] {
+			continue
+		}
+		seen[key{receipt.PushNotificationID, receipt.Event}] = true
+
+		receipt.AccountID, receipt.DeviceTokenID = accountID, push.DeviceTokenID
+		accepted = append(accepted, receipt)
+
+		if receipt.Event == model.PushOpened {
+			if id, ok := inboxEntry(push); ok {
+				opened = append(opened, id)
+			}
+		}
+	}
+	if len(accepted) == 0 {
+		return 0, nil, nil
+	}
+
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "push_notification_id"}, {Name: "event"}},
+		DoNothing: true,
+	}).Create(&accepted)
+	if result.Error != nil {
+		return 0, nil, result.Error
+	}
+
+	return result.RowsAffected, opened, nil
+}
+
+// inboxEntry returns the ID of the inbox entry a push was sent for.
+func inboxEntry(push *model.PushNotification) (uuid.UUID, bool) {
+	if push.NotificationID != nil {
+		return *push.NotificationID, true
+	}
+
+	id, err := uuid.Parse(push.Data[model.PushDataNotificationID])
+	return id, err == nil
+}
+
+func (r *pushReceiptRepository) TemplateStats(template string) (*model.PushStats, error) {
+	return pushStats(r.db, "template = ?", template)
+}
+
+// pushStats aggregates the pushes to devices matching the given condition and the receipts reported for them. Pushes
+// fanned out to the devices of an account are not counted themselves, their pushes per device are. Pushes broadcast to
+// a topic or condition are left out as well: they are not tied to an account, so no receipts can be recorded for them
+// and counting them as sent would only lower the open rate.
+func pushStats(db *gorm.DB, query string, args ...interface{}) (*model.PushStats, error) {
+	var stats model.PushStats
+	if err := db.Model(&model.PushNotification{}).
+		Select(`COUNT(*) FILTER (WHERE status = ?) AS pending,
+			COUNT(*) FILTER (WHERE status = ?) AS sent,
+			COUNT(*) FILTER (WHERE status = ?) AS failed,
+			COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM push_receipts WHERE push_receipts.push_notification_id = push_notifications.id)) AS delivered,
+			COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM push_receipts WHERE push_receipts.push_notification_id = push_notifications.id AND push_receipts.event = ?)) AS opened,
+			COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM push_receipts WHERE push_receipts.push_notification_id = push_notifications.id AND push_receipts.event = ?)) AS dismissed`,
+			model.Pending, model.Success, model.Failure, model.PushOpened, model.PushDismissed).
+		Where(query, args...).
+		Where("device_token_id IS NOT NULL").
+		Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	if stats.Sent > 0 {
+		stats.OpenRate = float64(stats.Opened) / float64(stats.Sent)
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type (
+	// RetentionRepository defines methods for purging notifications and push notifications past their retention. Every
+	// purge deletes one batch in a short transaction, locking only the rows of the batch and skipping rows locked by
+	// others, so the tables stay available while they are purged.
+	RetentionRepository interface {
+		// PurgeNotifications deletes up to limit notifications that were read or soft deleted before the cutoff, handing
+		// them to the archive first when one is given. It returns the number of notifications deleted.
+		PurgeNotifications(cutoff time.Time, limit int, archive Archive) (int64, error)
+
+		// PurgePushNotifications deletes up to limit delivered or failed push notifications created before the cutoff,
+		// together with their receipts, handing the pushes to the archive first when one is given. Pending pushes are
+		// kept. It returns the number of push notifications deleted.
+		PurgePushNotifications(cutoff time.Time, limit int, archive Archive) (int64, error)
+	}
+
+	// retentionRepository implements RetentionRepository interface.
+	retentionRepository struct{ *Repository }
+
+	// Archive keeps the rows deleted by a purge. Store runs inside the transaction of the purge, which is rolled back if
+	// it fails, so no row is deleted without being archived.
+	Archive interface {
+		Store(tx *gorm.DB, records []*model.PurgedRecord) error
+	}
+
+	// tableArchive keeps purged rows in the PurgedRecord table.
+	tableArchive struct{}
+
+	// fileArchive appends purged rows to a JSONL file per table and day below a directory.
+	fileArchive struct{ dir string }
+)
+
+// NewRetentionRepository creates a new instance of RetentionRepository with the provided Repository parameter.
+func NewRetentionRepository(r *Repository) RetentionRepository {
+	return &retentionRepository{r}
+}
+
+// NewTableArchive returns an Archive keeping purged rows in the PurgedRecord table.
+func NewTableArchive() Archive {
+	return tableArchive{}
+}
+
+// NewFileArchive returns an Archive appending purged rows to files named after their table and the day of the purge,
+// e.g. "notifications-2006-01-02.jsonl", below the given directory.
+func NewFileArchive(dir string) Archive {
+	return fileArchive{dir: dir}
+}
+
+func (r *retentionRepository) PurgeNotifications(cutoff time.Time, limit int, archive Archive) (int64, error) {
+	return purge(r.db, archive, "notifications", limit, func(db *gorm.DB) *gorm.DB {
+		return db.Where("(read = ? AND read_at < ?) OR deleted_at < ?", true, cutoff, cutoff)
+	}, func(notification *model.Notification) uuid.UUID {
+		return notification.ID
+	}, nil)
+}
+
+func (r *retentionRepository) PurgePushNotifications(cutoff time.Time, limit int, archive Archive) (int64, error) {
+	return purge(r.db, archive, "push_notifications", limit, func(db *gorm.DB) *gorm.DB {
+		return db.Where("status <> ? AND created_at < ?", model.Pending, cutoff)
+	}, func(notification *model.PushNotification) uuid.UUID {
+		return notification.ID
+	}, func(tx *gorm.DB, ids []uuid.UUID) error {
+		return tx.Where("push_notification_id IN ?", ids).Delete(&model.PushReceipt{}).Error
+	})
+}
+
+func (tableArchive) Store(tx *gorm.DB, records []*model.PurgedRecord) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&records).Error
+}
+
+func (a fileArchive) Store(_ *gorm.DB, records []*model.PurgedRecord) error {
+	if err := os.MkdirAll(a.dir, 0o750); err != nil {
+		return err
+	}
+
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	name := fmt.Sprintf("%s-%s.jsonl", records[0].Source, records[0].PurgedAt.Format(time.DateOnly))
+	file, err := os.OpenFile(filepath.Join(a.dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(lines.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// purge deletes up to limit rows of T matching the scope from the given table in a single transaction, including soft
+// deleted ones, and hands them to the archive before the deletion is committed. The dependents of the deleted rows are
+// removed by the given function in the same transaction, if any.
+func purge[T any](db *gorm.DB, archive Archive, source string, limit int, scope func(db *gorm.DB) *gorm.DB, id func(*T) uuid.UUID, dependents func(tx *gorm.DB, ids []uuid.UUID) error) (int64, error) {
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		batch := tx.Unscoped().Model(new(T)).
+			Scopes(scope).
+			Select("id").
+			Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
+		var rows []*T
+		result := tx.Unscoped().Clauses(clause.Returning{}).Where("id IN (?)", batch).Delete(&rows)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+
+		if dependents != nil {
+			ids := make([]uuid.UUID, 0, len(rows))
+			for _, row := range rows {
+				ids = append(ids, id(row))
+			}
+			if err := dependents(tx, ids); err != nil {
+				return err
+			}
+		}
+
+		if archive == nil {
+			return nil
+		}
+
+		purgedAt := time.Now()
+		records := make([]*model.PurgedRecord, 0, len(rows))
+		for _, row := range rows {
+			data, err := json.Marshal(row)
+			if err != nil {
+				return err
+			}
+			records = append(records, &model.PurgedRecord{Source: source, ID: id(row), Data: data, PurgedAt: purgedAt})
+		}
+
+		return archive.Store(tx, records)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+type (
+	// TopicRepository defines methods for managing the push topic subscriptions of accounts.
+	TopicRepository interface {
+		// Subscribe stores the subscription of an account to a topic. Existing subscriptions are kept as they are.
+		Subscribe(accountID uuid.UUID, topic string) error
+
+		// Unsubscribe removes the subscription of an account to a topic, returning if it was found.
+		Unsubscribe(accountID uuid.UUID, topic string) (founded bool, err error)
+
+		// GetList retrieves the topic subscriptions of an account.
+		GetList(accountID uuid.UUID) ([]*model.TopicSubscription, error)
+	}
+
+	// topicRepository implements TopicRepository interface.
+	topicRepository struct{ *Repository }
+)
+
+// NewTopicRepository creates a new instance of TopicRepository with the provided Repository parameter.
+func NewTopicRepository(r *Repository) TopicRepository {
+	return &topicRepository{r}
+}
+
+func (r *topicRepository) Subscribe(accountID uuid.UUID, topic string) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.TopicSubscription{AccountID: accountID, Topic: topic}).Error
+}
+
+func (r *topicRepository) Unsubscribe(accountID uuid.UUID, topic string) (founded bool, err error) {
+	result := r.db.Where("account_id = ? AND topic = ?", accountID, topic).Delete(&model.TopicSubscription{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *topicRepository) GetList(accountID uuid.UUID) ([]*model.TopicSubscription, error) {
+	var subscriptions []*model.TopicSubscription
+	if err := r.db.Where("account_id = ?", accountID).Order("topic").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
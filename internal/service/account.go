@@ -1,17 +1,28 @@
 package service
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"github.com/arifai/zenith/config"
 	"github.com/arifai/zenith/internal/model"
 	"github.com/arifai/zenith/internal/repository"
 	"github.com/arifai/zenith/internal/types/request"
 	"github.com/arifai/zenith/internal/types/response"
+	"github.com/arifai/zenith/pkg/common"
 	"github.com/arifai/zenith/pkg/crypto"
 	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/imaging"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/arifai/zenith/pkg/storage"
+	"github.com/arifai/zenith/pkg/utils"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"image"
+	"io"
+	"net/url"
+	"path"
 	"strings"
 	"time"
 )
@@ -23,9 +34,12 @@ type (
 		Register(body *request.AccountCreateRequest) (*model.Account, error)
 
 		// Authorization authenticates a user by validating their email and password, returning access and refresh tokens.
+		// The push token in the request body is registered for the signing-in device and subscribed to the topics of the account.
 		Authorization(body *request.AccountAuthRequest) (*response.AccountAuthResponse, error)
 
 		// Unauthorization invalidates both the access and refresh tokens present in the request body by blacklisting them.
+		// The push token of the signed-out device is removed and unsubscribed from the topics of the account, other devices
+		// of the account keep receiving notifications.
 		Unauthorization(body *request.AccountUnauthRequest) error
 
 		// RefreshToken refreshes the access and refresh tokens for a given account ID if the provided refresh token is valid.
@@ -34,23 +48,74 @@ type (
 		// GetCurrent retrieves the current account details by the given account ID (uuid.UUID).
 		GetCurrent(id *uuid.UUID) (*model.Account, error)
 
-		// Update updates an existing account's details such as FullName and Email based on the provided id and request body.
+		// Update updates an existing account's details such as FullName based on the provided id and request body.
+		// A changed Email is not applied directly, instead a confirmation link is sent to the new address.
 		Update(id *uuid.UUID, body *request.AccountUpdateRequest) (*model.Account, error)
 
+		// UpdateAvatar validates, resizes and stores the uploaded avatar image of the account, along with its thumbnails.
+		// The image is re-encoded, which strips its EXIF metadata. Objects of the previous avatar are removed afterwards.
+		UpdateAvatar(id *uuid.UUID, file io.Reader, size int64) (*model.Account, error)
+
+		// ConfirmEmailChange applies the pending email change identified by the confirmation token in the request body.
+		ConfirmEmailChange(body *request.AccountConfirmEmailChangeRequest) (*model.Account, error)
+
 		// UpdatePassword updates the password of an account identified by the given UUID. It takes the new password and the old password for validation. Returns an error if the operation fails.
 		UpdatePassword(id *uuid.UUID, body *request.AccountUpdatePasswordRequest) error
+
+		// Search retrieves a page of accounts for internal services, matching the search term against the full name and
+		// the email. The list can be filtered and sorted by full_name, email, active and created_at.
+		Search(paging *common.Pagination) (*common.EntriesModel[*model.Account], error)
 	}
 
 	// accountService handles account-related operations and interacts with the account repository.
 	accountService struct {
 		*Service
-		accountRepo repository.AccountRepository
+		accountRepo     repository.AccountRepository
+		deviceTokenRepo repository.DeviceTokenRepository
+		topicRepo       repository.TopicRepository
+		topics          push.TopicManager
+		mailer          utils.Mailer
+		storage         storage.Storage
 	}
 )
 
-// NewAccountService initializes and returns an AccountService instance with the provided Service and AccountRepository.
-func NewAccountService(service *Service, accountRepo repository.AccountRepository) AccountService {
-	return &accountService{Service: service, accountRepo: accountRepo}
+const (
+	emailChangeTTL                   = time.Hour * 24
+	emailChangeConfirmationTemplate  = "templates/mail/email_change_confirmation.html"
+	emailChangeNoticeTemplate        = "templates/mail/email_change_notice.html"
+	emailChangeConfirmationPath      = "/api/v1/auth/account/email/confirm"
+	emailChangeConfirmationSubject   = "Confirm your new email address"
+	emailChangeNoticeSubject         = "Your email address is about to change"
+	emailChangeConfirmationTokenSize = 32
+	avatarVariant                    = "avatar"
+	avatarMaxDimension               = 1024
+	avatarObjectPrefix               = "avatars"
+)
+
+// avatarThumbnailSizes maps the name of each avatar thumbnail to its square size in pixels.
+var avatarThumbnailSizes = map[string]int{"small": 64, "medium": 256}
+
+// accountFields lists the fields account searches can be filtered and sorted by.
+var accountFields = common.Fields{
+	"full_name":  {Column: "full_name", Type: common.StringField},
+	"email":      {Column: "email", Type: common.StringField},
+	"active":     {Column: "active", Type: common.BoolField},
+	"created_at": {Column: "created_at", Type: common.TimeField},
+}
+
+// NewAccountService initializes and returns an AccountService instance with the provided Service, AccountRepository,
+// DeviceTokenRepository, TopicRepository, TopicManager, Mailer and Storage.
+func NewAccountService(service *Service, accountRepo repository.AccountRepository, deviceTokenRepo repository.DeviceTokenRepository,
+	topicRepo repository.TopicRepository, topics push.TopicManager, mailer utils.Mailer, storage storage.Storage) AccountService {
+	return &accountService{
+		Service:         service,
+		accountRepo:     accountRepo,
+		deviceTokenRepo: deviceTokenRepo,
+		topicRepo:       topicRepo,
+		topics:          topics,
+		mailer:          mailer,
+		storage:         storage,
+	}
 }
 
 func (a *accountService) Register(body *request.AccountCreateRequest) (*model.Account, error) {
@@ -83,10 +148,6 @@ func (a *accountService) Authorization(body *request.AccountAuthRequest) (*respo
 		return nil, errormessage.ErrEmailAddressNotFound
 	}
 
-	if err = a.accountRepo.SetFCMToken(account.Email, body.FcmToken); err != nil {
-		return nil, err
-	}
-
 	if err := validateAccount(account, body.Password); err != nil {
 		return nil, err
 	}
@@ -97,6 +158,24 @@ func (a *accountService) Authorization(body *request.AccountAuthRequest) (*respo
 		return nil, errormessage.ErrInvalidDeviceIDInBody
 	}
 
+	platform := model.Platform(body.Platform)
+	if platform == "" {
+		platform = model.Android
+	}
+
+	provider := model.TokenProvider(body.PushProvider)
+	if provider == "" {
+		provider = model.ProviderFCM
+	}
+
+	deviceToken := &model.DeviceToken{AccountID: account.ID, DeviceID: parsedDeviceID, Platform: platform, Token: body.FcmToken, Provider: provider}
+	if err = a.deviceTokenRepo.Register(deviceToken); err != nil {
+		return nil, err
+	}
+	if deviceToken.IsFCM() {
+		go a.syncDeviceTopics(account.ID, []string{deviceToken.Token}, a.topics.Subscribe)
+	}
+
 	accessToken, err := generateToken(account.ID, parsedDeviceID, crypto.AccessToken, time.Hour*6)
 	if err != nil {
 		return nil, err
@@ -115,12 +194,12 @@ func (a *accountService) Authorization(body *request.AccountAuthRequest) (*respo
 
 func (a *accountService) Unauthorization(body *request.AccountUnauthRequest) error {
 	verifyAccessToken, err := crypto.VerifyToken(body.AccessToken, config.PublicKey)
-	if err != nil {
+	if err != nil || verifyAccessToken.TokenType != crypto.AccessToken {
 		return errormessage.ErrInvalidAccessTokenInBody
 	}
 
 	verifyRefreshToken, err := crypto.VerifyToken(body.RefreshToken, config.PublicKey)
-	if err != nil {
+	if err != nil || verifyRefreshToken.TokenType != crypto.RefreshToken {
 		return errormessage.ErrInvalidRefreshTokenInBody
 	}
 
@@ -132,16 +211,27 @@ func (a *accountService) Unauthorization(body *request.AccountUnauthRequest) err
 		return err
 	}
 
-	if err = a.accountRepo.UnsetFCMToken(verifyAccessToken.AccountID); err != nil {
+	deviceTokens, err := a.deviceTokenRepo.Remove(verifyAccessToken.AccountID, verifyAccessToken.DeviceID)
+	if err != nil {
 		return err
 	}
 
+	tokens := make([]string, 0, len(deviceTokens))
+	for _, deviceToken := range deviceTokens {
+		if deviceToken.IsFCM() {
+			tokens = append(tokens, deviceToken.Token)
+		}
+	}
+	if len(tokens) > 0 {
+		go a.syncDeviceTopics(verifyAccessToken.AccountID, tokens, a.topics.Unsubscribe)
+	}
+
 	return nil
 }
 
 func (a *accountService) RefreshToken(body *request.AccountRefreshTokenRequest) (*response.AccountAuthResponse, error) {
 	verifyRefreshToken, err := crypto.VerifyToken(body.RefreshToken, config.PublicKey)
-	if err != nil {
+	if err != nil || verifyRefreshToken.TokenType != crypto.RefreshToken {
 		return nil, errormessage.ErrInvalidRefreshTokenInBody
 	}
 
@@ -159,6 +249,10 @@ func (a *accountService) RefreshToken(body *request.AccountRefreshTokenRequest)
 		return nil, err
 	}
 
+	if err = a.deviceTokenRepo.Touch(verifyRefreshToken.AccountID, verifyRefreshToken.DeviceID, time.Now()); err != nil {
+		a.log.Error(errormessage.ErrFailedToTouchDeviceTokenText, zap.Error(err))
+	}
+
 	return &response.AccountAuthResponse{AccessToken: accessToken, RefreshToken: refreshToken}, nil
 }
 
@@ -177,16 +271,100 @@ func (a *accountService) Update(id *uuid.UUID, body *request.AccountUpdateReques
 		return nil, errormessage.ErrAccountNotFound
 	}
 
+	newEmail := strings.ToLower(body.Email)
+	emailChanged := newEmail != account.Email
+	if emailChanged {
+		if err := a.ensureEmailAvailable(newEmail); err != nil {
+			return nil, err
+		}
+	}
+
 	account.FullName = body.FullName
-	account.Email = strings.ToLower(body.Email)
 
 	if err := a.accountRepo.Update(account); err != nil {
 		return nil, err
 	}
 
+	if emailChanged {
+		if err := a.requestEmailChange(account, newEmail); err != nil {
+			return nil, err
+		}
+		account.PendingEmail = newEmail
+	}
+
 	return account, nil
 }
 
+func (a *accountService) UpdateAvatar(id *uuid.UUID, file io.Reader, size int64) (*model.Account, error) {
+	if size > a.config.AvatarMaxSize {
+		return nil, errormessage.ErrAvatarTooLarge
+	}
+
+	account, err := a.accountRepo.FindByID(id)
+	if err != nil {
+		return nil, errormessage.ErrAccountNotFound
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, a.config.AvatarMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > a.config.AvatarMaxSize {
+		return nil, errormessage.ErrAvatarTooLarge
+	}
+
+	img, contentType, err := imaging.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	variants := map[string]image.Image{avatarVariant: imaging.Fit(img, avatarMaxDimension)}
+	for name, thumbnailSize := range avatarThumbnailSizes {
+		variants[name] = imaging.Thumbnail(img, thumbnailSize)
+	}
+
+	urls, objects, err := a.storeAvatarVariants(account.ID, variants, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	previousObjects := account.AvatarObjects
+	account.Avatar = urls[avatarVariant]
+	delete(urls, avatarVariant)
+	account.AvatarThumbnails = urls
+	account.AvatarObjects = objects
+
+	if err := a.accountRepo.Update(account); err != nil {
+		a.deleteObjects(objects)
+		return nil, err
+	}
+
+	a.deleteObjects(previousObjects)
+
+	return account, nil
+}
+
+func (a *accountService) ConfirmEmailChange(body *request.AccountConfirmEmailChangeRequest) (*model.Account, error) {
+	change, err := a.accountRepo.FindEmailChangeByTokenHash(crypto.HashOpaqueToken(body.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errormessage.ErrInvalidEmailChangeToken
+		}
+		return nil, err
+	}
+
+	if time.Now().After(change.ExpiresAt) {
+		return nil, errormessage.ErrInvalidEmailChangeToken
+	}
+
+	if err := a.ensureEmailAvailable(change.NewEmail); err != nil {
+		return nil, err
+	}
+
+	return a.accountRepo.ConfirmEmailChange(change)
+}
+
 func (a *accountService) UpdatePassword(id *uuid.UUID, body *request.AccountUpdatePasswordRequest) error {
 	account, err := a.accountRepo.FindByID(id)
 	if err != nil {
@@ -211,6 +389,92 @@ func (a *accountService) UpdatePassword(id *uuid.UUID, body *request.AccountUpda
 	return nil
 }
 
+// ensureEmailAvailable returns errormessage.ErrEmailAlreadyExists if the given email is already used by an account.
+func (a *accountService) ensureEmailAvailable(email string) error {
+	founded, err := a.accountRepo.FindByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if founded != nil {
+		return errormessage.ErrEmailAlreadyExists
+	}
+
+	return nil
+}
+
+// requestEmailChange stores a pending email change for the account, sends a confirmation link to the new address
+// and a notice to the current one.
+func (a *accountService) requestEmailChange(account *model.Account, newEmail string) error {
+	token, tokenHash, err := crypto.GenerateOpaqueToken(emailChangeConfirmationTokenSize)
+	if err != nil {
+		return err
+	}
+
+	change := &model.AccountEmailChange{
+		AccountID: account.ID,
+		NewEmail:  newEmail,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	}
+	if err := a.accountRepo.CreateEmailChange(change); err != nil {
+		return err
+	}
+
+	confirmationURL := strings.TrimRight(a.config.AppURL, "/") + emailChangeConfirmationPath + "?token=" + url.QueryEscape(token)
+
+	a.mailer.QueueMailWithTemplate([]string{newEmail}, emailChangeConfirmationSubject, emailChangeConfirmationTemplate, map[string]any{
+		"FullName":        account.FullName,
+		"NewEmail":        newEmail,
+		"ConfirmationURL": confirmationURL,
+		"ExpiresAt":       change.ExpiresAt.Format(time.RFC1123),
+	})
+	a.mailer.QueueMailWithTemplate([]string{account.Email}, emailChangeNoticeSubject, emailChangeNoticeTemplate, map[string]any{
+		"FullName": account.FullName,
+		"OldEmail": account.Email,
+		"NewEmail": newEmail,
+	})
+
+	return nil
+}
+
+// storeAvatarVariants encodes and stores every avatar variant below a new prefix of the account.
+// It returns the public URL of each variant and the keys of all stored objects. On failure, already stored objects are removed.
+func (a *accountService) storeAvatarVariants(accountID uuid.UUID, variants map[string]image.Image, contentType string) (map[string]string, []string, error) {
+	prefix := path.Join(avatarObjectPrefix, accountID.String(), uuid.NewString())
+	urls := make(map[string]string, len(variants))
+	objects := make([]string, 0, len(variants))
+
+	for name, variant := range variants {
+		encoded, encodedType, extension, err := imaging.Encode(variant, contentType)
+		if err != nil {
+			a.deleteObjects(objects)
+			return nil, nil, err
+		}
+
+		key := path.Join(prefix, name+extension)
+		objectURL, err := a.storage.Put(context.Background(), key, bytes.NewReader(encoded), encodedType)
+		if err != nil {
+			a.deleteObjects(objects)
+			return nil, nil, err
+		}
+
+		urls[name] = objectURL
+		objects = append(objects, key)
+	}
+
+	return urls, objects, nil
+}
+
+// deleteObjects removes the given objects from the storage, logging failures instead of returning them.
+func (a *accountService) deleteObjects(keys []string) {
+	for _, key := range keys {
+		if err := a.storage.Delete(context.Background(), key); err != nil {
+			a.log.Error(errormessage.ErrFailedToDeleteObjectText, zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
 // generatePasswordHash generates a secure password hash using the Argon2ID algorithm with the provided password and salt.
 // Returns the password hash as a string or an error if the hashing process fails.
 func generatePasswordHash(password, salt string) (string, error) {
@@ -259,3 +523,36 @@ func generateToken(accountID, deviceID uuid.UUID, tokenType string, duration tim
 
 	return token, nil
 }
+
+// syncDeviceTopics applies a topic management operation for every topic of the account to the given push tokens.
+// Failures are only logged, the topics of a device are synchronized again on its next sign-in.
+func (a *accountService) syncDeviceTopics(accountID uuid.UUID, tokens []string, operation func(context.Context, string, []string) error) {
+	subscriptions, err := a.topicRepo.GetList(accountID)
+	if err != nil {
+		a.log.Error(errormessage.ErrFailedToSyncTopicsText, zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), topicSyncTimeout)
+	defer cancel()
+
+	for _, subscription := range subscriptions {
+		if err := operation(ctx, subscription.Topic, tokens); err != nil {
+			a.log.Error(errormessage.ErrFailedToSyncTopicsText, zap.String("topic", subscription.Topic), zap.Error(err))
+		}
+	}
+}
+
+func (s *accountService) Search(paging *common.Pagination) (*common.EntriesModel[*model.Account], error) {
+	query, err := paging.ParseQuery(accountFields, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, count, err := s.accountRepo.Search(paging, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewEntries(accounts, count, paging.GetPage(count), paging.GetTotalPages(count)), nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/internal/types/response"
+	"github.com/arifai/zenith/pkg/crypto"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+type (
+	// APIKeyService provides methods for managing the API keys used by services to call the API.
+	APIKeyService interface {
+		// Create generates a new API key owned by the given account. The plaintext key is only returned once.
+		Create(accountID *uuid.UUID, body *request.APIKeyCreateRequest) (*response.APIKeyCreateResponse, error)
+
+		// GetList retrieves all API keys owned by the given account.
+		GetList(accountID *uuid.UUID) ([]*model.APIKey, error)
+
+		// Revoke revokes an API key owned by the given account, returning if it was found and any error encountered.
+		Revoke(accountID *uuid.UUID, id string) (founded bool, err error)
+	}
+
+	// apiKeyService struct implements the APIKeyService interface.
+	apiKeyService struct {
+		*Service
+		apiKeyRepo repository.APIKeyRepository
+	}
+)
+
+// NewAPIKeyService creates a new instance of APIKeyService with the provided service and APIKeyRepository.
+func NewAPIKeyService(service *Service, apiKeyRepo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{Service: service, apiKeyRepo: apiKeyRepo}
+}
+
+func (s *apiKeyService) Create(accountID *uuid.UUID, body *request.APIKeyCreateRequest) (*response.APIKeyCreateResponse, error) {
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(time.Now()) {
+		return nil, errormessage.ErrExpiryInThePast
+	}
+
+	key, prefix, hash, err := crypto.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &model.APIKey{
+		OwnerType: model.AccountOwner,
+		OwnerID:   *accountID,
+		Name:      body.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    body.Scopes,
+		ExpiresAt: body.ExpiresAt,
+	}
+
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return nil, err
+	}
+
+	return &response.APIKeyCreateResponse{APIKey: apiKey, Key: key}, nil
+}
+
+func (s *apiKeyService) GetList(accountID *uuid.UUID) ([]*model.APIKey, error) {
+	return s.apiKeyRepo.GetList(model.AccountOwner, *accountID)
+}
+
+func (s *apiKeyService) Revoke(accountID *uuid.UUID, id string) (founded bool, err error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", id), zap.Error(err))
+		return false, nil
+	}
+
+	return s.apiKeyRepo.Revoke(model.AccountOwner, *accountID, parsedID)
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/internal/types/response"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+type (
+	// CampaignService provides methods for scheduling push campaigns and following their delivery.
+	CampaignService interface {
+		// Create schedules a campaign. Its audience filters and template are checked up front, and a first occurrence
+		// in the past runs right away.
+		Create(body *request.CampaignCreateRequest) (*model.Campaign, error)
+
+		// GetList retrieves a page of campaigns, matching the search term against the name. The list can be filtered
+		// and sorted by name, status, category, next_run_at and created_at.
+		GetList(paging *common.Pagination) (*common.EntriesModel[*model.Campaign], error)
+
+		// Get retrieves a campaign by its ID together with the delivery stats of its pushes.
+		Get(id string) (*response.CampaignResponse, error)
+
+		// Pause stops a scheduled campaign from running until it is resumed. Pushes already enqueued are still sent.
+		Pause(id string) (*model.Campaign, error)
+
+		// Resume schedules a paused campaign again, skipping the occurrences it missed while paused.
+		Resume(id string) (*model.Campaign, error)
+
+		// Cancel stops a scheduled or paused campaign for good and fails its pushes that are still pending.
+		Cancel(id string) (*model.Campaign, error)
+	}
+
+	// campaignService struct implements the CampaignService interface.
+	campaignService struct {
+		*Service
+		campaignRepo repository.CampaignRepository
+		templateRepo repository.NotificationTemplateRepository
+	}
+)
+
+// campaignFields lists the fields campaign lists can be filtered and sorted by.
+var campaignFields = common.Fields{
+	"name":        {Column: "name", Type: common.StringField},
+	"status":      {Column: "status", Type: common.StringField},
+	"category":    {Column: "category", Type: common.StringField},
+	"next_run_at": {Column: "next_run_at", Type: common.TimeField, Nullable: true},
+	"created_at":  {Column: "created_at", Type: common.TimeField},
+}
+
+// wallClockLayout is the layout of the wall clock times of campaign requests.
+const wallClockLayout = "2006-01-02T15:04"
+
+// NewCampaignService creates a new instance of CampaignService with the provided service, CampaignRepository and
+// NotificationTemplateRepository.
+func NewCampaignService(service *Service, campaignRepo repository.CampaignRepository, templateRepo repository.NotificationTemplateRepository) CampaignService {
+	return &campaignService{Service: service, campaignRepo: campaignRepo, templateRepo: templateRepo}
+}
+
+func (s *campaignService) Create(body *request.CampaignCreateRequest) (*model.Campaign, error) {
+	if len(body.AccountIDs) == 0 && len(body.AudienceFilters) == 0 {
+		return nil, errormessage.ErrEmptyAudience
+	}
+
+	if _, err := (common.Pagination{Filters: body.AudienceFilters}).ParseQuery(accountFields, "created_at"); err != nil {
+		return nil, err
+	}
+
+	if body.Options != nil {
+		if err := body.Options.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if body.Template != "" {
+		if _, err := s.templateRepo.Find(body.Template, []string{s.config.DefaultLocale}); err != nil {
+			return nil, err
+		}
+	}
+
+	startsAt, err := time.Parse(wallClockLayout, body.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign := &model.Campaign{
+		Name:            body.Name,
+		Status:          model.CampaignScheduled,
+		Category:        model.NotificationCategory(body.Category),
+		Template:        body.Template,
+		Variables:       body.Variables,
+		Title:           body.Title,
+		Message:         body.Message,
+		Image:           body.Image,
+		Data:            body.Data,
+		Options:         body.Options,
+		AudienceFilters: body.AudienceFilters,
+		StartsAt:        startsAt,
+		Timezone:        body.Timezone,
+		LocalTime:       body.LocalTime,
+		Occurrence:      startsAt,
+	}
+	if campaign.Category == "" {
+		campaign.Category = model.CategoryMarketing
+	}
+
+	for _, id := range body.AccountIDs {
+		accountID, err := uuid.Parse(id)
+		if err != nil {
+			s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", id), zap.Error(err))
+			continue
+		}
+		campaign.AccountIDs = append(campaign.AccountIDs, accountID)
+	}
+
+	if body.Recurrence != nil {
+		campaign.Recurrence = &model.CampaignRecurrence{
+			Frequency: model.CampaignFrequency(body.Recurrence.Frequency),
+			Interval:  max(1, body.Recurrence.Interval),
+			Weekdays:  body.Recurrence.Weekdays,
+		}
+
+		if body.Recurrence.Until != "" {
+			until, err := time.Parse(wallClockLayout, body.Recurrence.Until)
+			if err != nil {
+				return nil, err
+			}
+			if until.Before(startsAt) {
+				return nil, errormessage.ErrCampaignEnded
+			}
+			campaign.Recurrence.Until = &until
+		}
+	}
+
+	if !campaign.Reschedule(time.Now()) {
+		return nil, errormessage.ErrCampaignEnded
+	}
+
+	if err := s.campaignRepo.Create(campaign); err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (s *campaignService) GetList(paging *common.Pagination) (*common.EntriesModel[*model.Campaign], error) {
+	query, err := paging.ParseQuery(campaignFields, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	campaigns, count, err := s.campaignRepo.GetList(paging, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewEntries(campaigns, count, paging.GetPage(count), paging.GetTotalPages(count)), nil
+}
+
+func (s *campaignService) Get(id string) (*response.CampaignResponse, error) {
+	campaignID, err := s.parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign, err := s.campaignRepo.FindByID(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.campaignRepo.Stats(campaign)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.CampaignResponse{Campaign: campaign, Stats: stats}, nil
+}
+
+func (s *campaignService) Pause(id string) (*model.Campaign, error) {
+	return s.transition(id, []model.CampaignStatus{model.CampaignScheduled}, func(campaign *model.Campaign) {
+		campaign.Status, campaign.NextRunAt = model.CampaignPaused, nil
+	})
+}
+
+func (s *campaignService) Resume(id string) (*model.Campaign, error) {
+	return s.transition(id, []model.CampaignStatus{model.CampaignPaused}, func(campaign *model.Campaign) {
+		campaign.Status = model.CampaignScheduled
+		if !campaign.Reschedule(time.Now()) {
+			campaign.Status, campaign.NextRunAt = model.CampaignCompleted, nil
+		}
+	})
+}
+
+func (s *campaignService) Cancel(id string) (*model.Campaign, error) {
+	return s.transition(id, []model.CampaignStatus{model.CampaignScheduled, model.CampaignPaused}, func(campaign *model.Campaign) {
+		campaign.Status, campaign.NextRunAt = model.CampaignCanceled, nil
+	})
+}
+
+// transition applies update to the campaign with the given ID if its status is one of from.
+func (s *campaignService) transition(id string, from []model.CampaignStatus, update func(campaign *model.Campaign)) (*model.Campaign, error) {
+	campaignID, err := s.parseID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.campaignRepo.Transition(campaignID, from, update)
+}
+
+// parseID parses the ID of a campaign, an invalid ID belongs to no campaign.
+func (s *campaignService) parseID(id string) (uuid.UUID, error) {
+	campaignID, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", id), zap.Error(err))
+		return uuid.Nil, errormessage.ErrCampaignNotFound
+	}
+
+	return campaignID, nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"maps"
+	"time"
+)
+
+type (
+	// CampaignScheduler runs due campaigns in the background, enqueuing their pushes for the PushDispatcher. Every
+	// scheduler leases the campaign it runs, so any number of replicas can run a scheduler against the same database.
+	CampaignScheduler interface {
+		// Start launches the scheduling loop, which polls for due campaigns until Shutdown is called.
+		Start()
+
+		// Shutdown stops claiming new campaigns and waits until the running campaign is settled.
+		Shutdown()
+
+		// RunDue runs the campaigns that are due one after another, returning the number of campaigns run.
+		RunDue(ctx context.Context) (int, error)
+	}
+
+	// campaignScheduler struct implements the CampaignScheduler interface.
+	campaignScheduler struct {
+		*Service
+		campaignRepo   repository.CampaignRepository
+		preferenceRepo repository.NotificationPreferenceRepository
+		templateRepo   repository.NotificationTemplateRepository
+		cancel         context.CancelFunc
+		done           chan struct{}
+	}
+)
+
+// NewCampaignScheduler creates a new instance of CampaignScheduler with the provided service, CampaignRepository,
+// NotificationPreferenceRepository and NotificationTemplateRepository.
+func NewCampaignScheduler(service *Service, campaignRepo repository.CampaignRepository, preferenceRepo repository.NotificationPreferenceRepository, templateRepo repository.NotificationTemplateRepository) CampaignScheduler {
+	return &campaignScheduler{
+		Service:        service,
+		campaignRepo:   campaignRepo,
+		preferenceRepo: preferenceRepo,
+		templateRepo:   templateRepo,
+	}
+}
+
+func (c *campaignScheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go c.loop(ctx)
+}
+
+func (c *campaignScheduler) Shutdown() {
+	if c.cancel == nil {
+		return
+	}
+
+	c.cancel()
+	<-c.done
+}
+
+func (c *campaignScheduler) RunDue(ctx context.Context) (int, error) {
+	runs := 0
+	for ctx.Err() == nil {
+		campaign, err := c.campaignRepo.ClaimDue(time.Now(), c.config.CampaignLease)
+		if err != nil || campaign == nil {
+			return runs, err
+		}
+
+		if err := c.run(campaign); err != nil {
+			// The lease expires and the campaign is claimed again.
+			c.log.Error(errormessage.ErrFailedToRunCampaignText, zap.String("id", campaign.ID.String()), zap.Error(err))
+			continue
+		}
+		runs++
+	}
+
+	return runs, nil
+}
+
+// loop polls for due campaigns.
+func (c *campaignScheduler) loop(ctx context.Context) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.config.CampaignPoll)
+	defer ticker.Stop()
+
+	c.log.Info("campaign scheduler started")
+	for {
+		if _, err := c.RunDue(ctx); err != nil {
+			c.log.Error(errormessage.ErrFailedToRunCampaignText, zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			c.log.Info("campaign scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run enqueues the pushes of the current occurrence of a claimed campaign batch by batch and moves it to its next
+// occurrence. Every batch extends the lease, and a run interrupted by a crash resumes after the last batch enqueued. A
+// run stops as soon as its claim is lost. Every recipient is reached at the occurrence in its own timezone for local
+// time campaigns, pushes falling into its quiet hours are deferred, and recipients that disabled pushes of the
+// category are skipped.
+func (c *campaignScheduler) run(campaign *model.Campaign) error {
+	query, err := (common.Pagination{Filters: campaign.AudienceFilters}).ParseQuery(accountFields, "created_at")
+	if err != nil {
+		return err
+	}
+
+	renderer := &campaignRenderer{campaign: campaign, templateRepo: c.templateRepo, defaultLocale: c.config.DefaultLocale, rendered: map[string]*model.RenderedTemplate{}}
+
+	var recipients, skipped int64
+	after := uuid.Nil
+	if campaign.RunCursor != nil {
+		after = *campaign.RunCursor
+	}
+	for {
+		accountIDs, err := c.campaignRepo.Audience(campaign, query, after, c.config.CampaignBatch)
+		if err != nil {
+			return err
+		}
+		if len(accountIDs) == 0 {
+			break
+		}
+		after = accountIDs[len(accountIDs)-1]
+
+		pushes, batchSkipped, err := c.batch(campaign, renderer, accountIDs)
+		if err != nil {
+			return err
+		}
+
+		reached, err := c.campaignRepo.EnqueueBatch(campaign, pushes, batchSkipped, after, c.config.CampaignLease)
+		if errors.Is(err, errormessage.ErrCampaignClaimLost) {
+			c.log.Info("campaign run aborted", zap.String("id", campaign.ID.String()))
+			return nil
+		} else if err != nil {
+			return err
+		}
+		recipients += reached
+		skipped += batchSkipped + int64(len(pushes)) - reached
+	}
+
+	if err := c.campaignRepo.CompleteRun(campaign); errors.Is(err, errormessage.ErrCampaignClaimLost) {
+		c.log.Info("campaign run aborted", zap.String("id", campaign.ID.String()))
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	c.log.Info("campaign run", zap.String("id", campaign.ID.String()), zap.Int64("recipients", recipients), zap.Int64("skipped", skipped))
+	return nil
+}
+
+// batch builds the pushes of a campaign for a batch of its audience, returning them and the number of accounts skipped
+// because they disabled pushes of the category.
+func (c *campaignScheduler) batch(campaign *model.Campaign, renderer *campaignRenderer, accountIDs []uuid.UUID) ([]*model.PushNotification, int64, error) {
+	preferences, err := c.preferenceRepo.GetMany(accountIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	pushes := make([]*model.PushNotification, 0, len(accountIDs))
+	var skipped int64
+	for _, accountID := range accountIDs {
+		preference := preferences[accountID]
+		if !preference.Channels(campaign.Category).Push {
+			skipped++
+			continue
+		}
+
+		deliverAt := campaign.DeliverAt(campaign.Occurrence, preference.Timezone)
+		if until, quiet := preference.QuietUntil(campaign.Category, deliverAt); quiet {
+			deliverAt = until
+		}
+		if deliverAt.Before(now) {
+			deliverAt = now
+		}
+
+		push, err := renderer.push(accountID, preference.Locale)
+		if err != nil {
+			return nil, 0, err
+		}
+		push.NextAttemptAt = deliverAt.Local()
+		pushes = append(pushes, push)
+	}
+
+	return pushes, skipped, nil
+}
+
+// campaignRenderer builds the pushes of a campaign run, rendering its template once per locale.
+type campaignRenderer struct {
+	campaign      *model.Campaign
+	templateRepo  repository.NotificationTemplateRepository
+	defaultLocale string
+	rendered      map[string]*model.RenderedTemplate
+}
+
+// push returns the push of the campaign for an account, in the given locale if the campaign has a template.
+func (r *campaignRenderer) push(accountID uuid.UUID, locale string) (*model.PushNotification, error) {
+	title, message := r.campaign.Title, r.campaign.Message
+	if r.campaign.Template != "" {
+		rendered, ok := r.rendered[locale]
+		if !ok {
+			template, err := r.templateRepo.Find(r.campaign.Template, model.LocaleFallbacks(locale, r.defaultLocale))
+			if err != nil {
+				return nil, err
+			}
+
+			rendered, err = template.Render(r.campaign.Variables)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", errormessage.ErrRenderingTemplate, err)
+			}
+			r.rendered[locale] = rendered
+		}
+		title, message = rendered.Title, rendered.ShortDescription
+	}
+
+	data := maps.Clone(r.campaign.Data)
+	if data == nil {
+		data = make(map[string]string, 2)
+	}
+	data[model.PushDataCampaignID] = r.campaign.ID.String()
+	data[model.PushDataCategory] = string(r.campaign.Category)
+
+	return &model.PushNotification{
+		AccountID:  accountID,
+		Title:      title,
+		Message:    message,
+		Image:      r.campaign.Image,
+		Data:       data,
+		Options:    r.campaign.Options,
+		CampaignID: &r.campaign.ID,
+		Template:   r.campaign.Template,
+	}, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// schedulerCampaignRepo serves an audience of sorted account IDs and records the enqueued batches. The claim is lost
+// once lostAfter batches are enqueued.
+type schedulerCampaignRepo struct {
+	repository.CampaignRepository
+	audience  []uuid.UUID
+	batches   [][]*model.PushNotification
+	cursors   []uuid.UUID
+	lostAfter int
+	completed bool
+}
+
+// schedulerPreferenceRepo returns the default preferences for every account.
+type schedulerPreferenceRepo struct {
+	repository.NotificationPreferenceRepository
+}
+
+func (r *schedulerCampaignRepo) Audience(_ *model.Campaign, _ *common.ListQuery, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, id := range r.audience {
+		if id.String() > after.String() && len(ids) < limit {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (r *schedulerCampaignRepo) EnqueueBatch(_ *model.Campaign, pushes []*model.PushNotification, _ int64, cursor uuid.UUID, _ time.Duration) (int64, error) {
+	if r.lostAfter > 0 && len(r.batches) == r.lostAfter {
+		return 0, errormessage.ErrCampaignClaimLost
+	}
+
+	r.batches = append(r.batches, pushes)
+	r.cursors = append(r.cursors, cursor)
+	return int64(len(pushes)), nil
+}
+
+func (r *schedulerCampaignRepo) CompleteRun(campaign *model.Campaign) error {
+	r.completed = true
+	campaign.Advance()
+	return nil
+}
+
+func (r *schedulerPreferenceRepo) GetMany(accountIDs []uuid.UUID) (map[uuid.UUID]*model.NotificationPreference, error) {
+	preferences := make(map[uuid.UUID]*model.NotificationPreference, len(accountIDs))
+	for _, accountID := range accountIDs {
+		preferences[accountID] = &model.NotificationPreference{AccountID: accountID, Timezone: "UTC"}
+	}
+
+	return preferences, nil
+}
+
+func newSchedulerAudience(n int) []uuid.UUID {
+	audience := make([]uuid.UUID, n)
+	for i := range audience {
+		audience[i] = uuid.New()
+	}
+	slices.SortFunc(audience, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	return audience
+}
+
+func TestCampaignRunEnqueuesBatches(t *testing.T) {
+	audience := newSchedulerAudience(5)
+
+	tests := []struct {
+		name          string
+		cursor        *uuid.UUID
+		lostAfter     int
+		wantBatches   int
+		wantCompleted bool
+	}{
+		{name: "whole audience", wantBatches: 3, wantCompleted: true},
+		{name: "resumes after cursor", cursor: &audience[1], wantBatches: 2, wantCompleted: true},
+		{name: "stops when claim is lost", lostAfter: 1, wantBatches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaignRepo := &schedulerCampaignRepo{audience: audience, lostAfter: tt.lostAfter}
+			cfg := &config.Config{CampaignBatch: 2, CampaignLease: time.Minute}
+			scheduler := NewCampaignScheduler(New(cfg, logger.Logger{Logger: zap.NewNop()}), campaignRepo, &schedulerPreferenceRepo{}, nil).(*campaignScheduler)
+
+			now := time.Now().UTC()
+			campaign := &model.Campaign{ID: uuid.New(), Status: model.CampaignScheduled, Category: model.CategoryGeneral, Title: "Title", Message: "Message", Timezone: "UTC", StartsAt: now, Occurrence: now, RunCursor: tt.cursor}
+
+			if err := scheduler.run(campaign); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if len(campaignRepo.batches) != tt.wantBatches {
+				t.Errorf("enqueued %d batches, want %d", len(campaignRepo.batches), tt.wantBatches)
+			}
+			if campaignRepo.completed != tt.wantCompleted {
+				t.Errorf("completed = %v, want %v", campaignRepo.completed, tt.wantCompleted)
+			}
+			if tt.cursor != nil && len(campaignRepo.batches) > 0 && campaignRepo.batches[0][0].AccountID != audience[2] {
+				t.Errorf("first push for %s, want the account after the cursor %s", campaignRepo.batches[0][0].AccountID, audience[2])
+			}
+			if n := len(campaignRepo.cursors); n > 0 && tt.wantCompleted && campaignRepo.cursors[n-1] != audience[len(audience)-1] {
+				t.Errorf("last cursor = %s, want the last account of the audience", campaignRepo.cursors[n-1])
+			}
+		})
+	}
+}
@@ -1,12 +1,18 @@
 package service
 
 import (
+	"fmt"
 	"github.com/arifai/zenith/internal/model"
 	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/internal/types/response"
 	"github.com/arifai/zenith/pkg/common"
 	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"maps"
+	"time"
 )
 
 type (
@@ -14,45 +20,396 @@ type (
 	// GetList retrieves a list of notifications and their pagination metadata based on the given ID and pagination parameters.
 	// MarkAsRead marks a notification as read by its ID, indicating if the operation was successful and if the notification was found.
 	NotificationService interface {
-		// GetList retrieves a list of notifications and pagination details based on the given account ID and pagination parameters.
-		GetList(id *uuid.UUID, paging *common.Pagination) (*common.EntriesModel[*model.Notification], error)
+		// GetList retrieves a list of notifications and pagination details based on the given account ID and pagination
+		// parameters, listing the archived notifications instead of the inbox if archived is set. The list can be
+		// filtered and sorted by id, read, category, priority, created_at, read_at and expires_at.
+		GetList(id *uuid.UUID, paging *common.Pagination, archived bool) (*common.EntriesModel[*model.Notification], error)
 
-		// MarkAsRead marks a notification as read by its ID, returning if it was found and any error encountered.
-		MarkAsRead(id string) (founded bool, err error)
+		// MarkAsRead marks a notification of the given account as read by its ID, returning if it was found and any
+		// error encountered.
+		MarkAsRead(accountID *uuid.UUID, id string) (founded bool, err error)
+
+		// MarkAsUnread marks a notification of the given account as unread by its ID, returning if it was found and any
+		// error encountered.
+		MarkAsUnread(accountID *uuid.UUID, id string) (founded bool, err error)
+
+		// MarkAllAsRead marks every notification of the given account as read.
+		MarkAllAsRead(accountID *uuid.UUID) (*response.NotificationUpdatedResponse, error)
+
+		// BulkMark sets the read state of the notifications of the given account listed in the request. IDs of other
+		// accounts are ignored.
+		BulkMark(accountID *uuid.UUID, body *request.NotificationBulkMarkRequest) (*response.NotificationUpdatedResponse, error)
+
+		// Archive moves a notification of the given account out of the inbox, or back if archived is false, returning if
+		// it was found.
+		Archive(accountID *uuid.UUID, id string, archived bool) (founded bool, err error)
+
+		// Delete soft deletes a notification of the given account, returning if it was found.
+		Delete(accountID *uuid.UUID, id string) (founded bool, err error)
+
+		// CountUnread returns the number of unread notifications in the inbox of the given account.
+		CountUnread(accountID *uuid.UUID) (*response.NotificationUnreadCountResponse, error)
+
+		// Send adds a notification to the inbox of an account and enqueues a push for each of its active devices in the
+		// same transaction, skipping the channels the account disabled for the category. Pushes falling into the quiet
+		// hours of the account are deferred until they end. The pushes carry the ID of the inbox entry, so opening one can
+		// mark the entry as read, along with its category, priority, action URL, actions and expiry. Notifications of a
+		// category the account only receives by email are stored for its email digest instead of the inbox. Templated
+		// notifications are rendered in the locale of the account.
+		Send(body *request.NotificationSendRequest) (*response.NotificationSendResponse, error)
 	}
 
 	// notificationService struct implements the NotificationService interface, providing methods to manage notifications.
 	notificationService struct {
 		*Service
 		notificationRepo repository.NotificationRepository
+		preferenceRepo   repository.NotificationPreferenceRepository
+		templateRepo     repository.NotificationTemplateRepository
+		eventRepo        repository.NotificationEventRepository
 	}
 )
 
-// NewNotificationService creates a new instance of NotificationService with the provided service and NotificationRepository.
-func NewNotificationService(service *Service, notificationRepo repository.NotificationRepository) NotificationService {
+// notificationFields lists the fields notification lists can be filtered and sorted by.
+var notificationFields = common.Fields{
+	"id":         {Column: "id", Type: common.UUIDField},
+	"read":       {Column: "read", Type: common.BoolField},
+	"category":   {Column: "category", Type: common.StringField},
+	"priority":   {Column: "priority", Type: common.StringField},
+	"created_at": {Column: "created_at", Type: common.TimeField},
+	"read_at":    {Column: "read_at", Type: common.TimeField, Nullable: true},
+	"expires_at": {Column: "expires_at", Type: common.TimeField, Nullable: true},
+}
+
+// NewNotificationService creates a new instance of NotificationService with the provided service, NotificationRepository,
+// NotificationPreferenceRepository, NotificationTemplateRepository and NotificationEventRepository.
+func NewNotificationService(service *Service, notificationRepo repository.NotificationRepository, preferenceRepo repository.NotificationPreferenceRepository, templateRepo repository.NotificationTemplateRepository, eventRepo repository.NotificationEventRepository) NotificationService {
 	return &notificationService{
 		Service:          service,
 		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		templateRepo:     templateRepo,
+		eventRepo:        eventRepo,
 	}
 }
 
-func (s *notificationService) GetList(id *uuid.UUID, paging *common.Pagination) (*common.EntriesModel[*model.Notification], error) {
-	entries, count, err := s.notificationRepo.GetList(id, paging)
+func (s *notificationService) GetList(id *uuid.UUID, paging *common.Pagination, archived bool) (*common.EntriesModel[*model.Notification], error) {
+	cursors := common.NewCursorCodec(s.config.CursorSecret)
+	cursor, err := cursors.Decode(paging.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := paging.ParseQuery(notificationFields, "created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	entries, count, err := s.notificationRepo.GetList(id, paging, query, cursor, archived)
 	if err != nil {
 		return nil, err
 	}
 
-	page := paging.GetPage(count)
 	totalPages := paging.GetTotalPages(count)
+	if cursor != nil {
+		entries, prev, next := common.KeysetPage(entries, paging, cursor, notificationPosition)
+		return common.NewEntries(entries, count, 0, totalPages).WithCursors(cursors.Encode(prev), cursors.Encode(next)), nil
+	}
+
+	page := paging.GetPage(count)
+	prev, next := common.OffsetCursors(entries, paging, query, count, notificationPosition)
+
+	return common.NewEntries(entries, count, page, totalPages).WithCursors(cursors.Encode(prev), cursors.Encode(next)), nil
+}
+
+func (s *notificationService) MarkAsRead(accountID *uuid.UUID, id string) (founded bool, err error) {
+	return s.setRead(accountID, id, true)
+}
+
+func (s *notificationService) MarkAsUnread(accountID *uuid.UUID, id string) (founded bool, err error) {
+	return s.setRead(accountID, id, false)
+}
+
+func (s *notificationService) MarkAllAsRead(accountID *uuid.UUID) (*response.NotificationUpdatedResponse, error) {
+	readAt := time.Now()
+	updated, err := s.notificationRepo.MarkAllAsRead(*accountID, readAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated > 0 {
+		s.publish(s.eventRepo, model.EventNotificationRead, *accountID, &model.NotificationReadState{All: true, Read: true, ReadAt: &readAt})
+	}
+
+	return &response.NotificationUpdatedResponse{Updated: updated}, nil
+}
+
+func (s *notificationService) BulkMark(accountID *uuid.UUID, body *request.NotificationBulkMarkRequest) (*response.NotificationUpdatedResponse, error) {
+	ids := make([]uuid.UUID, 0, len(body.IDs))
+	for _, id := range body.IDs {
+		parsedID, err := uuid.Parse(id)
+		if err != nil {
+			s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", id), zap.Error(err))
+			continue
+		}
+		ids = append(ids, parsedID)
+	}
+
+	_, changed, err := s.notificationRepo.SetRead(*accountID, ids, *body.Read)
+	if err != nil {
+		return nil, err
+	}
+	s.publishReadState(s.eventRepo, *accountID, changed, *body.Read)
+
+	return &response.NotificationUpdatedResponse{Updated: int64(len(changed))}, nil
+}
+
+func (s *notificationService) Archive(accountID *uuid.UUID, id string, archived bool) (founded bool, err error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", id), zap.Error(err))
+		return false, nil
+	}
+
+	founded, err = s.notificationRepo.SetArchived(*accountID, parsedID, archived)
+	if err != nil || !founded {
+		return founded, err
+	}
+
+	s.publish(s.eventRepo, model.EventNotificationArchived, *accountID, &model.NotificationArchiveState{IDs: []uuid.UUID{parsedID}, Archived: archived})
+
+	return true, nil
+}
+
+func (s *notificationService) Delete(accountID *uuid.UUID, id string) (founded bool, err error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", id), zap.Error(err))
+		return false, nil
+	}
+
+	founded, err = s.notificationRepo.Delete(*accountID, parsedID)
+	if err != nil || !founded {
+		return founded, err
+	}
+
+	s.publish(s.eventRepo, model.EventNotificationDeleted, *accountID, &model.NotificationArchiveState{IDs: []uuid.UUID{parsedID}})
+
+	return true, nil
+}
+
+func (s *notificationService) CountUnread(accountID *uuid.UUID) (*response.NotificationUnreadCountResponse, error) {
+	count, err := s.notificationRepo.CountUnread(*accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.NotificationUnreadCountResponse{Count: count}, nil
+}
+
+func (s *notificationService) Send(body *request.NotificationSendRequest) (*response.NotificationSendResponse, error) {
+	accountID, err := uuid.Parse(body.AccountID)
+	if err != nil {
+		s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", body.AccountID), zap.Error(err))
+		return nil, errormessage.ErrAccountNotFound
+	}
+
+	if body.Options != nil {
+		if err := body.Options.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if body.ExpiresAt != nil && !body.ExpiresAt.After(now) {
+		return nil, errormessage.ErrNotificationExpired
+	}
+
+	preference, err := s.preferenceRepo.Get(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	notification := newNotification(body)
+	if body.Template != "" {
+		if err := s.render(notification, body, preference); err != nil {
+			return nil, err
+		}
+	}
+	channels := preference.Channels(notification.Category)
+
+	var push *model.PushNotification
+	var deferredUntil *time.Time
+	sendAt := now
+	if until, quiet := preference.QuietUntil(notification.Category, now); quiet {
+		sendAt = until
+	}
+
+	// A push that would only be sent after the notification expired is dropped.
+	if channels.Push && (notification.ExpiresAt == nil || notification.ExpiresAt.After(sendAt)) {
+		data := notification.PushData()
+		maps.Copy(data, body.Data)
+
+		push = &model.PushNotification{
+			Title:    notification.Title,
+			Message:  notification.ShortDescription,
+			Image:    body.Image,
+			Data:     data,
+			Options:  pushOptions(body.Options, notification, sendAt),
+			Template: body.Template,
+		}
+
+		if sendAt.After(now) {
+			push.NextAttemptAt = sendAt
+			deferredUntil = &sendAt
+		}
+	}
+
+	// Notifications of a category that is only emailed are kept out of the inbox, but stored for the email digest.
+	inbox := notification
+	if !channels.InApp {
+		inbox = nil
+		if channels.Email {
+			notification.EmailOnly = true
+		} else {
+			notification = nil
+		}
+	}
+
+	pushes, err := s.notificationRepo.Send(accountID, notification, push)
+	if err != nil {
+		return nil, err
+	}
+
+	if inbox != nil {
+		s.publish(s.eventRepo, model.EventNotificationCreated, accountID, inbox)
+	}
+
+	return &response.NotificationSendResponse{Notification: inbox, PushNotifications: pushes, DeferredUntil: deferredUntil}, nil
+}
 
-	return common.NewEntries(entries, count, page, totalPages), nil
+// render renders the template of a send request in the locale of the account, falling back to its base language and
+// the default locale, into the copy of the notification. The category of the template applies unless the request
+// names one.
+func (s *notificationService) render(notification *model.Notification, body *request.NotificationSendRequest, preference *model.NotificationPreference) error {
+	template, err := s.templateRepo.Find(body.Template, model.LocaleFallbacks(preference.Locale, s.config.DefaultLocale))
+	if err != nil {
+		return err
+	}
+
+	rendered, err := template.Render(body.Variables)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errormessage.ErrRenderingTemplate, err)
+	}
+
+	notification.Title = rendered.Title
+	notification.ShortDescription = rendered.ShortDescription
+	notification.Description = rendered.Description
+	if body.Category == "" {
+		notification.Category = template.Category
+	}
+
+	return nil
 }
 
-func (s *notificationService) MarkAsRead(id string) (founded bool, err error) {
+// newNotification builds the inbox entry of a send request, defaulting to the general category and normal priority.
+// The expiry is kept in local time like the other timestamps of the table.
+func newNotification(body *request.NotificationSendRequest) *model.Notification {
+	notification := &model.Notification{
+		Category:         model.NotificationCategory(body.Category),
+		Priority:         model.NotificationPriority(body.Priority),
+		Title:            body.Title,
+		Image:            body.Image,
+		ShortDescription: body.ShortDescription,
+		Description:      body.Description,
+		ActionURL:        body.ActionURL,
+	}
+
+	if notification.Category == "" {
+		notification.Category = model.CategoryGeneral
+	}
+	if notification.Priority == "" {
+		notification.Priority = model.PriorityNormal
+	}
+	if body.ExpiresAt != nil {
+		expiresAt := body.ExpiresAt.Local()
+		notification.ExpiresAt = &expiresAt
+	}
+	for _, action := range body.Actions {
+		notification.Actions = append(notification.Actions, model.NotificationAction{Label: action.Label, Payload: action.Payload})
+	}
+
+	return notification
+}
+
+// pushOptions returns the push options of a notification sent at the given time. Unless the options of the request
+// say otherwise, high priority notifications are pushed with high priority and expiring notifications are only kept by
+// the push service until they expire.
+func pushOptions(options *push.Options, notification *model.Notification, sendAt time.Time) *push.Options {
+	merged := push.Options{}
+	if options != nil {
+		merged = *options
+	}
+
+	if merged.Priority == "" && notification.Priority == model.PriorityHigh {
+		merged.Priority = push.PriorityHigh
+	}
+	if merged.TimeToLive == nil && notification.ExpiresAt != nil {
+		timeToLive := int(notification.ExpiresAt.Sub(sendAt).Seconds())
+		merged.TimeToLive = &timeToLive
+	}
+
+	if options == nil && merged == (push.Options{}) {
+		return nil
+	}
+
+	return &merged
+}
+
+// notificationPosition returns the keyset position of a notification.
+func notificationPosition(notification *model.Notification) (time.Time, uuid.UUID) {
+	return notification.CreatedAt, notification.ID
+}
+
+// setRead sets the read state of a single notification of an account.
+func (s *notificationService) setRead(accountID *uuid.UUID, id string, read bool) (founded bool, err error) {
 	parsedID, err := uuid.Parse(id)
 	if err != nil {
 		s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", id), zap.Error(err))
+		return false, nil
+	}
+
+	found, changed, err := s.notificationRepo.SetRead(*accountID, []uuid.UUID{parsedID}, read)
+	if err != nil {
+		return false, err
+	}
+	s.publishReadState(s.eventRepo, *accountID, changed, read)
+
+	return found > 0, nil
+}
+
+// publishReadState announces the notifications whose read state changed through the given event repository.
+func (s *Service) publishReadState(eventRepo repository.NotificationEventRepository, accountID uuid.UUID, changed []*model.Notification, read bool) {
+	if len(changed) == 0 {
 		return
 	}
-	return s.notificationRepo.MarkAsRead(parsedID)
+
+	state := &model.NotificationReadState{IDs: make([]uuid.UUID, 0, len(changed)), Read: read, ReadAt: changed[0].ReadAt}
+	for _, notification := range changed {
+		state.IDs = append(state.IDs, notification.ID)
+	}
+
+	s.publish(eventRepo, model.EventNotificationRead, accountID, state)
+}
+
+// publish announces a change of the notifications of an account to its open streams through the given event
+// repository. The change is already stored, so a failure is only logged and clients catch up when they fetch the list.
+func (s *Service) publish(eventRepo repository.NotificationEventRepository, eventType model.NotificationEventType, accountID uuid.UUID, data interface{}) {
+	event, err := model.NewNotificationEvent(eventType, accountID, data)
+	if err == nil {
+		err = eventRepo.Publish(event)
+	}
+
+	if err != nil {
+		s.log.Error(errormessage.ErrFailedToPublishEventText, zap.String("account_id", accountID.String()), zap.Error(err))
+	}
 }
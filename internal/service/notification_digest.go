@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+type (
+	// NotificationDigest mails every account with email enabled for any category a periodic digest of its unread
+	// notifications of those categories. Each digest collects the notifications created since the previous one, which
+	// is recorded before the mail is queued, so any number of replicas can run a digest against the same database.
+	NotificationDigest interface {
+		// Start launches the digest loop, which polls for accounts due for a digest until Shutdown is called.
+		Start()
+
+		// Shutdown stops the digest loop and waits until the current batch is mailed.
+		Shutdown()
+
+		// SendDue queues the digests of every account that is due, returning the number of digests queued. Accounts
+		// without unread notifications are only recorded.
+		SendDue(ctx context.Context) (int, error)
+	}
+
+	// notificationDigest struct implements the NotificationDigest interface.
+	notificationDigest struct {
+		*Service
+		notificationRepo repository.NotificationRepository
+		preferenceRepo   repository.NotificationPreferenceRepository
+		mailer           utils.Mailer
+		cancel           context.CancelFunc
+		done             chan struct{}
+	}
+
+	// digestEntry is a notification as listed in a digest email.
+	digestEntry struct {
+		Title            string
+		ShortDescription string
+		Category         model.NotificationCategory
+		ActionURL        string
+		CreatedAt        string
+	}
+)
+
+const (
+	notificationDigestTemplate = "templates/mail/notification_digest.html"
+	notificationDigestSubject  = "Your unread notifications"
+
+	// digestMaxEntries bounds the notifications listed in a digest, the remaining ones are only counted.
+	digestMaxEntries = 20
+
+	// digestTimeLayout is the layout of the creation time of the notifications listed in a digest.
+	digestTimeLayout = "Jan 2, 15:04 MST"
+)
+
+// NewNotificationDigest creates a new instance of NotificationDigest with the provided service, NotificationRepository,
+// NotificationPreferenceRepository and Mailer.
+func NewNotificationDigest(service *Service, notificationRepo repository.NotificationRepository, preferenceRepo repository.NotificationPreferenceRepository, mailer utils.Mailer) NotificationDigest {
+	return &notificationDigest{
+		Service:          service,
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		mailer:           mailer,
+	}
+}
+
+func (n *notificationDigest) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	n.done = make(chan struct{})
+
+	go n.loop(ctx)
+}
+
+func (n *notificationDigest) Shutdown() {
+	if n.cancel == nil {
+		return
+	}
+
+	n.cancel()
+	<-n.done
+}
+
+func (n *notificationDigest) SendDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	sent := 0
+	after := uuid.Nil
+	for ctx.Err() == nil {
+		recipients, err := n.preferenceRepo.DueDigests(now.Add(-n.config.DigestInterval), after, n.config.DigestBatch)
+		if err != nil || len(recipients) == 0 {
+			return sent, err
+		}
+		after = recipients[len(recipients)-1].AccountID
+
+		for _, recipient := range recipients {
+			queued, err := n.send(recipient, now)
+			if err != nil {
+				n.log.Error(errormessage.ErrFailedToSendDigestText, zap.String("account_id", recipient.AccountID.String()), zap.Error(err))
+				continue
+			}
+			if queued {
+				sent++
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+// loop polls for accounts due for a digest.
+func (n *notificationDigest) loop(ctx context.Context) {
+	defer close(n.done)
+
+	ticker := time.NewTicker(n.config.DigestPoll)
+	defer ticker.Stop()
+
+	n.log.Info("notification digest started")
+	for {
+		if _, err := n.SendDue(ctx); err != nil {
+			n.log.Error(errormessage.ErrFailedToSendDigestText, zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			n.log.Info("notification digest stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// send queues the digest of the unread notifications a recipient received since its last digest, or within the last
+// interval for its first one, and records it as sent at now. It reports false if there was nothing to mail or another
+// replica recorded a digest first.
+func (n *notificationDigest) send(recipient *model.DigestRecipient, now time.Time) (bool, error) {
+	since := now.Add(-n.config.DigestInterval)
+	if recipient.LastDigestAt != nil {
+		since = *recipient.LastDigestAt
+	}
+
+	notifications, count, err := n.notificationRepo.Unread(recipient.AccountID, recipient.EmailCategories(), since, now, digestMaxEntries)
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := n.preferenceRepo.ClaimDigest(recipient.AccountID, recipient.LastDigestAt, now)
+	if err != nil || !claimed || count == 0 {
+		return false, err
+	}
+
+	location, err := time.LoadLocation(recipient.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	entries := make([]digestEntry, 0, len(notifications))
+	for _, notification := range notifications {
+		entries = append(entries, digestEntry{
+			Title:            notification.Title,
+			ShortDescription: notification.ShortDescription,
+			Category:         notification.Category,
+			ActionURL:        notification.ActionURL,
+			CreatedAt:        notification.CreatedAt.In(location).Format(digestTimeLayout),
+		})
+	}
+
+	n.mailer.QueueMailWithTemplate([]string{recipient.Email}, notificationDigestSubject, notificationDigestTemplate, map[string]any{
+		"FullName":      recipient.FullName,
+		"Count":         count,
+		"Notifications": entries,
+		"More":          count - int64(len(entries)),
+	})
+
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/logger"
+	"github.com/arifai/zenith/pkg/mail"
+	"github.com/arifai/zenith/pkg/utils"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// digestPreferenceRepo serves a fixed list of recipients due for a digest and records the claimed digests.
+type digestPreferenceRepo struct {
+	repository.NotificationPreferenceRepository
+	recipients []*model.DigestRecipient
+	claimed    []uuid.UUID
+}
+
+// digestNotificationRepo serves the unread notifications of every account.
+type digestNotificationRepo struct {
+	repository.NotificationRepository
+	unread map[uuid.UUID][]*model.Notification
+}
+
+func (r *digestPreferenceRepo) DueDigests(_ time.Time, after uuid.UUID, _ int) ([]*model.DigestRecipient, error) {
+	if after != uuid.Nil {
+		return nil, nil
+	}
+
+	return r.recipients, nil
+}
+
+func (r *digestPreferenceRepo) ClaimDigest(accountID uuid.UUID, _ *time.Time, _ time.Time) (bool, error) {
+	r.claimed = append(r.claimed, accountID)
+	return true, nil
+}
+
+func (r *digestNotificationRepo) Unread(accountID uuid.UUID, _ []model.NotificationCategory, _, _ time.Time, limit int) ([]*model.Notification, int64, error) {
+	unread := r.unread[accountID]
+	return unread[:min(limit, len(unread))], int64(len(unread)), nil
+}
+
+func TestNotificationDigestMailsUnreadNotifications(t *testing.T) {
+	email := map[model.NotificationCategory]model.ChannelPreference{model.CategoryGeneral: {Email: true}}
+	john := &model.DigestRecipient{
+		NotificationPreference: model.NotificationPreference{AccountID: uuid.New(), Timezone: "UTC", Categories: email},
+		Email:                  "john@example.com",
+		FullName:               "John Doe",
+	}
+	jane := &model.DigestRecipient{
+		NotificationPreference: model.NotificationPreference{AccountID: uuid.New(), Timezone: "UTC", Categories: email},
+		Email:                  "jane@example.com",
+		FullName:               "Jane Doe",
+	}
+
+	preferenceRepo := &digestPreferenceRepo{recipients: []*model.DigestRecipient{john, jane}}
+	notificationRepo := &digestNotificationRepo{unread: map[uuid.UUID][]*model.Notification{
+		john.AccountID: {
+			{Title: "Your order shipped", ShortDescription: "Arrives on Monday", Category: model.CategoryGeneral, ActionURL: "https://example.com/orders/1", CreatedAt: time.Now()},
+			{Title: "New follower", ShortDescription: "Jane follows you", Category: model.CategoryGeneral, CreatedAt: time.Now()},
+		},
+	}}
+
+	cfg := &config.Config{MailFrom: "noreply@example.com", DigestInterval: 24 * time.Hour, DigestBatch: 100}
+	outbox := mail.NewMemory()
+	// The mail templates are resolved relative to the root of the repository.
+	mailer := utils.NewMailer(*cfg, outbox, os.DirFS("../.."), 10, 1)
+	digest := NewNotificationDigest(New(cfg, logger.Logger{Logger: zap.NewNop()}), notificationRepo, preferenceRepo, mailer)
+
+	sent, err := digest.SendDue(context.Background())
+	mailer.Shutdown()
+	if err != nil {
+		t.Fatalf("SendDue() error = %v", err)
+	}
+	if sent != 1 {
+		t.Errorf("SendDue() = %d, want 1", sent)
+	}
+	if len(preferenceRepo.claimed) != 2 {
+		t.Errorf("claimed %d digests, want 2 since accounts without unread notifications are recorded too", len(preferenceRepo.claimed))
+	}
+
+	messages := outbox.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("outbox holds %d messages, want 1", len(messages))
+	}
+
+	message := messages[0]
+	if message.From != "noreply@example.com" || len(message.To) != 1 || message.To[0] != "john@example.com" {
+		t.Errorf("envelope = %s to %v, want noreply@example.com to john@example.com", message.From, message.To)
+	}
+
+	data := string(message.Data)
+	for _, want := range []string{
+		"Subject: " + notificationDigestSubject,
+		"To: john@example.com",
+		"multipart/alternative",
+		"Hi John Doe,",
+		"Your order shipped",
+		"New follower",
+		"https://example.com/orders/1",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, data)
+		}
+	}
+}
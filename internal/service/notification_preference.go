@@ -0,0 +1,72 @@
+package service
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/google/uuid"
+)
+
+type (
+	// NotificationPreferenceService provides methods for managing the notification preferences of accounts.
+	NotificationPreferenceService interface {
+		// Get retrieves the notification preferences of the given account, listing the channels of every category.
+		Get(accountID *uuid.UUID) (*model.NotificationPreference, error)
+
+		// Update replaces the notification preferences of the given account.
+		Update(accountID *uuid.UUID, body *request.NotificationPreferenceUpdateRequest) (*model.NotificationPreference, error)
+	}
+
+	// notificationPreferenceService struct implements the NotificationPreferenceService interface.
+	notificationPreferenceService struct {
+		*Service
+		preferenceRepo repository.NotificationPreferenceRepository
+	}
+)
+
+// NewNotificationPreferenceService creates a new instance of NotificationPreferenceService with the provided service and
+// NotificationPreferenceRepository.
+func NewNotificationPreferenceService(service *Service, preferenceRepo repository.NotificationPreferenceRepository) NotificationPreferenceService {
+	return &notificationPreferenceService{Service: service, preferenceRepo: preferenceRepo}
+}
+
+func (s *notificationPreferenceService) Get(accountID *uuid.UUID) (*model.NotificationPreference, error) {
+	preference, err := s.preferenceRepo.Get(*accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return withAllCategories(preference), nil
+}
+
+func (s *notificationPreferenceService) Update(accountID *uuid.UUID, body *request.NotificationPreferenceUpdateRequest) (*model.NotificationPreference, error) {
+	preference := &model.NotificationPreference{
+		AccountID:  *accountID,
+		Timezone:   body.Timezone,
+		Locale:     body.Locale,
+		Categories: make(map[model.NotificationCategory]model.ChannelPreference, len(body.Categories)),
+	}
+	if body.QuietHours != nil {
+		preference.QuietHoursStart, preference.QuietHoursEnd = body.QuietHours.Start, body.QuietHours.End
+	}
+	for category, channels := range body.Categories {
+		preference.Categories[model.NotificationCategory(category)] = channels
+	}
+
+	if err := s.preferenceRepo.Save(preference); err != nil {
+		return nil, err
+	}
+
+	return withAllCategories(preference), nil
+}
+
+// withAllCategories fills in the default channels of the categories the preferences leave out.
+func withAllCategories(preference *model.NotificationPreference) *model.NotificationPreference {
+	categories := make(map[model.NotificationCategory]model.ChannelPreference, len(model.NotificationCategories))
+	for _, category := range model.NotificationCategories {
+		categories[category] = preference.Channels(category)
+	}
+	preference.Categories = categories
+
+	return preference
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+type (
+	// NotificationStreamService provides real-time streams of the notification events of accounts.
+	NotificationStreamService interface {
+		// Open opens a stream of the events of an account, replaying the events after lastEventID first if it is given
+		// and still in the history. The stream ends when ctx is done or the client does not keep up with the events.
+		Open(ctx context.Context, accountID *uuid.UUID, lastEventID string) (*NotificationStream, error)
+	}
+
+	// NotificationStream delivers the events of an account, with an EventHeartbeat whenever the heartbeat interval
+	// passes. Events is closed when the stream ends.
+	NotificationStream struct {
+		Events <-chan *model.NotificationEvent
+	}
+
+	// notificationStreamService struct implements the NotificationStreamService interface.
+	notificationStreamService struct {
+		*Service
+		eventRepo repository.NotificationEventRepository
+	}
+)
+
+// NewNotificationStreamService creates a new instance of NotificationStreamService with the provided service and
+// NotificationEventRepository.
+func NewNotificationStreamService(service *Service, eventRepo repository.NotificationEventRepository) NotificationStreamService {
+	return &notificationStreamService{Service: service, eventRepo: eventRepo}
+}
+
+func (s *notificationStreamService) Open(ctx context.Context, accountID *uuid.UUID, lastEventID string) (*NotificationStream, error) {
+	// Registrations outlive a few missed heartbeats, so streams of a replica that died expire on their own.
+	heartbeat := s.config.StreamHeartbeat
+	connectionTTL := heartbeat * 3
+	connectionID := uuid.NewString()
+
+	acquired, err := s.eventRepo.AcquireConnection(*accountID, connectionID, s.config.StreamMaxConns, connectionTTL)
+	if err != nil {
+		return nil, err
+	} else if !acquired {
+		return nil, errormessage.ErrTooManyStreams
+	}
+
+	release := func() {
+		if err := s.eventRepo.ReleaseConnection(*accountID, connectionID); err != nil {
+			s.log.Error(errormessage.ErrFailedToReleaseStreamText, zap.String("account_id", accountID.String()), zap.Error(err))
+		}
+	}
+
+	// Subscribe before reading the history, so no event published in between is missed.
+	live, unsubscribe, err := s.eventRepo.Subscribe(*accountID)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	var backlog []*model.NotificationEvent
+	if model.ValidEventID(lastEventID) {
+		if backlog, err = s.eventRepo.Since(*accountID, lastEventID); err != nil {
+			unsubscribe()
+			release()
+			return nil, err
+		}
+	}
+
+	events := make(chan *model.NotificationEvent)
+	go func() {
+		defer close(events)
+		defer release()
+		defer unsubscribe()
+
+		send := func(event *model.NotificationEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, event := range backlog {
+			if !send(event) {
+				return
+			}
+			lastEventID = event.ID
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-live:
+				if !ok {
+					return
+				}
+				if !event.After(lastEventID) {
+					continue
+				}
+				if !send(event) {
+					return
+				}
+				lastEventID = event.ID
+			case <-ticker.C:
+				if err := s.eventRepo.RefreshConnection(*accountID, connectionID, connectionTTL); err != nil {
+					s.log.Error(errormessage.ErrFailedToRefreshStreamText, zap.String("account_id", accountID.String()), zap.Error(err))
+				}
+				if !send(&model.NotificationEvent{Type: model.EventHeartbeat}) {
+					return
+				}
+			}
+		}
+	}()
+
+	return &NotificationStream{Events: events}, nil
+}
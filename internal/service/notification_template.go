@@ -0,0 +1,110 @@
+package service
+
+import (
+	"fmt"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type (
+	// NotificationTemplateService provides methods for managing the templates notifications are rendered from.
+	NotificationTemplateService interface {
+		// GetList retrieves a page of templates, matching the search term against the name. The list can be filtered
+		// and sorted by name, locale, category and created_at.
+		GetList(paging *common.Pagination) (*common.EntriesModel[*model.NotificationTemplate], error)
+
+		// Save creates or replaces the template with the name and locale of the request body, after checking that it
+		// parses.
+		Save(body *request.NotificationTemplateSaveRequest) (*model.NotificationTemplate, error)
+
+		// Delete deletes a template by its ID, returning if it was found.
+		Delete(id string) (founded bool, err error)
+
+		// Stats aggregates the delivery and engagement of the pushes rendered from the template with the given name, in
+		// any locale, including pushes of campaigns using it.
+		Stats(name string) (*model.TemplateStats, error)
+	}
+
+	// notificationTemplateService struct implements the NotificationTemplateService interface.
+	notificationTemplateService struct {
+		*Service
+		templateRepo repository.NotificationTemplateRepository
+		receiptRepo  repository.PushReceiptRepository
+	}
+)
+
+// notificationTemplateFields lists the fields template lists can be filtered and sorted by.
+var notificationTemplateFields = common.Fields{
+	"name":       {Column: "name", Type: common.StringField},
+	"locale":     {Column: "locale", Type: common.StringField},
+	"category":   {Column: "category", Type: common.StringField},
+	"created_at": {Column: "created_at", Type: common.TimeField},
+}
+
+// NewNotificationTemplateService creates a new instance of NotificationTemplateService with the provided service,
+// NotificationTemplateRepository and PushReceiptRepository.
+func NewNotificationTemplateService(service *Service, templateRepo repository.NotificationTemplateRepository, receiptRepo repository.PushReceiptRepository) NotificationTemplateService {
+	return &notificationTemplateService{Service: service, templateRepo: templateRepo, receiptRepo: receiptRepo}
+}
+
+func (s *notificationTemplateService) GetList(paging *common.Pagination) (*common.EntriesModel[*model.NotificationTemplate], error) {
+	query, err := paging.ParseQuery(notificationTemplateFields, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	templates, count, err := s.templateRepo.GetList(paging, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewEntries(templates, count, paging.GetPage(count), paging.GetTotalPages(count)), nil
+}
+
+func (s *notificationTemplateService) Save(body *request.NotificationTemplateSaveRequest) (*model.NotificationTemplate, error) {
+	template := &model.NotificationTemplate{
+		Name:             body.Name,
+		Locale:           body.Locale,
+		Category:         model.NotificationCategory(body.Category),
+		Title:            body.Title,
+		ShortDescription: body.ShortDescription,
+		Description:      body.Description,
+	}
+	if template.Category == "" {
+		template.Category = model.CategoryGeneral
+	}
+
+	if err := template.Parse(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errormessage.ErrInvalidTemplate, err)
+	}
+
+	if err := s.templateRepo.Save(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (s *notificationTemplateService) Delete(id string) (founded bool, err error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", id), zap.Error(err))
+		return false, nil
+	}
+
+	return s.templateRepo.Delete(parsedID)
+}
+
+func (s *notificationTemplateService) Stats(name string) (*model.TemplateStats, error) {
+	stats, err := s.receiptRepo.TemplateStats(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TemplateStats{Template: name, PushStats: *stats}, nil
+}
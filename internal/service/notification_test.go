@@ -0,0 +1,93 @@
+package service
+
+import (
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+)
+
+// sendPreferenceRepo serves the same preferences for every account.
+type sendPreferenceRepo struct {
+	repository.NotificationPreferenceRepository
+	categories map[model.NotificationCategory]model.ChannelPreference
+}
+
+// sendNotificationRepo records the notifications and pushes of every send.
+type sendNotificationRepo struct {
+	repository.NotificationRepository
+	notifications []*model.Notification
+	pushes        []*model.PushNotification
+}
+
+// sendEventRepo records the published events.
+type sendEventRepo struct {
+	repository.NotificationEventRepository
+	events []*model.NotificationEvent
+}
+
+func (r *sendPreferenceRepo) Get(accountID uuid.UUID) (*model.NotificationPreference, error) {
+	return &model.NotificationPreference{AccountID: accountID, Timezone: "UTC", Categories: r.categories}, nil
+}
+
+func (r *sendNotificationRepo) Send(_ uuid.UUID, notification *model.Notification, push *model.PushNotification) ([]*model.PushNotification, error) {
+	r.notifications = append(r.notifications, notification)
+	r.pushes = append(r.pushes, push)
+	return nil, nil
+}
+
+func (r *sendEventRepo) Publish(event *model.NotificationEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestSendStoresNotificationsByChannel(t *testing.T) {
+	tests := []struct {
+		name          string
+		channels      model.ChannelPreference
+		wantStored    bool
+		wantEmailOnly bool
+		wantInbox     bool
+		wantPush      bool
+	}{
+		{name: "inbox and push", channels: model.ChannelPreference{Push: true, InApp: true}, wantStored: true, wantInbox: true, wantPush: true},
+		{name: "email only", channels: model.ChannelPreference{Email: true}, wantStored: true, wantEmailOnly: true},
+		{name: "email and push", channels: model.ChannelPreference{Email: true, Push: true}, wantStored: true, wantEmailOnly: true, wantPush: true},
+		{name: "push only", channels: model.ChannelPreference{Push: true}, wantPush: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notificationRepo := &sendNotificationRepo{}
+			eventRepo := &sendEventRepo{}
+			preferenceRepo := &sendPreferenceRepo{categories: map[model.NotificationCategory]model.ChannelPreference{model.CategoryGeneral: tt.channels}}
+			s := NewNotificationService(New(&config.Config{}, logger.Logger{Logger: zap.NewNop()}), notificationRepo, preferenceRepo, nil, eventRepo)
+
+			result, err := s.Send(&request.NotificationSendRequest{AccountID: uuid.NewString(), Title: "Title", ShortDescription: "Short", Description: "Description"})
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			stored := notificationRepo.notifications[0]
+			if (stored != nil) != tt.wantStored {
+				t.Fatalf("stored = %v, want %v", stored != nil, tt.wantStored)
+			}
+			if stored != nil && stored.EmailOnly != tt.wantEmailOnly {
+				t.Errorf("EmailOnly = %v, want %v", stored.EmailOnly, tt.wantEmailOnly)
+			}
+			if (result.Notification != nil) != tt.wantInbox {
+				t.Errorf("inbox entry returned = %v, want %v", result.Notification != nil, tt.wantInbox)
+			}
+			if (len(eventRepo.events) > 0) != tt.wantInbox {
+				t.Errorf("published %d events, want one only for inbox entries", len(eventRepo.events))
+			}
+			if (notificationRepo.pushes[0] != nil) != tt.wantPush {
+				t.Errorf("push = %v, want %v", notificationRepo.pushes[0] != nil, tt.wantPush)
+			}
+		})
+	}
+}
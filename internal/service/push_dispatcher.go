@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"maps"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type (
+	// PushDispatcher delivers pending push notifications in the background. Every dispatcher leases the notifications it
+	// claims, so any number of replicas can run a dispatcher against the same database.
+	PushDispatcher interface {
+		// Start launches the dispatch loop, which polls for due notifications until Shutdown is called.
+		Start()
+
+		// Shutdown stops claiming new notifications and waits until all claimed notifications are settled.
+		Shutdown()
+
+		// DispatchPending claims a single batch of due notifications and delivers it, returning the number of claimed
+		// notifications.
+		DispatchPending(ctx context.Context) (int, error)
+	}
+
+	// pushDispatcher struct implements the PushDispatcher interface.
+	pushDispatcher struct {
+		*Service
+		id              uuid.UUID
+		pushRepo        repository.PushNotificationRepository
+		deviceTokenRepo repository.DeviceTokenRepository
+		provider        push.Provider
+		cancel          context.CancelFunc
+		done            chan struct{}
+	}
+)
+
+// backoffJitter is the fraction of the backoff delay that is randomized, so retries of a burst of failures spread out.
+const backoffJitter = 0.2
+
+// NewPushDispatcher creates a new instance of PushDispatcher delivering notifications through the given provider.
+func NewPushDispatcher(service *Service, pushRepo repository.PushNotificationRepository, deviceTokenRepo repository.DeviceTokenRepository, provider push.Provider) PushDispatcher {
+	return &pushDispatcher{
+		Service:         service,
+		id:              uuid.New(),
+		pushRepo:        pushRepo,
+		deviceTokenRepo: deviceTokenRepo,
+		provider:        provider,
+	}
+}
+
+func (d *pushDispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+
+	go d.run(ctx)
+}
+
+func (d *pushDispatcher) Shutdown() {
+	if d.cancel == nil {
+		return
+	}
+
+	d.cancel()
+	<-d.done
+}
+
+func (d *pushDispatcher) DispatchPending(ctx context.Context) (int, error) {
+	notifications, err := d.pushRepo.ClaimPending(d.id, d.config.PushBatchSize, d.config.PushLeaseTimeout)
+	if err != nil {
+		return 0, err
+	}
+
+	// Deliveries are not bound to ctx: a notification that was claimed is always settled, even during shutdown.
+	deliveryCtx, cancel := context.WithTimeout(context.Background(), d.config.PushLeaseTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(1, d.config.PushWorkers))
+	for _, batch := range groupByOrigin(notifications) {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(batch []*model.PushNotification) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if len(batch) > 1 {
+				d.deliverBatch(deliveryCtx, batch)
+			} else {
+				d.deliver(deliveryCtx, batch[0])
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	return len(notifications), nil
+}
+
+// run polls for due notifications. Full batches are followed by another claim right away, so backlogs drain without
+// waiting for the next tick.
+func (d *pushDispatcher) run(ctx context.Context) {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.config.PushPollInterval)
+	defer ticker.Stop()
+
+	d.log.Info("push dispatcher started", zap.String("dispatcher_id", d.id.String()))
+	for {
+		claimed, err := d.DispatchPending(ctx)
+		if err != nil {
+			d.log.Error(errormessage.ErrFailedToClaimPushText, zap.Error(err))
+		}
+
+		if err == nil && claimed == d.config.PushBatchSize {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			d.log.Info("push dispatcher stopped", zap.String("dispatcher_id", d.id.String()))
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver sends a claimed notification through the provider and records the outcome. Topic and condition
+// notifications are sent to the push service as they are, account-wide notifications are fanned out to the active
+// devices of the account instead.
+func (d *pushDispatcher) deliver(ctx context.Context, notification *model.PushNotification) {
+	if notification.Topic != "" || notification.Condition != "" {
+		message := pushMessage(notification)
+		message.Topic, message.Condition = notification.Topic, notification.Condition
+
+		response, err := d.provider.Send(ctx, message)
+		d.record(notification, nil, response, err)
+		return
+	}
+
+	if notification.DeviceTokenID == nil {
+		d.fanOut(notification)
+		return
+	}
+
+	deviceToken, err := d.deviceTokenRepo.FindByID(*notification.DeviceTokenID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		d.retry(notification, "", err)
+		return
+	} else if err != nil || !deviceToken.Active {
+		d.fail(notification, notification.Retries, "", errormessage.ErrInactiveDeviceTokenText)
+		return
+	}
+
+	d.sendTo(ctx, notification, deviceToken)
+}
+
+// deliverBatch sends claimed notifications of the same origin to a platform. Every notification is sent as a message of
+// its own, since the payloads of rows may differ, but all of them in a single batch if the provider supports it, and one
+// by one otherwise. Web Push subscriptions are always sent one by one, since every message is encrypted for its
+// subscription.
+func (d *pushDispatcher) deliverBatch(ctx context.Context, notifications []*model.PushNotification) {
+	ids := make([]uuid.UUID, 0, len(notifications))
+	for _, notification := range notifications {
+		ids = append(ids, *notification.DeviceTokenID)
+	}
+
+	deviceTokens, err := d.deviceTokenRepo.FindByIDs(ids)
+	if err != nil {
+		for _, notification := range notifications {
+			d.retry(notification, "", err)
+		}
+		return
+	}
+
+	byID := make(map[uuid.UUID]*model.DeviceToken, len(deviceTokens))
+	for _, deviceToken := range deviceTokens {
+		byID[deviceToken.ID] = deviceToken
+	}
+
+	targets := make([]*model.PushNotification, 0, len(notifications))
+	messages := make([]*push.Message, 0, len(notifications))
+	for _, notification := range notifications {
+		deviceToken, ok := byID[*notification.DeviceTokenID]
+		if !ok || !deviceToken.Active {
+			d.fail(notification, notification.Retries, "", errormessage.ErrInactiveDeviceTokenText)
+			continue
+		}
+		if deviceToken.IsWebPush() {
+			d.sendTo(ctx, notification, deviceToken)
+			continue
+		}
+		message := pushMessage(notification)
+		message.Token, message.Provider = deviceToken.Token, string(deviceToken.Provider)
+		targets = append(targets, notification)
+		messages = append(messages, message)
+	}
+
+	if len(targets) == 0 {
+		return
+	}
+
+	batch, ok := d.provider.(push.BatchProvider)
+	if !ok {
+		for _, notification := range targets {
+			d.sendTo(ctx, notification, byID[*notification.DeviceTokenID])
+		}
+		return
+	}
+
+	results, err := batch.SendEach(ctx, messages)
+	for i, notification := range targets {
+		result := push.Result{Err: err}
+		if err == nil && i < len(results) {
+			result = results[i]
+		} else if err == nil {
+			result.Err = push.ErrMissingResult
+		}
+		d.record(notification, byID[*notification.DeviceTokenID], result.Response, result.Err)
+	}
+}
+
+// sendTo sends a notification to a single device token and records the outcome.
+func (d *pushDispatcher) sendTo(ctx context.Context, notification *model.PushNotification, deviceToken *model.DeviceToken) {
+	message := pushMessage(notification)
+	message.Token, message.Provider = deviceToken.Token, string(deviceToken.Provider)
+	if deviceToken.IsWebPush() {
+		message.Keys = &push.Keys{P256dh: deviceToken.P256dh, Auth: deviceToken.Auth}
+	}
+
+	response, err := d.provider.Send(ctx, message)
+	d.record(notification, deviceToken, response, err)
+}
+
+// record settles a notification with the outcome of its delivery. Permanent provider errors fail the notification right
+// away and prune the device token if it is dead, transient errors are retried.
+func (d *pushDispatcher) record(notification *model.PushNotification, deviceToken *model.DeviceToken, response string, err error) {
+	if err == nil {
+		if err := d.pushRepo.MarkSent(notification.ID, d.id, response); err != nil {
+			d.log.Error(errormessage.ErrFailedToUpdatePushText, zap.String("id", notification.ID.String()), zap.Error(err))
+		}
+		return
+	}
+
+	if push.IsPermanent(err) {
+		if deviceToken != nil && push.IsInvalidToken(err) {
+			if err := d.deviceTokenRepo.Deactivate(deviceToken.ID); err != nil {
+				d.log.Error(errormessage.ErrFailedToDeactivateTokenText, zap.String("id", deviceToken.ID.String()), zap.Error(err))
+			}
+		}
+		d.fail(notification, notification.Retries, response, err.Error())
+		return
+	}
+
+	d.retry(notification, response, err)
+}
+
+// fanOut creates one notification per active device of the account, which are delivered in later batches.
+func (d *pushDispatcher) fanOut(notification *model.PushNotification) {
+	deviceTokens, err := d.deviceTokenRepo.GetActive(notification.AccountID)
+	if err != nil {
+		d.retry(notification, "", err)
+		return
+	}
+
+	if len(deviceTokens) == 0 {
+		d.fail(notification, notification.Retries, "", errormessage.ErrNoPushTokenText)
+		return
+	}
+
+	if err := d.pushRepo.FanOut(notification, d.id, deviceTokens); err != nil {
+		d.log.Error(errormessage.ErrFailedToUpdatePushText, zap.String("id", notification.ID.String()), zap.Error(err))
+	}
+}
+
+// retry schedules another attempt with exponential backoff, or fails the notification once the maximum number of
+// retries is exhausted.
+func (d *pushDispatcher) retry(notification *model.PushNotification, response string, cause error) {
+	retries := notification.Retries + 1
+	if int(retries) > d.config.PushMaxRetries {
+		d.fail(notification, retries, response, cause.Error())
+		return
+	}
+
+	nextAttemptAt := time.Now().Add(d.backoff(int(retries)))
+	if err := d.pushRepo.MarkRetry(notification.ID, d.id, retries, nextAttemptAt, response, cause.Error()); err != nil {
+		d.log.Error(errormessage.ErrFailedToUpdatePushText, zap.String("id", notification.ID.String()), zap.Error(err))
+	}
+}
+
+// fail marks the notification as permanently failed.
+func (d *pushDispatcher) fail(notification *model.PushNotification, retries int8, response, lastError string) {
+	d.log.Warn("push notification failed", zap.String("id", notification.ID.String()), zap.String("error", lastError))
+	if err := d.pushRepo.MarkFailed(notification.ID, d.id, retries, response, lastError); err != nil {
+		d.log.Error(errormessage.ErrFailedToUpdatePushText, zap.String("id", notification.ID.String()), zap.Error(err))
+	}
+}
+
+// backoff returns the delay before the given retry: the base delay doubled for every previous retry, capped at the
+// maximum delay, with a random jitter.
+func (d *pushDispatcher) backoff(retries int) time.Duration {
+	delay := d.config.PushBackoffBase
+	for i := 1; i < retries && delay < d.config.PushBackoffMax; i++ {
+		delay *= 2
+	}
+	delay = min(delay, d.config.PushBackoffMax)
+
+	return delay + time.Duration(rand.Float64()*backoffJitter*float64(delay))
+}
+
+// groupByOrigin groups the notifications to devices of the same platform that were fanned out from the same account-wide
+// notification or enqueued for the same inbox entry, so they can be delivered together. Every other notification forms
+// a group of its own.
+func groupByOrigin(notifications []*model.PushNotification) [][]*model.PushNotification {
+	type key struct {
+		origin   uuid.UUID
+		platform model.Platform
+	}
+
+	groups := make([][]*model.PushNotification, 0, len(notifications))
+	index := make(map[key]int)
+	for _, notification := range notifications {
+		origin := notification.ParentID
+		if origin == nil {
+			origin = notification.NotificationID
+		}
+
+		if origin == nil || notification.DeviceTokenID == nil {
+			groups = append(groups, []*model.PushNotification{notification})
+			continue
+		}
+
+		k := key{origin: *origin, platform: notification.Platform}
+		if i, ok := index[k]; ok {
+			groups[i] = append(groups[i], notification)
+			continue
+		}
+
+		index[k] = len(groups)
+		groups = append(groups, []*model.PushNotification{notification})
+	}
+
+	return groups
+}
+
+// pushMessage builds the provider message of a notification without its target. The data of the message carries the ID
+// of the notification under PushDataPushID, so clients can report receipts for it.
+func pushMessage(notification *model.PushNotification) *push.Message {
+	data := make(map[string]string, len(notification.Data)+1)
+	maps.Copy(data, notification.Data)
+	data[model.PushDataPushID] = notification.ID.String()
+
+	return &push.Message{
+		Title:   notification.Title,
+		Body:    notification.Message,
+		Image:   notification.Image,
+		Data:    data,
+		Options: notification.Options,
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"errors"
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/logger"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"slices"
+	"testing"
+	"time"
+)
+
+// dispatcherPushRepo records how the dispatcher settles notifications.
+type dispatcherPushRepo struct {
+	repository.PushNotificationRepository
+	sent    int
+	retried []int8
+	failed  []int8
+}
+
+// dispatcherDeviceTokenRepo records the deactivated device tokens.
+type dispatcherDeviceTokenRepo struct {
+	repository.DeviceTokenRepository
+	deactivated []uuid.UUID
+}
+
+func (r *dispatcherPushRepo) MarkSent(_, _ uuid.UUID, _ string) error {
+	r.sent++
+	return nil
+}
+
+func (r *dispatcherPushRepo) MarkRetry(_, _ uuid.UUID, retries int8, _ time.Time, _, _ string) error {
+	r.retried = append(r.retried, retries)
+	return nil
+}
+
+func (r *dispatcherPushRepo) MarkFailed(_, _ uuid.UUID, retries int8, _, _ string) error {
+	r.failed = append(r.failed, retries)
+	return nil
+}
+
+func (r *dispatcherDeviceTokenRepo) Deactivate(id uuid.UUID) error {
+	r.deactivated = append(r.deactivated, id)
+	return nil
+}
+
+func newTestDispatcher(cfg *config.Config) (*pushDispatcher, *dispatcherPushRepo, *dispatcherDeviceTokenRepo) {
+	pushRepo, deviceTokenRepo := &dispatcherPushRepo{}, &dispatcherDeviceTokenRepo{}
+	dispatcher := NewPushDispatcher(New(cfg, logger.Logger{Logger: zap.NewNop()}), pushRepo, deviceTokenRepo, nil).(*pushDispatcher)
+
+	return dispatcher, pushRepo, deviceTokenRepo
+}
+
+func TestPushMessageCarriesPushID(t *testing.T) {
+	first := &model.PushNotification{ID: uuid.New(), Data: map[string]string{model.PushDataCampaignID: "campaign"}}
+	second := &model.PushNotification{ID: uuid.New(), Data: first.Data}
+
+	for _, notification := range []*model.PushNotification{first, second} {
+		message := pushMessage(notification)
+		if got := message.Data[model.PushDataPushID]; got != notification.ID.String() {
+			t.Errorf("push ID = %q, want %q", got, notification.ID)
+		}
+		if got := message.Data[model.PushDataCampaignID]; got != "campaign" {
+			t.Errorf("campaign ID = %q, want %q", got, "campaign")
+		}
+	}
+
+	if _, ok := first.Data[model.PushDataPushID]; ok {
+		t.Error("pushMessage modified the data of the notification")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	dispatcher, _, _ := newTestDispatcher(&config.Config{PushBackoffBase: time.Second, PushBackoffMax: 10 * time.Second})
+
+	tests := []struct {
+		retries int
+		want    time.Duration
+	}{
+		{retries: 1, want: time.Second},
+		{retries: 2, want: 2 * time.Second},
+		{retries: 3, want: 4 * time.Second},
+		{retries: 4, want: 8 * time.Second},
+		{retries: 5, want: 10 * time.Second},
+		{retries: 50, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			got := dispatcher.backoff(tt.retries)
+			if maxDelay := tt.want + time.Duration(backoffJitter*float64(tt.want)); got < tt.want || got > maxDelay {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", tt.retries, got, tt.want, maxDelay)
+			}
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		retries     int8
+		wantRetried []int8
+		wantFailed  []int8
+	}{
+		{name: "first retry", retries: 0, wantRetried: []int8{1}},
+		{name: "last retry", retries: 2, wantRetried: []int8{3}},
+		{name: "retries exhausted", retries: 3, wantFailed: []int8{4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher, pushRepo, _ := newTestDispatcher(&config.Config{PushMaxRetries: 3, PushBackoffBase: time.Second, PushBackoffMax: time.Minute})
+
+			dispatcher.retry(&model.PushNotification{ID: uuid.New(), Retries: tt.retries}, "", errors.New("unavailable"))
+			if !slices.Equal(pushRepo.retried, tt.wantRetried) || !slices.Equal(pushRepo.failed, tt.wantFailed) {
+				t.Errorf("retried %v and failed %v, want retried %v and failed %v", pushRepo.retried, pushRepo.failed, tt.wantRetried, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestFail(t *testing.T) {
+	dispatcher, pushRepo, _ := newTestDispatcher(&config.Config{})
+
+	dispatcher.fail(&model.PushNotification{ID: uuid.New(), Retries: 2}, 2, "", "invalid payload")
+	if !slices.Equal(pushRepo.failed, []int8{2}) || len(pushRepo.retried) != 0 {
+		t.Errorf("failed %v and retried %v, want failed [2] only", pushRepo.failed, pushRepo.retried)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	deviceToken := &model.DeviceToken{ID: uuid.New()}
+
+	tests := []struct {
+		name            string
+		deviceToken     *model.DeviceToken
+		err             error
+		wantSent        int
+		wantRetried     int
+		wantFailed      int
+		wantDeactivated int
+	}{
+		{name: "sent", deviceToken: deviceToken, wantSent: 1},
+		{name: "transient error", deviceToken: deviceToken, err: &push.Error{Err: errors.New("unavailable")}, wantRetried: 1},
+		{name: "network error", deviceToken: deviceToken, err: errors.New("connection reset"), wantRetried: 1},
+		{name: "invalid message", deviceToken: deviceToken, err: &push.Error{Err: errors.New("invalid argument"), Permanent: true}, wantFailed: 1},
+		{name: "invalid token", deviceToken: deviceToken, err: &push.Error{Err: errors.New("unregistered"), Permanent: true, InvalidToken: true}, wantFailed: 1, wantDeactivated: 1},
+		{name: "invalid token of a topic push", err: &push.Error{Err: errors.New("unregistered"), Permanent: true, InvalidToken: true}, wantFailed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher, pushRepo, deviceTokenRepo := newTestDispatcher(&config.Config{PushMaxRetries: 3, PushBackoffBase: time.Second, PushBackoffMax: time.Minute})
+
+			dispatcher.record(&model.PushNotification{ID: uuid.New()}, tt.deviceToken, "", tt.err)
+			if pushRepo.sent != tt.wantSent || len(pushRepo.retried) != tt.wantRetried || len(pushRepo.failed) != tt.wantFailed {
+				t.Errorf("sent %d, retried %d and failed %d, want %d, %d and %d", pushRepo.sent, len(pushRepo.retried), len(pushRepo.failed), tt.wantSent, tt.wantRetried, tt.wantFailed)
+			}
+			if len(deviceTokenRepo.deactivated) != tt.wantDeactivated {
+				t.Errorf("deactivated %d tokens, want %d", len(deviceTokenRepo.deactivated), tt.wantDeactivated)
+			}
+		})
+	}
+}
+
+func TestGroupByOrigin(t *testing.T) {
+	parent, inbox := uuid.New(), uuid.New()
+	device := func() *uuid.UUID {
+		id := uuid.New()
+		return &id
+	}
+
+	fannedOutAndroid := &model.PushNotification{ID: uuid.New(), ParentID: &parent, DeviceTokenID: device(), Platform: model.Android}
+	fannedOutAndroid2 := &model.PushNotification{ID: uuid.New(), ParentID: &parent, DeviceTokenID: device(), Platform: model.Android}
+	fannedOutIOS := &model.PushNotification{ID: uuid.New(), ParentID: &parent, DeviceTokenID: device(), Platform: model.IOS}
+	inboxAndroid := &model.PushNotification{ID: uuid.New(), NotificationID: &inbox, DeviceTokenID: device(), Platform: model.Android}
+	inboxAndroid2 := &model.PushNotification{ID: uuid.New(), NotificationID: &inbox, DeviceTokenID: device(), Platform: model.Android}
+	accountWide := &model.PushNotification{ID: uuid.New(), NotificationID: &inbox, Platform: model.Android}
+	single := &model.PushNotification{ID: uuid.New(), DeviceTokenID: device(), Platform: model.Android}
+	topic := &model.PushNotification{ID: uuid.New(), Topic: "news", Platform: model.Android}
+
+	tests := []struct {
+		name          string
+		notifications []*model.PushNotification
+		want          [][]*model.PushNotification
+	}{
+		{name: "empty"},
+		{
+			name:          "same parent and platform",
+			notifications: []*model.PushNotification{fannedOutAndroid, fannedOutAndroid2},
+			want:          [][]*model.PushNotification{{fannedOutAndroid, fannedOutAndroid2}},
+		},
+		{
+			name:          "same parent on other platforms",
+			notifications: []*model.PushNotification{fannedOutAndroid, fannedOutIOS, fannedOutAndroid2},
+			want:          [][]*model.PushNotification{{fannedOutAndroid, fannedOutAndroid2}, {fannedOutIOS}},
+		},
+		{
+			name:          "same inbox entry",
+			notifications: []*model.PushNotification{inboxAndroid, single, inboxAndroid2},
+			want:          [][]*model.PushNotification{{inboxAndroid, inboxAndroid2}, {single}},
+		},
+		{
+			name:          "without device or origin",
+			notifications: []*model.PushNotification{accountWide, topic, single},
+			want:          [][]*model.PushNotification{{accountWide}, {topic}, {single}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupByOrigin(tt.notifications)
+			if len(got) != len(tt.want) {
+				t.Fatalf("groupByOrigin() returned %d groups, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if len(got[i]) != len(tt.want[i]) {
+					t.Fatalf("group %d has %d notifications, want %d", i, len(got[i]), len(tt.want[i]))
+				}
+				for j := range got[i] {
+					if got[i][j] != tt.want[i][j] {
+						t.Errorf("group %d notification %d = %s, want %s", i, j, got[i][j].ID, tt.want[i][j].ID)
+					}
+				}
+			}
+		})
+	}
+}
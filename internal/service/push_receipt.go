@@ -0,0 +1,92 @@
+package service
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/internal/types/response"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+type (
+	// PushReceiptService provides methods for recording the delivery, opening and dismissal of push notifications
+	// reported by the devices of an account.
+	PushReceiptService interface {
+		// Record stores the events of the request body for the pushes of the given account. Event times in the future
+		// are clamped to now. Opening a push for the first time marks its inbox entry as read.
+		Record(accountID *uuid.UUID, body *request.PushReceiptRequest) (*response.PushReceiptResponse, error)
+	}
+
+	// pushReceiptService struct implements the PushReceiptService interface.
+	pushReceiptService struct {
+		*Service
+		receiptRepo      repository.PushReceiptRepository
+		notificationRepo repository.NotificationRepository
+		eventRepo        repository.NotificationEventRepository
+	}
+)
+
+// NewPushReceiptService creates a new instance of PushReceiptService with the provided service, PushReceiptRepository,
+// NotificationRepository and NotificationEventRepository.
+func NewPushReceiptService(service *Service, receiptRepo repository.PushReceiptRepository, notificationRepo repository.NotificationRepository, eventRepo repository.NotificationEventRepository) PushReceiptService {
+	return &pushReceiptService{Service: service, receiptRepo: receiptRepo, notificationRepo: notificationRepo, eventRepo: eventRepo}
+}
+
+func (s *pushReceiptService) Record(accountID *uuid.UUID, body *request.PushReceiptRequest) (*response.PushReceiptResponse, error) {
+	var deviceID *uuid.UUID
+	if body.DeviceID != "" {
+		if id, err := uuid.Parse(body.DeviceID); err == nil {
+			deviceID = &id
+		} else {
+			s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", body.DeviceID), zap.Error(err))
+		}
+	}
+
+	now := time.Now()
+	receipts := make([]*model.PushReceipt, 0, len(body.Events))
+	for _, event := range body.Events {
+		pushID, err := uuid.Parse(event.PushID)
+		if err != nil {
+			s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", event.PushID), zap.Error(err))
+			continue
+		}
+
+		occurredAt := now
+		if event.OccurredAt != nil && event.OccurredAt.Before(now) {
+			occurredAt = event.OccurredAt.Local()
+		}
+
+		receipts = append(receipts, &model.PushReceipt{
+			PushNotificationID: pushID,
+			Event:              model.PushEvent(event.Event),
+			OccurredAt:         occurredAt,
+			DeviceID:           deviceID,
+			Platform:           body.Platform,
+			DeviceModel:        body.DeviceModel,
+			OSVersion:          body.OSVersion,
+			AppVersion:         body.AppVersion,
+		})
+	}
+
+	if len(receipts) == 0 {
+		return &response.PushReceiptResponse{Ignored: int64(len(body.Events))}, nil
+	}
+
+	recorded, opened, err := s.receiptRepo.Record(*accountID, receipts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opened) > 0 {
+		_, changed, err := s.notificationRepo.SetRead(*accountID, opened, true)
+		if err != nil {
+			return nil, err
+		}
+		s.publishReadState(s.eventRepo, *accountID, changed, true)
+	}
+
+	return &response.PushReceiptResponse{Recorded: recorded, Ignored: int64(len(body.Events)) - recorded}, nil
+}
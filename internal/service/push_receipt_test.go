@@ -0,0 +1,93 @@
+package service
+
+import (
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// receiptRepo records every receipt and reports the given inbox entries as opened.
+type receiptRepo struct {
+	repository.PushReceiptRepository
+	receipts []*model.PushReceipt
+	opened   []uuid.UUID
+}
+
+// readNotificationRepo marks the inbox entries it is given as read, unless they already are.
+type readNotificationRepo struct {
+	repository.NotificationRepository
+	read map[uuid.UUID]bool
+}
+
+func (r *receiptRepo) Record(_ uuid.UUID, receipts []*model.PushReceipt) (int64, []uuid.UUID, error) {
+	r.receipts = append(r.receipts, receipts...)
+	return int64(len(receipts)), r.opened, nil
+}
+
+func (r *readNotificationRepo) SetRead(_ uuid.UUID, ids []uuid.UUID, read bool) (int, []*model.Notification, error) {
+	now := time.Now()
+	var changed []*model.Notification
+	for _, id := range ids {
+		if r.read[id] != read {
+			r.read[id] = read
+			changed = append(changed, &model.Notification{ID: id, ReadAt: &now})
+		}
+	}
+
+	return len(ids), changed, nil
+}
+
+func TestRecordMarksOpenedNotificationsAsRead(t *testing.T) {
+	unread, alreadyRead := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name       string
+		opened     []uuid.UUID
+		wantRead   []uuid.UUID
+		wantEvents int
+	}{
+		{name: "no push opened", wantEvents: 0},
+		{name: "unread entry opened", opened: []uuid.UUID{unread}, wantRead: []uuid.UUID{unread}, wantEvents: 1},
+		{name: "read entry opened", opened: []uuid.UUID{alreadyRead}, wantEvents: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipts := &receiptRepo{opened: tt.opened}
+			notifications := &readNotificationRepo{read: map[uuid.UUID]bool{unread: false, alreadyRead: true}}
+			events := &sendEventRepo{}
+			s := NewPushReceiptService(New(&config.Config{}, logger.Logger{Logger: zap.NewNop()}), receipts, notifications, events)
+
+			accountID := uuid.New()
+			body := &request.PushReceiptRequest{Events: []request.PushEventRequest{{PushID: uuid.NewString(), Event: string(model.PushOpened)}}}
+			result, err := s.Record(&accountID, body)
+			if err != nil {
+				t.Fatalf("Record() error = %v", err)
+			}
+			if result.Recorded != 1 {
+				t.Errorf("Recorded = %d, want 1", result.Recorded)
+			}
+
+			for _, id := range tt.wantRead {
+				if !notifications.read[id] {
+					t.Errorf("notification %s not marked as read", id)
+				}
+			}
+
+			if len(events.events) != tt.wantEvents {
+				t.Fatalf("published %d events, want %d", len(events.events), tt.wantEvents)
+			}
+			for _, event := range events.events {
+				if event.Type != model.EventNotificationRead {
+					t.Errorf("event type = %s, want %s", event.Type, model.EventNotificationRead)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"go.uber.org/zap"
+	"time"
+)
+
+type (
+	// RetentionPurger deletes notifications and push notifications past their retention in the background, batch by
+	// batch. Read and soft deleted notifications are kept for ReadRetention days and delivered or failed pushes for
+	// PushRetention days, a retention of 0 keeps them forever. Depending on PurgeArchive, rows are archived to the
+	// PurgedRecord table or to JSONL files before they are deleted.
+	RetentionPurger interface {
+		// Start launches the purge loop, which purges every PurgeInterval until Shutdown is called.
+		Start()
+
+		// Shutdown stops the purge loop and waits until the current batch is committed.
+		Shutdown()
+
+		// PurgeDue purges every notification and push notification past its retention, returning how many rows were
+		// deleted.
+		PurgeDue(ctx context.Context) (int64, error)
+	}
+
+	// retentionPurger struct implements the RetentionPurger interface.
+	retentionPurger struct {
+		*Service
+		retentionRepo repository.RetentionRepository
+		cancel        context.CancelFunc
+		done          chan struct{}
+	}
+)
+
+// retentionDay is the unit of the retention periods.
+const retentionDay = 24 * time.Hour
+
+// NewRetentionPurger creates a new instance of RetentionPurger with the provided service and RetentionRepository.
+func NewRetentionPurger(service *Service, retentionRepo repository.RetentionRepository) RetentionPurger {
+	return &retentionPurger{Service: service, retentionRepo: retentionRepo}
+}
+
+func (r *retentionPurger) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	go r.loop(ctx)
+}
+
+func (r *retentionPurger) Shutdown() {
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+}
+
+func (r *retentionPurger) PurgeDue(ctx context.Context) (int64, error) {
+	archive, err := r.archive()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var purged int64
+	if r.config.ReadRetention > 0 {
+		cutoff := now.Add(-time.Duration(r.config.ReadRetention) * retentionDay)
+		deleted, err := r.purgeAll(ctx, func() (int64, error) {
+			return r.retentionRepo.PurgeNotifications(cutoff, r.config.PurgeBatch, archive)
+		})
+		purged += deleted
+		if err != nil {
+			return purged, err
+		}
+		if deleted > 0 {
+			r.log.Info("notifications purged", zap.Int64("count", deleted))
+		}
+	}
+
+	if r.config.PushRetention > 0 {
+		cutoff := now.Add(-time.Duration(r.config.PushRetention) * retentionDay)
+		deleted, err := r.purgeAll(ctx, func() (int64, error) {
+			return r.retentionRepo.PurgePushNotifications(cutoff, r.config.PurgeBatch, archive)
+		})
+		purged += deleted
+		if err != nil {
+			return purged, err
+		}
+		if deleted > 0 {
+			r.log.Info("push notifications purged", zap.Int64("count", deleted))
+		}
+	}
+
+	return purged, nil
+}
+
+// loop purges every PurgeInterval.
+func (r *retentionPurger) loop(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.config.PurgeInterval)
+	defer ticker.Stop()
+
+	r.log.Info("retention purger started")
+	for {
+		if _, err := r.PurgeDue(ctx); err != nil {
+			r.log.Error(errormessage.ErrFailedToPurgeText, zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			r.log.Info("retention purger stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeAll purges batch after batch until a batch comes back short or the context is canceled.
+func (r *retentionPurger) purgeAll(ctx context.Context, purge func() (int64, error)) (int64, error) {
+	var purged int64
+	for ctx.Err() == nil {
+		deleted, err := purge()
+		purged += deleted
+		if err != nil || deleted < int64(r.config.PurgeBatch) {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// archive returns the Archive selected by PurgeArchive, nil if purged rows are not kept.
+func (r *retentionPurger) archive() (repository.Archive, error) {
+	switch model.PurgeArchive(r.config.PurgeArchive) {
+	case model.ArchiveNone:
+		return nil, nil
+	case model.ArchiveTable:
+		return repository.NewTableArchive(), nil
+	case model.ArchiveFile:
+		return repository.NewFileArchive(r.config.PurgeArchiveDir), nil
+	default:
+		return nil, errormessage.ErrInvalidPurgeArchive
+	}
+}
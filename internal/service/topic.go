@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type (
+	// TopicService provides methods for managing the push topic subscriptions of accounts. Subscriptions are stored per
+	// account and applied to the push tokens of all active devices of the account.
+	TopicService interface {
+		// GetList retrieves the topic subscriptions of the given account.
+		GetList(accountID *uuid.UUID) ([]*model.TopicSubscription, error)
+
+		// Subscribe subscribes the given account and the push tokens of its devices to the topic.
+		Subscribe(accountID *uuid.UUID, topic string) error
+
+		// Unsubscribe unsubscribes the given account and the push tokens of its devices from the topic, returning if the
+		// subscription was found.
+		Unsubscribe(accountID *uuid.UUID, topic string) (founded bool, err error)
+
+		// Broadcast enqueues a push to the devices subscribed to a topic or matching a condition over topics. The push is
+		// not tied to an account and is delivered by the push dispatcher like any other.
+		Broadcast(body *request.PushBroadcastRequest) (*model.PushNotification, error)
+	}
+
+	// topicService struct implements the TopicService interface.
+	topicService struct {
+		*Service
+		topicRepo       repository.TopicRepository
+		deviceTokenRepo repository.DeviceTokenRepository
+		pushRepo        repository.PushNotificationRepository
+		topics          push.TopicManager
+	}
+
+	// conditionParser checks the syntax of FCM conditions, counting the topics they test.
+	conditionParser struct {
+		input  string
+		pos    int
+		topics int
+	}
+)
+
+// topicSyncTimeout bounds the requests to the push service made while managing topic subscriptions.
+const topicSyncTimeout = time.Second * 30
+
+// topicPattern matches the topic names accepted by FCM.
+var topicPattern = regexp.MustCompile(`^[a-zA-Z0-9\-_.~%]{1,900}$`)
+
+// maxConditionTopics is the number of topics FCM allows a condition to test.
+const maxConditionTopics = 5
+
+// NewTopicService creates a new instance of TopicService with the provided service, TopicRepository,
+// DeviceTokenRepository, PushNotificationRepository and TopicManager.
+func NewTopicService(service *Service, topicRepo repository.TopicRepository, deviceTokenRepo repository.DeviceTokenRepository, pushRepo repository.PushNotificationRepository, topics push.TopicManager) TopicService {
+	return &topicService{Service: service, topicRepo: topicRepo, deviceTokenRepo: deviceTokenRepo, pushRepo: pushRepo, topics: topics}
+}
+
+func (s *topicService) GetList(accountID *uuid.UUID) ([]*model.TopicSubscription, error) {
+	return s.topicRepo.GetList(*accountID)
+}
+
+func (s *topicService) Subscribe(accountID *uuid.UUID, topic string) error {
+	if !topicPattern.MatchString(topic) {
+		return errormessage.ErrInvalidTopic
+	}
+
+	tokens, err := s.deviceTokens(*accountID)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), topicSyncTimeout)
+		defer cancel()
+
+		if err := s.topics.Subscribe(ctx, topic, tokens); err != nil {
+			return err
+		}
+	}
+
+	return s.topicRepo.Subscribe(*accountID, topic)
+}
+
+func (s *topicService) Unsubscribe(accountID *uuid.UUID, topic string) (founded bool, err error) {
+	founded, err = s.topicRepo.Unsubscribe(*accountID, topic)
+	if err != nil || !founded {
+		return founded, err
+	}
+
+	tokens, err := s.deviceTokens(*accountID)
+	if err != nil {
+		return true, err
+	}
+
+	if len(tokens) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), topicSyncTimeout)
+		defer cancel()
+
+		if err := s.topics.Unsubscribe(ctx, topic, tokens); err != nil {
+			s.log.Error(errormessage.ErrFailedToSyncTopicsText, zap.String("topic", topic), zap.Error(err))
+		}
+	}
+
+	return true, nil
+}
+
+func (s *topicService) Broadcast(body *request.PushBroadcastRequest) (*model.PushNotification, error) {
+	if body.Topic != "" && !topicPattern.MatchString(body.Topic) {
+		return nil, errormessage.ErrInvalidTopic
+	}
+
+	if body.Condition != "" && !validCondition(body.Condition) {
+		return nil, errormessage.ErrInvalidCondition
+	}
+
+	if body.Options != nil {
+		if err := body.Options.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	platform := model.Android
+	if body.Platform != "" {
+		platform = model.Platform(body.Platform)
+	}
+
+	data := body.Data
+	if data == nil {
+		data = make(map[string]string)
+	}
+
+	notification := &model.PushNotification{
+		AccountID:     uuid.Nil,
+		Title:         body.Title,
+		Message:       body.Message,
+		Image:         body.Image,
+		Data:          data,
+		Platform:      platform,
+		Status:        model.Pending,
+		Topic:         body.Topic,
+		Condition:     body.Condition,
+		Options:       body.Options,
+		NextAttemptAt: time.Now(),
+	}
+
+	if err := s.pushRepo.Create(notification); err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+// deviceTokens returns the FCM tokens of the active devices of the account, tokens of other push services can not be
+// subscribed to topics.
+func (s *topicService) deviceTokens(accountID uuid.UUID) ([]string, error) {
+	deviceTokens, err := s.deviceTokenRepo.GetActive(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]string, 0, len(deviceTokens))
+	for _, deviceToken := range deviceTokens {
+		if deviceToken.IsFCM() {
+			tokens = append(tokens, deviceToken.Token)
+		}
+	}
+
+	return tokens, nil
+}
+
+// validCondition reports whether the condition is a valid FCM condition: tests like 'topic' in topics, at most
+// maxConditionTopics of them, combined with && and || and grouped with parentheses.
+func validCondition(condition string) bool {
+	p := &conditionParser{input: condition}
+	if !p.expression() {
+		return false
+	}
+
+	p.skipSpaces()
+	return p.pos == len(p.input) && p.topics <= maxConditionTopics
+}
+
+// expression parses tests joined by && and ||.
+func (p *conditionParser) expression() bool {
+	if !p.term() {
+		return false
+	}
+
+	for {
+		p.skipSpaces()
+		if !p.consume("&&") && !p.consume("||") {
+			return true
+		}
+		if !p.term() {
+			return false
+		}
+	}
+}
+
+// term parses a parenthesized expression or a single topic test.
+func (p *conditionParser) term() bool {
+	p.skipSpaces()
+	if p.consume("(") {
+		if !p.expression() {
+			return false
+		}
+		p.skipSpaces()
+		return p.consume(")")
+	}
+
+	if p.pos >= len(p.input) || (p.input[p.pos] != '\'' && p.input[p.pos] != '"') {
+		return false
+	}
+
+	quote := p.input[p.pos]
+	end := strings.IndexByte(p.input[p.pos+1:], quote)
+	if end < 0 || !topicPattern.MatchString(p.input[p.pos+1:p.pos+1+end]) {
+		return false
+	}
+	p.pos += end + 2
+	p.topics++
+
+	if !p.skipSpaces() || !p.consume("in") || !p.skipSpaces() {
+		return false
+	}
+
+	return p.consume("topics")
+}
+
+// consume advances past the token if the input continues with it.
+func (p *conditionParser) consume(token string) bool {
+	if !strings.HasPrefix(p.input[p.pos:], token) {
+		return false
+	}
+
+	p.pos += len(token)
+	return true
+}
+
+// skipSpaces advances past spaces, returning if there were any.
+func (p *conditionParser) skipSpaces() bool {
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+
+	return p.pos > start
+}
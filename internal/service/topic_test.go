@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"testing"
+)
+
+func TestValidCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      bool
+	}{
+		{`'news' in topics`, true},
+		{`"news" in topics`, true},
+		{`'news' in topics && 'sports' in topics`, true},
+		{`'news' in topics&&'sports' in topics`, true},
+		{`'a' in topics && ('b' in topics || 'c' in topics)`, true},
+		{`(('a' in topics))`, true},
+		{`'a' in topics || 'b' in topics || 'c' in topics || 'd' in topics || 'e' in topics`, true},
+		{`'a' in topics || 'b' in topics || 'c' in topics || 'd' in topics || 'e' in topics || 'f' in topics`, false},
+		{``, false},
+		{`news in topics`, false},
+		{`'news' in topic`, false},
+		{`'news' in topicsx`, false},
+		{`'news'in topics`, false},
+		{`'news' in topics &&`, false},
+		{`'news' in topics & 'sports' in topics`, false},
+		{`('news' in topics`, false},
+		{`'news' in topics)`, false},
+		{`'news" in topics`, false},
+		{`'' in topics`, false},
+		{`'bad topic' in topics`, false},
+		{`!('news' in topics)`, false},
+	}
+
+	for _, tt := range tests {
+		if got := validCondition(tt.condition); got != tt.want {
+			t.Errorf("validCondition(%q) = %v, want %v", tt.condition, got, tt.want)
+		}
+	}
+}
+
+type fakePushNotificationRepository struct {
+	repository.PushNotificationRepository
+	created []*model.PushNotification
+}
+
+func (r *fakePushNotificationRepository) Create(notification *model.PushNotification) error {
+	r.created = append(r.created, notification)
+	return nil
+}
+
+func TestBroadcast(t *testing.T) {
+	pushRepo := &fakePushNotificationRepository{}
+	s := &topicService{pushRepo: pushRepo}
+
+	notification, err := s.Broadcast(&request.PushBroadcastRequest{Condition: `'news' in topics || 'sports' in topics`, Title: "Title", Message: "Message"})
+	if err != nil {
+		t.Fatalf("Broadcast() error = %v", err)
+	}
+	if notification.AccountID != uuid.Nil || notification.Condition == "" || notification.Platform != model.Android || notification.Status != model.Pending {
+		t.Errorf("Broadcast() = %+v, want a pending Android condition push without account", notification)
+	}
+	if notification.Data == nil {
+		t.Error("Broadcast() left the data nil")
+	}
+
+	if _, err := s.Broadcast(&request.PushBroadcastRequest{Topic: "bad topic", Title: "Title", Message: "Message"}); !errors.Is(err, errormessage.ErrInvalidTopic) {
+		t.Errorf("Broadcast() error = %v, want %v", err, errormessage.ErrInvalidTopic)
+	}
+	if _, err := s.Broadcast(&request.PushBroadcastRequest{Condition: "news", Title: "Title", Message: "Message"}); !errors.Is(err, errormessage.ErrInvalidCondition) {
+		t.Errorf("Broadcast() error = %v, want %v", err, errormessage.ErrInvalidCondition)
+	}
+	if len(pushRepo.created) != 1 {
+		t.Errorf("created %d pushes, want 1", len(pushRepo.created))
+	}
+}
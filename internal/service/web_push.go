@@ -0,0 +1,96 @@
+package service
+
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/internal/repository"
+	"github.com/arifai/zenith/internal/types/request"
+	"github.com/arifai/zenith/internal/types/response"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/webpush"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type (
+	// WebPushService provides methods for managing the native Web Push subscriptions of browsers. A subscription is
+	// stored as the push token of the Web device it belongs to and delivered by the push dispatcher.
+	WebPushService interface {
+		// GetPublicKey returns the VAPID public key browsers subscribe with.
+		GetPublicKey() (*response.WebPushKeyResponse, error)
+
+		// Subscribe stores the Web Push subscription of a device of the given account, replacing the previous push token
+		// of the device. The endpoint must belong to one of the known browser push services.
+		Subscribe(accountID *uuid.UUID, body *request.WebPushSubscriptionRequest) error
+
+		// Unsubscribe removes the Web Push subscription of a device of the given account, returning if it was found.
+		Unsubscribe(accountID *uuid.UUID, deviceID string) (founded bool, err error)
+	}
+
+	// webPushService struct implements the WebPushService interface.
+	webPushService struct {
+		*Service
+		deviceTokenRepo repository.DeviceTokenRepository
+	}
+)
+
+// NewWebPushService creates a new instance of WebPushService with the provided service and DeviceTokenRepository.
+func NewWebPushService(service *Service, deviceTokenRepo repository.DeviceTokenRepository) WebPushService {
+	return &webPushService{Service: service, deviceTokenRepo: deviceTokenRepo}
+}
+
+func (s *webPushService) GetPublicKey() (*response.WebPushKeyResponse, error) {
+	if s.config.VAPIDPrivateKey == "" {
+		return nil, errormessage.ErrWebPushDisabled
+	}
+
+	publicKey, err := webpush.PublicKey(s.config.VAPIDPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.WebPushKeyResponse{PublicKey: publicKey}, nil
+}
+
+func (s *webPushService) Subscribe(accountID *uuid.UUID, body *request.WebPushSubscriptionRequest) error {
+	if s.config.VAPIDPrivateKey == "" {
+		return errormessage.ErrWebPushDisabled
+	}
+
+	deviceID, err := uuid.Parse(body.DeviceID)
+	if err != nil {
+		s.log.Error(errormessage.ErrFailedToParseUUIDText, zap.String("input", body.DeviceID), zap.Error(err))
+		return errormessage.ErrInvalidDeviceIDInBody
+	}
+
+	if err := webpush.ValidateEndpoint(body.Endpoint, nil); err != nil {
+		return err
+	}
+
+	if err := webpush.ValidateKeys(body.Keys.P256dh, body.Keys.Auth); err != nil {
+		return errormessage.ErrInvalidWebPushKeys
+	}
+
+	return s.deviceTokenRepo.Register(&model.DeviceToken{
+		AccountID: *accountID,
+		DeviceID:  deviceID,
+		Platform:  model.Web,
+		Token:     body.Endpoint,
+		Provider:  model.ProviderWebPush,
+		P256dh:    body.Keys.P256dh,
+		Auth:      body.Keys.Auth,
+	})
+}
+
+func (s *webPushService) Unsubscribe(accountID *uuid.UUID, deviceID string) (founded bool, err error) {
+	parsedDeviceID, err := uuid.Parse(deviceID)
+	if err != nil {
+		return false, nil
+	}
+
+	deviceTokens, err := s.deviceTokenRepo.Remove(*accountID, parsedDeviceID)
+	if err != nil {
+		return false, err
+	}
+
+	return len(deviceTokens) > 0, nil
+}
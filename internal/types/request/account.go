@@ -16,12 +16,16 @@ type (
 	}
 
 	// AccountAuthRequest represents a request for authenticating an account.
-	// It contains the necessary fields for Email and Password validation.
+	// It contains the necessary fields for Email and Password validation, and the push token of the device,
+	// whose Platform defaults to Android. PushProvider tells which push service issued the token, FCM by default; iOS
+	// apps using APNs directly send their device token as FcmToken with PushProvider "apns".
 	AccountAuthRequest struct {
-		Email    string `json:"email" validate:"required,email" reason:"required:Email is required;email:Invalid email address"`
-		FcmToken string `json:"fcm_token" validate:"required" reason:"required:FCM token is required"`
-		Password string `json:"password" validate:"required,min=8,max=100" reason:"required:Password is required;min:Password must be at least 8 characters;max:Password must be at most 100 characters"`
-		DeviceID string `json:"device_id" validate:"required,uuid" reason:"required:Device ID is required;uuid:Device ID must be a valid UUID"`
+		Email        string `json:"email" validate:"required,email" reason:"required:Email is required;email:Invalid email address"`
+		FcmToken     string `json:"fcm_token" validate:"required" reason:"required:FCM token is required"`
+		Password     string `json:"password" validate:"required,min=8,max=100" reason:"required:Password is required;min:Password must be at least 8 characters;max:Password must be at most 100 characters"`
+		DeviceID     string `json:"device_id" validate:"required,uuid" reason:"required:Device ID is required;uuid:Device ID must be a valid UUID"`
+		Platform     string `json:"platform" validate:"omitempty,oneof=Android iOS Web" reason:"oneof:Platform must be one of Android, iOS or Web"`
+		PushProvider string `json:"push_provider" validate:"omitempty,oneof=fcm apns" reason:"oneof:Push provider must be one of fcm or apns"`
 	}
 
 	// AccountUnauthRequest represents the request payload for unauthenticating an account by invalidating access and refresh tokens.
@@ -40,4 +44,9 @@ type (
 	AccountRefreshTokenRequest struct {
 		RefreshToken string `json:"refresh_token" validate:"required" reason:"required:Refresh token is required"`
 	}
+
+	// AccountConfirmEmailChangeRequest represents a request to confirm a pending email change using the token sent to the new address.
+	AccountConfirmEmailChangeRequest struct {
+		Token string `form:"token" json:"token" validate:"required" reason:"required:Token is required"`
+	}
 )
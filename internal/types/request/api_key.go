@@ -0,0 +1,12 @@
+package request
+
+import "time"
+
+type (
+	// APIKeyCreateRequest represents a request to create a new API key with a name, scopes and an optional expiry.
+	APIKeyCreateRequest struct {
+		Name      string     `json:"name" validate:"required,min=3,max=100" reason:"required:Name is required;min:Name must be at least 3 characters;max:Name must be at most 100 characters"`
+		Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=account:read notification:read notification:write" reason:"required:Scopes are required;min:At least one scope is required;oneof:Unknown scope"`
+		ExpiresAt *time.Time `json:"expires_at" validate:"omitempty"`
+	}
+)
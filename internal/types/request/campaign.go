@@ -0,0 +1,35 @@
+package request
+
+import "github.com/arifai/zenith/pkg/push"
+
+type (
+	// CampaignCreateRequest represents a request to schedule a push campaign. The audience are the listed accounts, the
+	// accounts matching the filters, written like the filters of the admin account search, or the listed accounts
+	// matching them. StartsAt is a wall clock time in the timezone, or in the timezone of every recipient with
+	// local_time. The copy is either given or rendered from the template in the locale of every recipient.
+	CampaignCreateRequest struct {
+		Name            string                     `json:"name" validate:"required,max=255" reason:"required:Name is required;max:Name must be at most 255 characters"`
+		Category        string                     `json:"category" validate:"omitempty,oneof=general account security marketing" reason:"oneof:Category must be one of general, account, security or marketing"`
+		Template        string                     `json:"template" validate:"omitempty,max=100" reason:"max:Template must be at most 100 characters"`
+		Variables       map[string]interface{}     `json:"variables"`
+		Title           string                     `json:"title" validate:"required_without=Template,max=255" reason:"required_without:Title is required without a template;max:Title must be at most 255 characters"`
+		Message         string                     `json:"message" validate:"required_without=Template,max=255" reason:"required_without:Message is required without a template;max:Message must be at most 255 characters"`
+		Image           string                     `json:"image" validate:"omitempty,url" reason:"url:Image must be a valid URL"`
+		Data            map[string]string          `json:"data"`
+		Options         *push.Options              `json:"options"`
+		AccountIDs      []string                   `json:"account_ids" validate:"omitempty,max=10000,dive,uuid" reason:"max:At most 10000 account IDs are allowed;uuid:Every account ID must be a valid UUID"`
+		AudienceFilters []string                   `json:"audience_filters" validate:"omitempty,max=10" reason:"max:At most 10 audience filters are allowed"`
+		StartsAt        string                     `json:"starts_at" validate:"required,datetime=2006-01-02T15:04" reason:"required:Start is required;datetime:Start must be formatted as YYYY-MM-DDTHH:MM"`
+		Timezone        string                     `json:"timezone" validate:"required,timezone" reason:"required:Timezone is required;timezone:Timezone must be an IANA time zone such as Europe/Berlin"`
+		LocalTime       bool                       `json:"local_time"`
+		Recurrence      *CampaignRecurrenceRequest `json:"recurrence"`
+	}
+
+	// CampaignRecurrenceRequest represents how a campaign repeats after its first occurrence.
+	CampaignRecurrenceRequest struct {
+		Frequency string   `json:"frequency" validate:"required,oneof=daily weekly monthly" reason:"required:Frequency is required;oneof:Frequency must be one of daily, weekly or monthly"`
+		Interval  int      `json:"interval" validate:"omitempty,min=1,max=365" reason:"min:Interval must be at least 1;max:Interval must be at most 365"`
+		Weekdays  []string `json:"weekdays" validate:"omitempty,max=7,dive,oneof=monday tuesday wednesday thursday friday saturday sunday" reason:"oneof:Weekdays must be English day names such as monday"`
+		Until     string   `json:"until" validate:"omitempty,datetime=2006-01-02T15:04" reason:"datetime:Until must be formatted as YYYY-MM-DDTHH:MM"`
+	}
+)
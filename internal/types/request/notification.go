@@ -1,8 +1,69 @@
 package request
 
+import (
+	"github.com/arifai/zenith/pkg/common"
+	"github.com/arifai/zenith/pkg/push"
+	"time"
+)
+
 type (
-	// NotificationMarkAsReadRequest represents a request to mark a notification as read.
+	// NotificationMarkAsReadRequest represents a request to mark a notification as read or unread.
 	NotificationMarkAsReadRequest struct {
 		ID string `json:"id" validate:"required,uuid" reason:"required:ID is required;uuid:ID must be a valid UUID"`
 	}
+
+	// NotificationBulkMarkRequest represents a request to set the read state of several notifications at once.
+	NotificationBulkMarkRequest struct {
+		IDs  []string `json:"ids" validate:"required,min=1,max=100,dive,uuid" reason:"required:IDs are required;min:At least one ID is required;max:At most 100 IDs are allowed;uuid:Every ID must be a valid UUID"`
+		Read *bool    `json:"read" validate:"required" reason:"required:Read is required"`
+	}
+
+	// NotificationListRequest represents the query of a notification list, which shows the inbox unless archived is set.
+	NotificationListRequest struct {
+		common.Pagination
+		Archived bool `form:"archived" validate:"omitempty"`
+	}
+
+	// NotificationSendRequest represents a request to send a notification to an account. The notification is added to
+	// the inbox of the account and pushed to its devices, using the short description as the body of the push. The
+	// category, general by default, selects the channels from the preferences of the account. The priority, action URL,
+	// actions and expiry are stored with the inbox entry and added to the data of the pushes. With a template the title,
+	// short description and description are rendered from the template with the variables instead, in the locale of
+	// the account.
+	NotificationSendRequest struct {
+		AccountID        string                      `json:"account_id" validate:"required,uuid" reason:"required:Account ID is required;uuid:Account ID must be a valid UUID"`
+		Category         string                      `json:"category" validate:"omitempty,oneof=general account security marketing" reason:"oneof:Category must be one of general, account, security or marketing"`
+		Priority         string                      `json:"priority" validate:"omitempty,oneof=low normal high" reason:"oneof:Priority must be one of low, normal or high"`
+		Template         string                      `json:"template" validate:"omitempty,max=100" reason:"max:Template must be at most 100 characters"`
+		Variables        map[string]interface{}      `json:"variables"`
+		Title            string                      `json:"title" validate:"required_without=Template,max=255" reason:"required_without:Title is required without a template;max:Title must be at most 255 characters"`
+		ShortDescription string                      `json:"short_description" validate:"required_without=Template,max=255" reason:"required_without:Short description is required without a template;max:Short description must be at most 255 characters"`
+		Description      string                      `json:"description" validate:"required_without=Template" reason:"required_without:Description is required without a template"`
+		Image            string                      `json:"image" validate:"omitempty,url" reason:"url:Image must be a valid URL"`
+		ActionURL        string                      `json:"action_url" validate:"omitempty,uri,max=2048" reason:"uri:Action URL must be a valid URI;max:Action URL must be at most 2048 characters"`
+		Actions          []NotificationActionRequest `json:"actions" validate:"omitempty,max=3,dive" reason:"max:At most 3 actions are allowed"`
+		ExpiresAt        *time.Time                  `json:"expires_at"`
+		Data             map[string]string           `json:"data"`
+		Options          *push.Options               `json:"options"`
+	}
+
+	// NotificationActionRequest represents an action button of a notification to send.
+	NotificationActionRequest struct {
+		Label   string `json:"label" validate:"required,max=64" reason:"required:Action label is required;max:Action label must be at most 64 characters"`
+		Payload string `json:"payload" validate:"required,max=1024" reason:"required:Action payload is required;max:Action payload must be at most 1024 characters"`
+	}
+
+	// PushBroadcastRequest represents a request to broadcast a push to the devices subscribed to a topic, or to the
+	// devices matching a condition over topics, such as "'news' in topics && ('sports' in topics || 'music' in topics)".
+	// The platform, Android by default, selects which of the options the push service applies.
+	PushBroadcastRequest struct {
+		Topic     string            `json:"topic" validate:"required_without=Condition,excluded_with=Condition" reason:"required_without:Topic or condition is required;excluded_with:Only one of topic and condition is allowed"`
+		Condition string            `json:"condition" validate:"required_without=Topic,max=1024" reason:"required_without:Topic or condition is required;max:Condition must be at most 1024 characters"`
+		Platform  string            `json:"platform" validate:"omitempty,oneof=Android iOS Web" reason:"oneof:Platform must be one of Android, iOS or Web"`
+		Title     string            `json:"title" validate:"required,max=255" reason:"required:Title is required;max:Title must be at most 255 characters"`
+		Message   string            `json:"message" validate:"required,max=255" reason:"required:Message is required;max:Message must be at most 255 characters"`
+		Image     string            `json:"image" validate:"omitempty,url" reason:"url:Image must be a valid URL"`
+		Data      map[string]string `json:"data"`
+		Options   *push.Options     `json:"options"`
+	}
 )
@@ -0,0 +1,21 @@
+package request
+
+import "github.com/arifai/zenith/internal/model"
+
+type (
+	// NotificationPreferenceUpdateRequest represents a request to replace the notification preferences of an account.
+	// Categories left out use the default channels, quiet hours are disabled when omitted and templated notifications
+	// use the default locale when no locale is given.
+	NotificationPreferenceUpdateRequest struct {
+		Timezone   string                             `json:"timezone" validate:"required,timezone" reason:"required:Timezone is required;timezone:Timezone must be an IANA time zone such as Europe/Berlin"`
+		Locale     string                             `json:"locale" validate:"omitempty,bcp47_language_tag" reason:"bcp47_language_tag:Locale must be a language tag such as en or pt-BR"`
+		QuietHours *QuietHoursRequest                 `json:"quiet_hours"`
+		Categories map[string]model.ChannelPreference `json:"categories" validate:"omitempty,dive,keys,oneof=general account security marketing,endkeys" reason:"oneof:Category must be one of general, account, security or marketing"`
+	}
+
+	// QuietHoursRequest represents the daily quiet hours of an account as wall clock times, which may span midnight.
+	QuietHoursRequest struct {
+		Start string `json:"start" validate:"required,datetime=15:04,nefield=End" reason:"required:Start is required;datetime:Start must be formatted as HH:MM;nefield:Start and end must differ"`
+		End   string `json:"end" validate:"required,datetime=15:04" reason:"required:End is required;datetime:End must be formatted as HH:MM"`
+	}
+)
@@ -0,0 +1,14 @@
+package request
+
+type (
+	// NotificationTemplateSaveRequest represents a request to create or replace the template with the given name and
+	// locale. The title, short description and description are Go text/template templates.
+	NotificationTemplateSaveRequest struct {
+		Name             string `json:"name" validate:"required,max=100" reason:"required:Name is required;max:Name must be at most 100 characters"`
+		Locale           string `json:"locale" validate:"required,bcp47_language_tag" reason:"required:Locale is required;bcp47_language_tag:Locale must be a language tag such as en or pt-BR"`
+		Category         string `json:"category" validate:"omitempty,oneof=general account security marketing" reason:"oneof:Category must be one of general, account, security or marketing"`
+		Title            string `json:"title" validate:"required" reason:"required:Title is required"`
+		ShortDescription string `json:"short_description" validate:"required" reason:"required:Short description is required"`
+		Description      string `json:"description" validate:"required" reason:"required:Description is required"`
+	}
+)
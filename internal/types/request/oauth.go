@@ -0,0 +1,50 @@
+package request
+
+type (
+	// OAuthClientCreateRequest represents a request to register a new OAuth2 client.
+	OAuthClientCreateRequest struct {
+		Name         string   `json:"name" validate:"required,min=3,max=100" reason:"required:Name is required;min:Name must be at least 3 characters;max:Name must be at most 100 characters"`
+		RedirectURIs []string `json:"redirect_uris" validate:"required,min=1,dive,url" reason:"required:Redirect URIs are required;min:At least one redirect URI is required;url:Redirect URI must be a valid URL"`
+		Scopes       []string `json:"scopes" validate:"required,min=1,dive,oneof=account:read notification:read notification:write" reason:"required:Scopes are required;min:At least one scope is required;oneof:Unknown scope"`
+		Confidential bool     `json:"confidential"`
+	}
+
+	// OAuthAuthorizeRequest represents an authorization request of the authorization code grant (RFC 6749 section 4.1.1)
+	// with the PKCE extension (RFC 7636).
+	OAuthAuthorizeRequest struct {
+		ResponseType        string `form:"response_type" json:"response_type" validate:"required" reason:"required:Response type is required"`
+		ClientID            string `form:"client_id" json:"client_id" validate:"required,uuid" reason:"required:Client ID is required;uuid:Client ID must be a valid UUID"`
+		RedirectURI         string `form:"redirect_uri" json:"redirect_uri" validate:"required,url" reason:"required:Redirect URI is required;url:Redirect URI must be a valid URL"`
+		Scope               string `form:"scope" json:"scope" validate:"required" reason:"required:Scope is required"`
+		State               string `form:"state" json:"state" validate:"omitempty,max=500" reason:"max:State must be at most 500 characters"`
+		CodeChallenge       string `form:"code_challenge" json:"code_challenge" validate:"omitempty,min=43,max=128" reason:"min:Code challenge must be at least 43 characters;max:Code challenge must be at most 128 characters"`
+		CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method" validate:"omitempty"`
+	}
+
+	// OAuthAuthorizeDecisionRequest represents the decision of an account on the consent screen.
+	OAuthAuthorizeDecisionRequest struct {
+		OAuthAuthorizeRequest
+		Approve bool `json:"approve"`
+	}
+
+	// OAuthTokenRequest represents a request to the token endpoint (RFC 6749 section 3.2). Depending on the grant type,
+	// only some of the fields are required.
+	OAuthTokenRequest struct {
+		GrantType    string `form:"grant_type"`
+		Code         string `form:"code"`
+		RedirectURI  string `form:"redirect_uri"`
+		CodeVerifier string `form:"code_verifier"`
+		RefreshToken string `form:"refresh_token"`
+		Scope        string `form:"scope"`
+		ClientID     string `form:"client_id"`
+		ClientSecret string `form:"client_secret"`
+	}
+
+	// OAuthTokenActionRequest represents a request to the revocation (RFC 7009) or introspection (RFC 7662) endpoint.
+	OAuthTokenActionRequest struct {
+		Token         string `form:"token"`
+		TokenTypeHint string `form:"token_type_hint"`
+		ClientID      string `form:"client_id"`
+		ClientSecret  string `form:"client_secret"`
+	}
+)
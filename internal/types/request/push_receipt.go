@@ -0,0 +1,24 @@
+package request
+
+import "time"
+
+type (
+	// PushReceiptRequest represents the events a device reports for the push notifications it received, together with
+	// the device they were reported from. Clients may queue events while offline and report them at once.
+	PushReceiptRequest struct {
+		Events      []PushEventRequest `json:"events" validate:"required,min=1,max=100,dive" reason:"required:Events are required;min:At least one event is required;max:At most 100 events are allowed"`
+		DeviceID    string             `json:"device_id" validate:"omitempty,uuid" reason:"uuid:Device ID must be a valid UUID"`
+		Platform    string             `json:"platform" validate:"omitempty,oneof=Android iOS Web" reason:"oneof:Platform must be one of Android, iOS or Web"`
+		DeviceModel string             `json:"device_model" validate:"omitempty,max=64" reason:"max:Device model must be at most 64 characters"`
+		OSVersion   string             `json:"os_version" validate:"omitempty,max=64" reason:"max:OS version must be at most 64 characters"`
+		AppVersion  string             `json:"app_version" validate:"omitempty,max=64" reason:"max:App version must be at most 64 characters"`
+	}
+
+	// PushEventRequest represents an event of a push notification, at the time it occurred on the device, the time of
+	// the request by default.
+	PushEventRequest struct {
+		PushID     string     `json:"push_id" validate:"required,uuid" reason:"required:Push ID is required;uuid:Push ID must be a valid UUID"`
+		Event      string     `json:"event" validate:"required,oneof=delivered opened dismissed" reason:"required:Event is required;oneof:Event must be one of delivered, opened or dismissed"`
+		OccurredAt *time.Time `json:"occurred_at"`
+	}
+)
@@ -0,0 +1,17 @@
+package request
+
+type (
+	// WebPushSubscriptionRequest represents the Web Push subscription of a browser, as returned by
+	// PushSubscription.toJSON(), together with the device it belongs to.
+	WebPushSubscriptionRequest struct {
+		DeviceID string             `json:"device_id" validate:"required,uuid" reason:"required:Device ID is required;uuid:Device ID must be a valid UUID"`
+		Endpoint string             `json:"endpoint" validate:"required,url,startswith=https://" reason:"required:Endpoint is required;url:Endpoint must be a valid URL;startswith:Endpoint must use HTTPS"`
+		Keys     WebPushKeysRequest `json:"keys" validate:"required"`
+	}
+
+	// WebPushKeysRequest represents the message encryption keys of a Web Push subscription.
+	WebPushKeysRequest struct {
+		P256dh string `json:"p256dh" validate:"required" reason:"required:P-256 public key is required"`
+		Auth   string `json:"auth" validate:"required" reason:"required:Authentication secret is required"`
+	}
+)
@@ -1,9 +1,24 @@
 package response
 
+import (
+	"github.com/arifai/zenith/internal/model"
+	"github.com/google/uuid"
+)
+
 type (
 	// AccountAuthResponse represents the structure of the authentication response containing AccessToken and RefreshToken.
 	AccountAuthResponse struct {
 		AccessToken  string `json:"access_token"`
 		RefreshToken string `json:"refresh_token"`
 	}
+
+	// AccountSessionResponse describes how a request was authenticated: anonymously, with the access token of an
+	// account or with the access token of an OAuth2 client, along with the account and the scopes of the client.
+	AccountSessionResponse struct {
+		Authenticated bool             `json:"authenticated"`
+		Method        model.AuthMethod `json:"method"`
+		AccountID     *uuid.UUID       `json:"account_id,omitempty"`
+		ClientID      *uuid.UUID       `json:"client_id,omitempty"`
+		Scopes        []string         `json:"scopes,omitempty"`
+	}
 )
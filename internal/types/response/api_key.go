@@ -0,0 +1,11 @@
+package response
+
+import "github.com/arifai/zenith/internal/model"
+
+type (
+	// APIKeyCreateResponse represents a newly created API key. The plaintext Key is only returned once.
+	APIKeyCreateResponse struct {
+		*model.APIKey
+		Key string `json:"key"`
+	}
+)
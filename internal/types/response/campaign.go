@@ -0,0 +1,11 @@
+package response
+
+import "github.com/arifai/zenith/internal/model"
+
+type (
+	// CampaignResponse represents a campaign together with the delivery stats of its pushes.
+	CampaignResponse struct {
+		*model.Campaign
+		Stats *model.CampaignStats `json:"stats"`
+	}
+)
@@ -0,0 +1,56 @@
+package response
+
+import "github.com/arifai/zenith/internal/model"
+
+type (
+	// OAuthClientCreateResponse represents a newly registered OAuth2 client. The ClientSecret of confidential clients
+	// is only returned once.
+	OAuthClientCreateResponse struct {
+		*model.OAuthClient
+		ClientSecret string `json:"client_secret,omitempty"`
+	}
+
+	// OAuthConsentResponse represents the data shown on the consent screen of an authorization request.
+	OAuthConsentResponse struct {
+		ClientID    string   `json:"client_id"`
+		ClientName  string   `json:"client_name"`
+		Scopes      []string `json:"scopes"`
+		RedirectURI string   `json:"redirect_uri"`
+		State       string   `json:"state"`
+		Consented   bool     `json:"consented"`
+	}
+
+	// OAuthAuthorizeResponse represents the URI the user agent must be redirected to after the consent decision.
+	OAuthAuthorizeResponse struct {
+		RedirectURI string `json:"redirect_uri"`
+	}
+
+	// OAuthTokenResponse represents a successful response of the token endpoint (RFC 6749 section 5.1).
+	OAuthTokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+		RefreshToken string `json:"refresh_token,omitempty"`
+		Scope        string `json:"scope"`
+	}
+
+	// OAuthIntrospectionResponse represents a response of the introspection endpoint (RFC 7662 section 2.2).
+	// Only Active is set for inactive tokens.
+	OAuthIntrospectionResponse struct {
+		Active    bool   `json:"active"`
+		Scope     string `json:"scope,omitempty"`
+		ClientID  string `json:"client_id,omitempty"`
+		Sub       string `json:"sub,omitempty"`
+		TokenType string `json:"token_type,omitempty"`
+		Exp       int64  `json:"exp,omitempty"`
+		Iat       int64  `json:"iat,omitempty"`
+		Nbf       int64  `json:"nbf,omitempty"`
+		Jti       string `json:"jti,omitempty"`
+	}
+
+	// OAuthErrorResponse represents an error response of the OAuth2 endpoints (RFC 6749 section 5.2).
+	OAuthErrorResponse struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description,omitempty"`
+	}
+)
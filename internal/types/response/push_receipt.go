@@ -0,0 +1,10 @@
+package response
+
+type (
+	// PushReceiptResponse represents how many of the reported events were recorded. Events for unknown pushes, pushes
+	// of other accounts and events reported before are ignored.
+	PushReceiptResponse struct {
+		Recorded int64 `json:"recorded"`
+		Ignored  int64 `json:"ignored"`
+	}
+)
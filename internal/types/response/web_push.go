@@ -0,0 +1,8 @@
+package response
+
+type (
+	// WebPushKeyResponse represents the VAPID public key browsers pass as applicationServerKey when subscribing.
+	WebPushKeyResponse struct {
+		PublicKey string `json:"public_key"`
+	}
+)
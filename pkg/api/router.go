@@ -8,9 +8,17 @@ import (
 )
 
 // SetupRouter initializes the main router and sets up all the routes and groups under "/api/v1".
-func SetupRouter(engine *gin.Engine, accountHandler *handler.AccountHandler, notificationHandler *handler.NotificationHandler, middleware *middleware.StrictAuthMiddleware) *gin.Engine {
+func SetupRouter(engine *gin.Engine, accountHandler *handler.AccountHandler, notificationHandler *handler.NotificationHandler, apiKeyHandler *handler.APIKeyHandler, oauthHandler *handler.OAuthHandler, topicHandler *handler.TopicHandler, webPushHandler *handler.WebPushHandler, preferenceHandler *handler.NotificationPreferenceHandler, templateHandler *handler.NotificationTemplateHandler, campaignHandler *handler.CampaignHandler, receiptHandler *handler.PushReceiptHandler, middleware *middleware.StrictAuthMiddleware, apiKeyMiddleware *middleware.APIKeyAuthMiddleware) *gin.Engine {
 	apiV1 := engine.Group("/api/v1")
-	router.AccountRouter(apiV1, accountHandler, middleware)
-	router.NotificationRouter(apiV1, notificationHandler, middleware)
+	router.AccountRouter(apiV1, accountHandler, middleware, apiKeyMiddleware)
+	router.NotificationRouter(apiV1, notificationHandler, middleware, apiKeyMiddleware)
+	router.APIKeyRouter(apiV1, apiKeyHandler, middleware)
+	router.OAuthRouter(apiV1, oauthHandler, middleware)
+	router.TopicRouter(apiV1, topicHandler, middleware, apiKeyMiddleware)
+	router.WebPushRouter(apiV1, webPushHandler, middleware)
+	router.NotificationPreferenceRouter(apiV1, preferenceHandler, middleware)
+	router.NotificationTemplateRouter(apiV1, templateHandler, apiKeyMiddleware)
+	router.CampaignRouter(apiV1, campaignHandler, apiKeyMiddleware)
+	router.PushReceiptRouter(apiV1, receiptHandler, middleware, apiKeyMiddleware)
 	return engine
 }
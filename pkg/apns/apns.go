@@ -0,0 +1,249 @@
+package apns
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	ProductionEndpoint = "https://api.push.apple.com"
+	SandboxEndpoint    = "https://api.sandbox.push.apple.com"
+)
+
+type (
+	// Config contains the settings of a Client.
+	Config struct {
+		// KeyFile is the path of the .p8 signing key, KeyID its identifier and TeamID the team that owns it.
+		KeyFile string
+		KeyID   string
+		TeamID  string
+
+		// Topic is the bundle ID of the app receiving the notifications.
+		Topic string
+
+		// Production selects the production endpoint instead of the sandbox endpoint used by development builds.
+		Production bool
+
+		// Endpoint overrides the endpoint selected by Production, for example to send to a local fake server.
+		Endpoint string
+
+		// HTTPClient overrides the HTTP/2 client used to reach the endpoint. The default client gives up on a request
+		// after requestTimeout, even when the context of Send has a later deadline.
+		HTTPClient *http.Client
+	}
+
+	// Client delivers notifications directly to APNs with token-based authentication, implementing push.Provider.
+	Client struct {
+		endpoint string
+		topic    string
+		http     *http.Client
+		signer   *tokenSigner
+	}
+
+	// errorResponse is the body of a rejected request.
+	errorResponse struct {
+		Reason string `json:"reason"`
+	}
+)
+
+// requestTimeout bounds a single request to APNs when the context has no deadline.
+const requestTimeout = time.Second * 30
+
+var (
+	// invalidTokenReasons are the reasons that mean the device token is dead and must be pruned.
+	invalidTokenReasons = map[string]bool{
+		"BadDeviceToken":         true,
+		"DeviceTokenNotForTopic": true,
+		"ExpiredToken":           true,
+		"Unregistered":           true,
+	}
+
+	// transientReasons are the reasons that may succeed when retried later. Every other reason is permanent.
+	transientReasons = map[string]bool{
+		"ExpiredProviderToken":        true,
+		"InternalServerError":         true,
+		"ServiceUnavailable":          true,
+		"Shutdown":                    true,
+		"TooManyProviderTokenUpdates": true,
+		"TooManyRequests":             true,
+	}
+)
+
+// New creates a Client from the given Config, loading its signing key.
+func New(config Config) (*Client, error) {
+	key, err := loadKey(config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := config.Endpoint
+	if endpoint == "" && config.Production {
+		endpoint = ProductionEndpoint
+	} else if endpoint == "" {
+		endpoint = SandboxEndpoint
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = &http.Client{Transport: &http.Transport{ForceAttemptHTTP2: true}, Timeout: requestTimeout}
+	}
+
+	return &Client{
+		endpoint: endpoint,
+		topic:    config.Topic,
+		http:     client,
+		signer:   &tokenSigner{key: key, keyID: config.KeyID, teamID: config.TeamID},
+	}, nil
+}
+
+// Send delivers the message to the device token of the message. It returns the apns-id assigned to the notification.
+// Rejections are classified by their reason: dead device tokens are permanent token errors, throttling and outages are
+// transient and anything else is permanent. A device token that is not hexadecimal is a token error without a request.
+func (c *Client) Send(ctx context.Context, message *push.Message) (string, error) {
+	if message.Token == "" {
+		return "", &push.Error{Err: errormessage.ErrPushTargetUnsupported, Permanent: true}
+	}
+	if _, err := hex.DecodeString(message.Token); err != nil {
+		return "", &push.Error{Err: errormessage.ErrInvalidAPNsDeviceToken, Permanent: true, InvalidToken: true}
+	}
+
+	body, err := json.Marshal(payload(message))
+	if err != nil {
+		return "", &push.Error{Err: err, Permanent: true}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/3/device/"+message.Token, bytes.NewReader(body))
+	if err != nil {
+		return "", &push.Error{Err: err, Permanent: true}
+	}
+
+	token, err := c.signer.Token(time.Now())
+	if err != nil {
+		return "", &push.Error{Err: err, Permanent: true}
+	}
+
+	request.Header.Set("authorization", "bearer "+token)
+	request.Header.Set("content-type", "application/json")
+	for name, value := range c.headers(message.Options) {
+		request.Header.Set(name, value)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return "", &push.Error{Err: err}
+	}
+	defer response.Body.Close()
+
+	apnsID := response.Header.Get("apns-id")
+	if response.StatusCode == http.StatusOK {
+		return apnsID, nil
+	}
+
+	var rejection errorResponse
+	if data, err := io.ReadAll(io.LimitReader(response.Body, 4096)); err == nil {
+		_ = json.Unmarshal(data, &rejection)
+	}
+
+	if rejection.Reason == "ExpiredProviderToken" {
+		c.signer.Invalidate(token)
+	}
+
+	return apnsID, classifyError(response.StatusCode, rejection.Reason)
+}
+
+// headers returns the APNs request headers of a message.
+func (c *Client) headers(options *push.Options) map[string]string {
+	headers := map[string]string{"apns-push-type": "alert"}
+	if c.topic != "" {
+		headers["apns-topic"] = c.topic
+	}
+
+	if options == nil {
+		return headers
+	}
+
+	if options.CollapseKey != "" {
+		headers["apns-collapse-id"] = options.CollapseKey
+	}
+	switch options.Priority {
+	case push.PriorityHigh:
+		headers["apns-priority"] = "10"
+	case push.PriorityNormal:
+		headers["apns-priority"] = "5"
+	}
+	if options.TimeToLive != nil {
+		expiration := int64(0)
+		if *options.TimeToLive > 0 {
+			expiration = time.Now().Add(time.Duration(*options.TimeToLive) * time.Second).Unix()
+		}
+		headers["apns-expiration"] = strconv.FormatInt(expiration, 10)
+	}
+
+	return headers
+}
+
+// payload builds the JSON payload of a message. The data of the message and its image are sent as custom keys next to
+// the aps dictionary, the image is shown by a notification service extension of the app.
+func payload(message *push.Message) map[string]interface{} {
+	aps := map[string]interface{}{
+		"alert": map[string]string{"title": message.Title, "body": message.Body},
+	}
+
+	if options := message.Options; options != nil {
+		if options.Badge != nil {
+			aps["badge"] = *options.Badge
+		}
+		if options.Sound != "" {
+			aps["sound"] = options.Sound
+		}
+		if options.ThreadID != "" {
+			aps["thread-id"] = options.ThreadID
+		}
+		if options.MutableContent {
+			aps["mutable-content"] = 1
+		}
+		if options.ClickAction != "" {
+			aps["category"] = options.ClickAction
+		}
+	}
+
+	body := make(map[string]interface{}, len(message.Data)+2)
+	for key, value := range message.Data {
+		body[key] = value
+	}
+	if message.Image != "" {
+		body["image"] = message.Image
+	}
+	body["aps"] = aps
+
+	return body
+}
+
+// classifyError maps a rejected request to a push.Error by its status code and reason.
+func classifyError(status int, reason string) error {
+	err := fmt.Errorf("%w: %d %s", errormessage.ErrAPNsRejected, status, reason)
+
+	switch {
+	case invalidTokenReasons[reason] || status == http.StatusGone:
+		return &push.Error{Err: err, Permanent: true, InvalidToken: true}
+	case transientReasons[reason] || (reason == "" && status >= http.StatusInternalServerError):
+		return &push.Error{Err: err}
+	default:
+		return &push.Error{Err: err, Permanent: true}
+	}
+}
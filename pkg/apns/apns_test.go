@@ -0,0 +1,282 @@
+package apns
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testKeyID       = "ABC123DEFG"
+	testTeamID      = "DEF123GHIJ"
+	testTopic       = "com.example.app"
+	testDeviceToken = "00fc13adff785122b4ad28809a3420982341241421348097878e577c991de8f0"
+)
+
+// fakeAPNs is a stand-in for APNs answering every request with the next of its responses, repeating the last one.
+type fakeAPNs struct {
+	mu        sync.Mutex
+	responses []fakeResponse
+	requests  []*recordedRequest
+}
+
+// fakeResponse is an answer of fakeAPNs.
+type fakeResponse struct {
+	status int
+	reason string
+}
+
+// recordedRequest is a request received by fakeAPNs.
+type recordedRequest struct {
+	path   string
+	header http.Header
+	body   map[string]interface{}
+}
+
+func (f *fakeAPNs) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.requests = append(f.requests, &recordedRequest{path: r.URL.Path, header: r.Header.Clone(), body: body})
+
+	response := f.responses[min(len(f.requests), len(f.responses))-1]
+	w.Header().Set("apns-id", "apns-id-1")
+	w.WriteHeader(response.status)
+	if response.reason != "" {
+		_ = json.NewEncoder(w).Encode(errorResponse{Reason: response.reason})
+	}
+}
+
+func TestSendSignsProviderToken(t *testing.T) {
+	fake := &fakeAPNs{responses: []fakeResponse{{status: http.StatusOK}}}
+	client, key := newTestClient(t, fake)
+
+	badge := 3
+	apnsID, err := client.Send(context.Background(), &push.Message{
+		Token:   testDeviceToken,
+		Title:   "Hello",
+		Body:    "World",
+		Data:    map[string]string{"push_id": "1"},
+		Options: &push.Options{Priority: push.PriorityHigh, CollapseKey: "news", Badge: &badge},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if apnsID != "apns-id-1" {
+		t.Errorf("Send() = %q, want %q", apnsID, "apns-id-1")
+	}
+
+	request := fake.requests[0]
+	if request.path != "/3/device/"+testDeviceToken {
+		t.Errorf("path = %q, want %q", request.path, "/3/device/"+testDeviceToken)
+	}
+	for name, want := range map[string]string{
+		"apns-topic":       testTopic,
+		"apns-push-type":   "alert",
+		"apns-priority":    "10",
+		"apns-collapse-id": "news",
+	} {
+		if got := request.header.Get(name); got != want {
+			t.Errorf("header %s = %q, want %q", name, got, want)
+		}
+	}
+
+	header, claims := verifyToken(t, request.header.Get("authorization"), &key.PublicKey)
+	if header["alg"] != "ES256" || header["kid"] != testKeyID {
+		t.Errorf("token header = %v, want alg ES256 and kid %s", header, testKeyID)
+	}
+	if claims["iss"] != testTeamID || claims["iat"] == nil {
+		t.Errorf("token claims = %v, want iss %s and iat", claims, testTeamID)
+	}
+
+	aps, _ := request.body["aps"].(map[string]interface{})
+	alert, _ := aps["alert"].(map[string]interface{})
+	if alert["title"] != "Hello" || alert["body"] != "World" || aps["badge"] != float64(3) {
+		t.Errorf("aps = %v, want the title, body and badge of the message", aps)
+	}
+	if request.body["push_id"] != "1" {
+		t.Errorf("body = %v, want the data of the message next to aps", request.body)
+	}
+}
+
+func TestSendClassifiesRejections(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     fakeResponse
+		permanent    bool
+		invalidToken bool
+	}{
+		{"unregistered", fakeResponse{http.StatusGone, "Unregistered"}, true, true},
+		{"bad device token", fakeResponse{http.StatusBadRequest, "BadDeviceToken"}, true, true},
+		{"payload too large", fakeResponse{http.StatusRequestEntityTooLarge, "PayloadTooLarge"}, true, false},
+		{"too many requests", fakeResponse{http.StatusTooManyRequests, "TooManyRequests"}, false, false},
+		{"internal server error", fakeResponse{http.StatusInternalServerError, "InternalServerError"}, false, false},
+		{"service unavailable", fakeResponse{http.StatusServiceUnavailable, "ServiceUnavailable"}, false, false},
+		{"bad gateway without reason", fakeResponse{http.StatusBadGateway, ""}, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, _ := newTestClient(t, &fakeAPNs{responses: []fakeResponse{test.response}})
+
+			_, err := client.Send(context.Background(), &push.Message{Token: testDeviceToken, Title: "Hello"})
+			if !errors.Is(err, errormessage.ErrAPNsRejected) {
+				t.Fatalf("Send() error = %v, want %v", err, errormessage.ErrAPNsRejected)
+			}
+			if push.IsPermanent(err) != test.permanent || push.IsInvalidToken(err) != test.invalidToken {
+				t.Errorf("Send() error = %v, want permanent %v and invalid token %v", err, test.permanent, test.invalidToken)
+			}
+		})
+	}
+}
+
+func TestSendRenewsExpiredProviderToken(t *testing.T) {
+	fake := &fakeAPNs{responses: []fakeResponse{{http.StatusForbidden, "ExpiredProviderToken"}, {status: http.StatusOK}}}
+	client, _ := newTestClient(t, fake)
+	message := &push.Message{Token: testDeviceToken, Title: "Hello"}
+
+	if _, err := client.Send(context.Background(), message); err == nil || push.IsPermanent(err) {
+		t.Fatalf("Send() error = %v, want a transient error", err)
+	}
+	if _, err := client.Send(context.Background(), message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if fake.requests[0].header.Get("authorization") == fake.requests[1].header.Get("authorization") {
+		t.Error("the expired provider token was sent again")
+	}
+}
+
+func TestSendRejectsTopics(t *testing.T) {
+	client, _ := newTestClient(t, &fakeAPNs{responses: []fakeResponse{{status: http.StatusOK}}})
+
+	_, err := client.Send(context.Background(), &push.Message{Topic: "news", Title: "Hello"})
+	if !errors.Is(err, errormessage.ErrPushTargetUnsupported) || !push.IsPermanent(err) {
+		t.Fatalf("Send() error = %v, want a permanent %v", err, errormessage.ErrPushTargetUnsupported)
+	}
+}
+
+func TestSendRejectsInvalidDeviceTokens(t *testing.T) {
+	fake := &fakeAPNs{responses: []fakeResponse{{status: http.StatusOK}}}
+	client, _ := newTestClient(t, fake)
+
+	for _, token := range []string{"not-hex", "../../3/device/" + testDeviceToken, testDeviceToken + "?x=1", "abc"} {
+		_, err := client.Send(context.Background(), &push.Message{Token: token, Title: "Hello"})
+		if !errors.Is(err, errormessage.ErrInvalidAPNsDeviceToken) || !push.IsInvalidToken(err) {
+			t.Errorf("Send(%q) error = %v, want an invalid token %v", token, err, errormessage.ErrInvalidAPNsDeviceToken)
+		}
+	}
+
+	if len(fake.requests) != 0 {
+		t.Errorf("sent %d requests, want none", len(fake.requests))
+	}
+}
+
+func TestNewRejectsInvalidKey(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "key.p8")
+	if err := os.WriteFile(file, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(Config{KeyFile: file}); !errors.Is(err, errormessage.ErrSigningKeyNotPEM) {
+		t.Fatalf("New() error = %v, want %v", err, errormessage.ErrSigningKeyNotPEM)
+	}
+}
+
+// newTestClient starts an HTTP/2 TLS server serving the fake and creates a Client sending to it with a fresh signing
+// key, which it returns too.
+func newTestClient(t *testing.T, fake *fakeAPNs) (*Client, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(fake)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "AuthKey_"+testKeyID+".p8")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := New(Config{
+		KeyFile:    file,
+		KeyID:      testKeyID,
+		TeamID:     testTeamID,
+		Topic:      testTopic,
+		Endpoint:   server.URL,
+		HTTPClient: server.Client(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client, key
+}
+
+// verifyToken checks the ES256 signature of the bearer token in an authorization header and returns its decoded header
+// and claims.
+func verifyToken(t *testing.T, authorization string, key *ecdsa.PublicKey) (map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+
+	token, ok := strings.CutPrefix(authorization, "bearer ")
+	if !ok {
+		t.Fatalf("authorization = %q, want a bearer token", authorization)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token = %q, want three parts", token)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(signature) != 64 {
+		t.Fatalf("signature = %q, want 64 bytes of base64url", parts[2])
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		t.Fatal("token signature does not verify with the signing key")
+	}
+
+	decode := func(part string) map[string]interface{} {
+		data, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(data, &fields); err != nil {
+			t.Fatal(err)
+		}
+		return fields
+	}
+
+	return decode(parts[0]), decode(parts[1])
+}
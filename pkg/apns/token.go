@@ -0,0 +1,86 @@
+package apns
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/arifai/zenith/pkg/crypto"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"os"
+	"sync"
+	"time"
+)
+
+// tokenLifetime is how long a provider token is reused. APNs rejects tokens older than an hour and throttles providers
+// that refresh their token more often than every 20 minutes.
+const tokenLifetime = time.Minute * 50
+
+// tokenSigner issues the ES256 provider tokens used for token-based authentication with APNs and caches them for
+// tokenLifetime.
+type tokenSigner struct {
+	key    *ecdsa.PrivateKey
+	keyID  string
+	teamID string
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// loadKey reads the PKCS #8 encoded .p8 signing key downloaded from the Apple developer account.
+func loadKey(file string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errormessage.ErrSigningKeyNotPEM
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdsaKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errormessage.ErrSigningKeyNotECDSA
+	}
+
+	return ecdsaKey, nil
+}
+
+// Token returns the cached provider token, signing a new one once the cached token has expired.
+func (s *tokenSigner) Token(now time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && now.Sub(s.issuedAt) < tokenLifetime {
+		return s.token, nil
+	}
+
+	token, err := s.sign(now)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.issuedAt = token, now
+
+	return token, nil
+}
+
+// Invalidate drops the cached token if it is still the given token, so the next request signs a new one.
+func (s *tokenSigner) Invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == token {
+		s.token = ""
+	}
+}
+
+// sign creates a provider token carrying the team ID as issuer and the key ID in its header.
+func (s *tokenSigner) sign(now time.Time) (string, error) {
+	return crypto.SignES256JWT(s.key, map[string]string{"kid": s.keyID}, map[string]interface{}{"iss": s.teamID, "iat": now.Unix()})
+}
@@ -0,0 +1,178 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"slices"
+	"strings"
+	"time"
+)
+
+type (
+	// Cursor is a position in a list ordered by creation time and ID, used for keyset pagination. Desc keeps the
+	// direction of the list it was taken from and Before selects the entries preceding the position instead of those
+	// following it.
+	Cursor struct {
+		CreatedAt string    `json:"t"`
+		ID        uuid.UUID `json:"i"`
+		Desc      bool      `json:"d,omitempty"`
+		Before    bool      `json:"b,omitempty"`
+	}
+
+	// CursorCodec encodes cursors as opaque strings signed with HMAC-SHA256, so clients can neither read nor forge them.
+	CursorCodec struct {
+		secret []byte
+	}
+)
+
+// cursorTimeLayout keeps the wall clock of a timestamp column with its full precision. Cursors compare against the
+// column as a literal of its own type, so the time zone of the database session does not shift them.
+const cursorTimeLayout = "2006-01-02T15:04:05.999999"
+
+// NewCursor creates the cursor of an entry with the given creation time and ID.
+func NewCursor(createdAt time.Time, id uuid.UUID, desc, before bool) *Cursor {
+	return &Cursor{CreatedAt: createdAt.Format(cursorTimeLayout), ID: id, Desc: desc, Before: before}
+}
+
+// NewCursorCodec creates a CursorCodec signing with the given secret.
+func NewCursorCodec(secret string) *CursorCodec {
+	return &CursorCodec{secret: []byte(secret)}
+}
+
+// Encode returns the opaque form of a cursor, or an empty string for a nil cursor.
+func (c *CursorCodec) Encode(cursor *Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return ""
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded))
+}
+
+// Decode verifies and decodes an opaque cursor. An empty value decodes to a nil cursor, cursors that were tampered with
+// or not issued with the same secret are rejected.
+func (c *CursorCodec) Decode(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	encoded, signature, found := strings.Cut(value, ".")
+	if !found {
+		return nil, errormessage.ErrInvalidCursor
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(encoded)) {
+		return nil, errormessage.ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errormessage.ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, errormessage.ErrInvalidCursor
+	}
+	if _, err := time.Parse(cursorTimeLayout, cursor.CreatedAt); err != nil {
+		return nil, errormessage.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// sign computes the signature of an encoded cursor.
+func (c *CursorCodec) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// Keyset applies keyset pagination on (created_at, id) to a GORM query, starting after or before the cursor in the
+// direction it was taken in, and searches and filters like Paginate. The sort order of the query is replaced by the
+// keyset. One entry more than the limit is fetched, KeysetPage uses it to tell if the list goes on.
+// Entries before the cursor are fetched in reverse order and put back in order by KeysetPage.
+func Keyset(paging *Pagination, cursor *Cursor, query *ListQuery, columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = Filtered(paging, query, columns...)(db)
+
+		// Walking backwards flips both the comparison and the order.
+		reverse := cursor.Desc != cursor.Before
+		if reverse {
+			db = db.Where("(created_at, id) < (CAST(? AS timestamp), ?)", cursor.CreatedAt, cursor.ID)
+		} else {
+			db = db.Where("(created_at, id) > (CAST(? AS timestamp), ?)", cursor.CreatedAt, cursor.ID)
+		}
+
+		return db.Limit(paging.GetLimit() + 1).Order(keysetOrder(reverse))
+	}
+}
+
+// KeysetPage trims the entries fetched by Keyset to the page and returns the cursors of the pages before and after it,
+// nil at either end of the list. The position function returns the creation time and ID of an entry.
+func KeysetPage[T interface{}](entries []T, paging *Pagination, cursor *Cursor, position func(T) (time.Time, uuid.UUID)) (page []T, prev, next *Cursor) {
+	limit := paging.GetLimit()
+	more := len(entries) > limit
+	if more {
+		entries = entries[:limit]
+	}
+
+	if cursor.Before {
+		slices.Reverse(entries)
+	}
+	if len(entries) == 0 {
+		return entries, nil, nil
+	}
+
+	first, last := entries[0], entries[len(entries)-1]
+	if !cursor.Before || more {
+		createdAt, id := position(first)
+		prev = NewCursor(createdAt, id, cursor.Desc, true)
+	}
+	if cursor.Before || more {
+		createdAt, id := position(last)
+		next = NewCursor(createdAt, id, cursor.Desc, false)
+	}
+
+	return entries, prev, next
+}
+
+// OffsetCursors returns the cursors around a page fetched by offset, so clients can continue with keyset pagination.
+// Pages are only keyed when ordered by creation time.
+func OffsetCursors[T interface{}](entries []T, paging *Pagination, query *ListQuery, count int64, position func(T) (time.Time, uuid.UUID)) (prev, next *Cursor) {
+	keyed, desc := query.SortedBy("created_at")
+	if len(entries) == 0 || !keyed {
+		return nil, nil
+	}
+
+	if paging.GetOffset() > 0 {
+		createdAt, id := position(entries[0])
+		prev = NewCursor(createdAt, id, desc, true)
+	}
+	if int64(paging.GetOffset()+len(entries)) < count {
+		createdAt, id := position(entries[len(entries)-1])
+		next = NewCursor(createdAt, id, desc, false)
+	}
+
+	return prev, next
+}
+
+// keysetOrder orders by the keyset columns.
+func keysetOrder(desc bool) string {
+	if desc {
+		return "created_at DESC, id DESC"
+	}
+
+	return "created_at, id"
+}
@@ -0,0 +1,322 @@
+package common
+
+import (
+	"fmt"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// FieldType is the type of a filterable field, which decides how its values are parsed and which operators apply.
+	FieldType int
+
+	// Field describes a field of a resource that lists may filter and sort by.
+	Field struct {
+		Column   string
+		Type     FieldType
+		Nullable bool
+	}
+
+	// Fields is the allow-list of a resource, mapping the names used in query strings to their fields. Only these
+	// fields are accepted, so column names never come from the request.
+	Fields map[string]Field
+
+	// Operator compares a field with the values of a filter.
+	Operator string
+
+	// Filter is a parsed filter expression with values of the type of its field. The upper bound of between is
+	// exclusive when ExclusiveEnd is set, which is the case for a date standing for the whole day: it is then the
+	// start of the following day.
+	Filter struct {
+		Column       string
+		Operator     Operator
+		Values       []interface{}
+		ExclusiveEnd bool
+	}
+
+	// SortField is a parsed sort key.
+	SortField struct {
+		Column string
+		Desc   bool
+	}
+
+	// ListQuery holds the filters and the sort order of a list, parsed against the allow-list of its resource.
+	ListQuery struct {
+		Filters []Filter
+		Sort    []SortField
+	}
+)
+
+const (
+	StringField FieldType = iota
+	BoolField
+	TimeField
+	UUIDField
+	NumberField
+)
+
+const (
+	OperatorEq      Operator = "eq"
+	OperatorNe      Operator = "ne"
+	OperatorGt      Operator = "gt"
+	OperatorGte     Operator = "gte"
+	OperatorLt      Operator = "lt"
+	OperatorLte     Operator = "lte"
+	OperatorIn      Operator = "in"
+	OperatorBetween Operator = "between"
+	OperatorIsNull  Operator = "is_null"
+
+	// maxFilters, maxFilterValues and maxSortFields bound the size of a query.
+	maxFilters      = 10
+	maxFilterValues = 100
+	maxSortFields   = 3
+
+	// dateLayout is accepted for time values next to RFC 3339, as the start of the day in the local time zone.
+	dateLayout = "2006-01-02"
+)
+
+// ParseQuery parses the filter expressions and the sort order of the pagination against the allow-list. Filters are
+// written as "field:operator:value", where in and between take comma-separated values and is_null takes true or false,
+// e.g. "created_at:between:2024-01-01,2024-01-31", which includes the whole last day. The sort order is a comma-separated list of fields, each prefixed
+// with "-" for descending or "+" for ascending order, or followed by "asc" or "desc"; fields without a direction use
+// the Desc flag. Without a sort order the list is sorted by defaultSort.
+func (p Pagination) ParseQuery(fields Fields, defaultSort string) (*ListQuery, error) {
+	if len(p.Filters) > maxFilters {
+		return nil, fmt.Errorf("%w: at most %d filters are allowed", errormessage.ErrInvalidFilter, maxFilters)
+	}
+
+	query := &ListQuery{}
+	for _, expression := range p.Filters {
+		filter, err := parseFilter(fields, expression)
+		if err != nil {
+			return nil, err
+		}
+		query.Filters = append(query.Filters, *filter)
+	}
+
+	sort := p.Sort
+	if strings.TrimSpace(sort) == "" {
+		sort = defaultSort
+	}
+
+	for _, key := range strings.Split(sort, ",") {
+		sortField, err := parseSortField(fields, strings.TrimSpace(key), p.Desc)
+		if err != nil {
+			return nil, err
+		}
+		query.Sort = append(query.Sort, *sortField)
+	}
+	if len(query.Sort) > maxSortFields {
+		return nil, fmt.Errorf("%w: at most %d sort fields are allowed", errormessage.ErrInvalidSort, maxSortFields)
+	}
+
+	return query, nil
+}
+
+// SortedBy reports whether the list is sorted by the given column alone, ignoring the ID tie-breaker, and in which
+// direction.
+func (q *ListQuery) SortedBy(column string) (sorted, desc bool) {
+	if len(q.Sort) == 0 || q.Sort[0].Column != column {
+		return false, false
+	}
+
+	for _, sortField := range q.Sort[1:] {
+		if sortField.Column != "id" {
+			return false, false
+		}
+	}
+
+	return true, q.Sort[0].Desc
+}
+
+// Where applies the filters of the query to a GORM query.
+func (q *ListQuery) Where(db *gorm.DB) *gorm.DB {
+	for _, filter := range q.Filters {
+		column := clause.Column{Name: filter.Column}
+
+		switch filter.Operator {
+		case OperatorEq:
+			db = db.Where(clause.Eq{Column: column, Value: filter.Values[0]})
+		case OperatorNe:
+			db = db.Where(clause.Neq{Column: column, Value: filter.Values[0]})
+		case OperatorGt:
+			db = db.Where(clause.Gt{Column: column, Value: filter.Values[0]})
+		case OperatorGte:
+			db = db.Where(clause.Gte{Column: column, Value: filter.Values[0]})
+		case OperatorLt:
+			db = db.Where(clause.Lt{Column: column, Value: filter.Values[0]})
+		case OperatorLte:
+			db = db.Where(clause.Lte{Column: column, Value: filter.Values[0]})
+		case OperatorIn:
+			db = db.Where(clause.IN{Column: column, Values: filter.Values})
+		case OperatorBetween:
+			db = db.Where(clause.Gte{Column: column, Value: filter.Values[0]})
+			if filter.ExclusiveEnd {
+				db = db.Where(clause.Lt{Column: column, Value: filter.Values[1]})
+			} else {
+				db = db.Where(clause.Lte{Column: column, Value: filter.Values[1]})
+			}
+		case OperatorIsNull:
+			if filter.Values[0] == true {
+				db = db.Where(clause.Eq{Column: column, Value: nil})
+			} else {
+				db = db.Where(clause.Neq{Column: column, Value: nil})
+			}
+		}
+	}
+
+	return db
+}
+
+// Order applies the sort order of the query to a GORM query. Ties are broken by ID, so pages stay stable between
+// requests.
+func (q *ListQuery) Order(db *gorm.DB) *gorm.DB {
+	tieBreaker := true
+	desc := false
+	for _, sortField := range q.Sort {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: sortField.Column}, Desc: sortField.Desc})
+		if sortField.Column == "id" {
+			tieBreaker = false
+		}
+		desc = sortField.Desc
+	}
+
+	if tieBreaker {
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc})
+	}
+
+	return db
+}
+
+// parseFilter parses a single filter expression.
+func parseFilter(fields Fields, expression string) (*Filter, error) {
+	parts := strings.SplitN(expression, ":", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: %q must be written as field:operator:value", errormessage.ErrInvalidFilter, expression)
+	}
+
+	name, operator, value := parts[0], Operator(parts[1]), parts[2]
+	field, ok := fields[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown field %q", errormessage.ErrInvalidFilter, name)
+	}
+	if !field.supports(operator) {
+		return nil, fmt.Errorf("%w: operator %q is not supported for %q", errormessage.ErrInvalidFilter, operator, name)
+	}
+
+	var rawValues []string
+	switch operator {
+	case OperatorIn:
+		rawValues = strings.Split(value, ",")
+		if len(rawValues) > maxFilterValues {
+			return nil, fmt.Errorf("%w: at most %d values are allowed for %q", errormessage.ErrInvalidFilter, maxFilterValues, name)
+		}
+	case OperatorBetween:
+		rawValues = strings.Split(value, ",")
+		if len(rawValues) != 2 {
+			return nil, fmt.Errorf("%w: between takes two values for %q", errormessage.ErrInvalidFilter, name)
+		}
+	case OperatorIsNull:
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: is_null takes true or false for %q", errormessage.ErrInvalidFilter, name)
+		}
+		return &Filter{Column: field.Column, Operator: operator, Values: []interface{}{isNull}}, nil
+	default:
+		rawValues = []string{value}
+	}
+
+	values := make([]interface{}, 0, len(rawValues))
+	for _, rawValue := range rawValues {
+		parsed, err := field.parse(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid value %q for %q", errormessage.ErrInvalidFilter, rawValue, name)
+		}
+		values = append(values, parsed)
+	}
+
+	filter := &Filter{Column: field.Column, Operator: operator, Values: values}
+	if operator == OperatorBetween && field.Type == TimeField && isDate(strings.TrimSpace(rawValues[1])) {
+		filter.Values[1] = values[1].(time.Time).AddDate(0, 0, 1)
+		filter.ExclusiveEnd = true
+	}
+
+	return filter, nil
+}
+
+// parseSortField parses a single sort key.
+func parseSortField(fields Fields, key string, desc bool) (*SortField, error) {
+	name := key
+	switch {
+	case strings.HasPrefix(key, "-"):
+		name, desc = key[1:], true
+	case strings.HasPrefix(key, "+"):
+		name, desc = key[1:], false
+	case strings.Contains(key, " "):
+		parts := strings.Fields(key)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: %q", errormessage.ErrInvalidSort, key)
+		}
+
+		switch strings.ToLower(parts[1]) {
+		case "asc":
+			desc = false
+		case "desc":
+			desc = true
+		default:
+			return nil, fmt.Errorf("%w: unknown direction %q", errormessage.ErrInvalidSort, parts[1])
+		}
+		name = parts[0]
+	}
+
+	field, ok := fields[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown field %q", errormessage.ErrInvalidSort, name)
+	}
+
+	return &SortField{Column: field.Column, Desc: desc}, nil
+}
+
+// supports reports whether the operator applies to the field.
+func (f Field) supports(operator Operator) bool {
+	switch operator {
+	case OperatorEq, OperatorNe, OperatorIn:
+		return true
+	case OperatorGt, OperatorGte, OperatorLt, OperatorLte, OperatorBetween:
+		return f.Type == TimeField || f.Type == NumberField
+	case OperatorIsNull:
+		return f.Nullable
+	default:
+		return false
+	}
+}
+
+// parse converts a value from the query string to the type of the field.
+func (f Field) parse(value string) (interface{}, error) {
+	switch f.Type {
+	case BoolField:
+		return strconv.ParseBool(value)
+	case TimeField:
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			return parsed, nil
+		}
+		return time.ParseInLocation(dateLayout, value, time.Local)
+	case UUIDField:
+		return uuid.Parse(value)
+	case NumberField:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+}
+
+// isDate reports whether a time value is a date without a time of day.
+func isDate(value string) bool {
+	_, err := time.Parse(dateLayout, value)
+	return err == nil
+}
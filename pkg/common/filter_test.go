@@ -0,0 +1,278 @@
+package common
+
+import (
+	"errors"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFields = Fields{
+	"created_at": {Column: "created_at", Type: TimeField},
+	"read_at":    {Column: "read_at", Type: TimeField, Nullable: true},
+	"title":      {Column: "title", Type: StringField},
+	"priority":   {Column: "priority", Type: NumberField},
+	"id":         {Column: "id", Type: UUIDField},
+}
+
+// testRow is the model the generated statements select from.
+type testRow struct{}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		pagination  Pagination
+		wantFilters []Filter
+		wantSort    []SortField
+		wantErr     error
+	}{
+		{
+			name:       "default sort",
+			pagination: Pagination{},
+			wantSort:   []SortField{{Column: "created_at", Desc: true}},
+		},
+		{
+			name:       "equal filter",
+			pagination: Pagination{Filters: []string{"title:eq:hello"}},
+			wantFilters: []Filter{
+				{Column: "title", Operator: OperatorEq, Values: []interface{}{"hello"}},
+			},
+			wantSort: []SortField{{Column: "created_at", Desc: true}},
+		},
+		{
+			name:       "in filter",
+			pagination: Pagination{Filters: []string{"priority:in:1, 2"}},
+			wantFilters: []Filter{
+				{Column: "priority", Operator: OperatorIn, Values: []interface{}{1.0, 2.0}},
+			},
+			wantSort: []SortField{{Column: "created_at", Desc: true}},
+		},
+		{
+			name:       "is_null filter",
+			pagination: Pagination{Filters: []string{"read_at:is_null:true"}},
+			wantFilters: []Filter{
+				{Column: "read_at", Operator: OperatorIsNull, Values: []interface{}{true}},
+			},
+			wantSort: []SortField{{Column: "created_at", Desc: true}},
+		},
+		{
+			name:       "between dates includes the whole last day",
+			pagination: Pagination{Filters: []string{"created_at:between:2024-01-01,2024-01-31"}},
+			wantFilters: []Filter{{
+				Column:       "created_at",
+				Operator:     OperatorBetween,
+				Values:       []interface{}{time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)},
+				ExclusiveEnd: true,
+			}},
+			wantSort: []SortField{{Column: "created_at", Desc: true}},
+		},
+		{
+			name:       "between times keeps the upper bound",
+			pagination: Pagination{Filters: []string{"created_at:between:2024-01-01,2024-01-31T12:00:00Z"}},
+			wantFilters: []Filter{{
+				Column:   "created_at",
+				Operator: OperatorBetween,
+				Values:   []interface{}{time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+			}},
+			wantSort: []SortField{{Column: "created_at", Desc: true}},
+		},
+		{
+			name:       "plain sort field follows desc",
+			pagination: Pagination{Sort: "created_at", Desc: true},
+			wantSort:   []SortField{{Column: "created_at", Desc: true}},
+		},
+		{
+			name:       "plain sort field ascending",
+			pagination: Pagination{Sort: "created_at"},
+			wantSort:   []SortField{{Column: "created_at"}},
+		},
+		{
+			name:       "several sort fields",
+			pagination: Pagination{Sort: "-priority, title asc,+created_at"},
+			wantSort:   []SortField{{Column: "priority", Desc: true}, {Column: "title"}, {Column: "created_at"}},
+		},
+		{
+			name:       "unknown filter field",
+			pagination: Pagination{Filters: []string{"password:eq:secret"}},
+			wantErr:    errormessage.ErrInvalidFilter,
+		},
+		{
+			name:       "unsupported operator",
+			pagination: Pagination{Filters: []string{"title:gt:a"}},
+			wantErr:    errormessage.ErrInvalidFilter,
+		},
+		{
+			name:       "invalid value",
+			pagination: Pagination{Filters: []string{"created_at:gt:yesterday"}},
+			wantErr:    errormessage.ErrInvalidFilter,
+		},
+		{
+			name:       "between takes two values",
+			pagination: Pagination{Filters: []string{"created_at:between:2024-01-01"}},
+			wantErr:    errormessage.ErrInvalidFilter,
+		},
+		{
+			name:       "too many filters",
+			pagination: Pagination{Filters: strings.Split(strings.Repeat("title:eq:a ", maxFilters+1), " ")[:maxFilters+1]},
+			wantErr:    errormessage.ErrInvalidFilter,
+		},
+		{
+			name:       "unknown sort field",
+			pagination: Pagination{Sort: "password"},
+			wantErr:    errormessage.ErrInvalidSort,
+		},
+		{
+			name:       "too many sort fields",
+			pagination: Pagination{Sort: "title,priority,created_at,id"},
+			wantErr:    errormessage.ErrInvalidSort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := tt.pagination.ParseQuery(testFields, "-created_at")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseQuery() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuery() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(query.Filters, tt.wantFilters) {
+				t.Errorf("Filters = %+v, want %+v", query.Filters, tt.wantFilters)
+			}
+			if !reflect.DeepEqual(query.Sort, tt.wantSort) {
+				t.Errorf("Sort = %+v, want %+v", query.Sort, tt.wantSort)
+			}
+		})
+	}
+}
+
+func TestParseSortField(t *testing.T) {
+	tests := []struct {
+		key     string
+		desc    bool
+		want    *SortField
+		wantErr bool
+	}{
+		{key: "created_at", want: &SortField{Column: "created_at"}},
+		{key: "created_at", desc: true, want: &SortField{Column: "created_at", Desc: true}},
+		{key: "-created_at", want: &SortField{Column: "created_at", Desc: true}},
+		{key: "+created_at", desc: true, want: &SortField{Column: "created_at"}},
+		{key: "created_at desc", want: &SortField{Column: "created_at", Desc: true}},
+		{key: "created_at ASC", desc: true, want: &SortField{Column: "created_at"}},
+		{key: "created_at sideways", wantErr: true},
+		{key: "created_at desc nulls", wantErr: true},
+		{key: "-password", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSortField(testFields, tt.key, tt.desc)
+		if tt.wantErr {
+			if !errors.Is(err, errormessage.ErrInvalidSort) {
+				t.Errorf("parseSortField(%q) error = %v, want %v", tt.key, err, errormessage.ErrInvalidSort)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSortField(%q, %v) = %+v, %v, want %+v", tt.key, tt.desc, got, err, tt.want)
+		}
+	}
+}
+
+func TestSortedByCreatedAt(t *testing.T) {
+	// sort=created_at must stay on keyset pagination in the direction of desc, as it was before sort keys could be
+	// combined.
+	for _, desc := range []bool{false, true} {
+		query, err := Pagination{Sort: "created_at", Desc: desc}.ParseQuery(testFields, "-created_at")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if sorted, gotDesc := query.SortedBy("created_at"); !sorted || gotDesc != desc {
+			t.Errorf("desc=%v: SortedBy() = %v, %v, want true, %v", desc, sorted, gotDesc, desc)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		sort []SortField
+		want string
+	}{
+		{
+			name: "tie-breaker follows the last field",
+			sort: []SortField{{Column: "created_at", Desc: true}},
+			want: `ORDER BY "created_at" DESC,"id" DESC`,
+		},
+		{
+			name: "ascending",
+			sort: []SortField{{Column: "created_at"}},
+			want: `ORDER BY "created_at","id"`,
+		},
+		{
+			name: "several fields",
+			sort: []SortField{{Column: "priority", Desc: true}, {Column: "title"}},
+			want: `ORDER BY "priority" DESC,"title","id"`,
+		},
+		{
+			name: "explicit id",
+			sort: []SortField{{Column: "id", Desc: true}, {Column: "title"}},
+			want: `ORDER BY "id" DESC,"title"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := &ListQuery{Sort: tt.sort}
+			if sql := dryRun(t, query.Order); !strings.HasSuffix(sql, tt.want) {
+				t.Errorf("SQL = %s, want it to end with %s", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhereBetween(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{name: "dates", filter: "created_at:between:2024-01-01,2024-01-31", want: `"created_at" >= $1 AND "created_at" < $2`},
+		{name: "times", filter: "created_at:between:2024-01-01T00:00:00Z,2024-01-31T23:59:59Z", want: `"created_at" >= $1 AND "created_at" <= $2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := Pagination{Filters: []string{tt.filter}}.ParseQuery(testFields, "-created_at")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if sql := dryRun(t, query.Where); !strings.HasSuffix(sql, "WHERE "+tt.want) {
+				t.Errorf("SQL = %s, want it to end with WHERE %s", sql, tt.want)
+			}
+		})
+	}
+}
+
+// dryRun builds the PostgreSQL statement selecting test rows with the given scope applied, without a database.
+func dryRun(t *testing.T, scope func(*gorm.DB) *gorm.DB) string {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rows []testRow
+	return scope(db.Model(&testRow{})).Find(&rows).Statement.SQL.String()
+}
@@ -29,20 +29,28 @@ type (
 	}
 
 	// EntriesModel represents a paginated collection of entries of a generic type T.
+	// Lists ordered by creation time also carry the cursors of the neighbouring pages. Page is 0 for pages fetched by
+	// cursor, which have no fixed position.
 	EntriesModel[T interface{}] struct {
-		Entries    []T   `json:"entries"`
-		Count      int64 `json:"count"`
-		Page       int   `json:"page"`
-		TotalPages int   `json:"total_pages"`
+		Entries    []T    `json:"entries"`
+		Count      int64  `json:"count"`
+		Page       int    `json:"page"`
+		TotalPages int    `json:"total_pages"`
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
 	}
 
-	// Pagination struct handles fields required for paginating, searching, and sorting a collection of items.
+	// Pagination struct handles fields required for paginating, searching, filtering and sorting a collection of items.
+	// Filters and Sort are parsed by ParseQuery. A Cursor switches from offset to keyset pagination, Offset, Sort and
+	// Desc are then taken from the cursor.
 	Pagination struct {
-		Offset int    `form:"offset" validate:"omitempty"`
-		Limit  int    `form:"limit" validate:"omitempty"`
-		Search string `form:"search" validate:"omitempty"`
-		Sort   string `form:"sort" validate:"omitempty"`
-		Desc   bool   `form:"desc" validate:"omitempty"`
+		Offset  int      `form:"offset" validate:"omitempty"`
+		Limit   int      `form:"limit" validate:"omitempty"`
+		Search  string   `form:"search" validate:"omitempty"`
+		Filters []string `form:"filter" validate:"omitempty"`
+		Sort    string   `form:"sort" validate:"omitempty"`
+		Desc    bool     `form:"desc" validate:"omitempty"`
+		Cursor  string   `form:"cursor" validate:"omitempty"`
 	}
 )
 
@@ -77,6 +85,12 @@ func NewEntries[T interface{}](entries []T, count int64, page, totalPages int) *
 	}
 }
 
+// WithCursors sets the encoded cursors of the pages before and after the entries.
+func (e *EntriesModel[T]) WithCursors(prev, next string) *EntriesModel[T] {
+	e.PrevCursor, e.NextCursor = prev, next
+	return e
+}
+
 // Success sets a JSON success response with HTTP status 200 and the provided result data.
 func (r Response) Success(c *gin.Context, result interface{}) {
 	c.JSON(http.StatusOK, ResponseModel{
@@ -130,6 +144,15 @@ func (r Response) Error(c *gin.Context, errParam interface{}) {
 		if errors.Is(err, io.EOF) {
 			fmt.Printf("EOF error: %v\n", err)
 			r.BadRequest(c, []utils.IError{}, errormessage.ErrRequestBodyEmptyText)
+		} else if errors.Is(err, errormessage.ErrEmailAlreadyExists) {
+			fmt.Printf("Conflict error: %v\n", err)
+			r.Conflict(c, err.Error())
+		} else if errors.Is(err, errormessage.ErrAvatarTooLarge) {
+			fmt.Printf("Request entity too large error: %v\n", err)
+			r.RequestEntityTooLarge(c, err.Error())
+		} else if errors.Is(err, errormessage.ErrTooManyStreams) {
+			fmt.Printf("Too many requests error: %v\n", err)
+			r.TooManyRequests(c, err.Error())
 		} else {
 			fmt.Printf("Error: %v\n", err)
 			r.BadRequest(c, []utils.IError{}, err.Error())
@@ -160,6 +183,46 @@ func (r Response) InternalServerError(c *gin.Context, message string) {
 	})
 }
 
+// Conflict sends an HTTP 409 Conflict response with a custom message and an empty list of errormessage.
+func (r Response) Conflict(c *gin.Context, message string) {
+	c.JSON(http.StatusConflict, ResponseModel{
+		TraceID: r.extractTraceID(c),
+		Message: utils.CapitalizeFirstLetter(message),
+		Errors:  []utils.IError{},
+		Result:  nil,
+	})
+}
+
+// RequestEntityTooLarge sends an HTTP 413 Request Entity Too Large response with a custom message and an empty list of errormessage.
+func (r Response) RequestEntityTooLarge(c *gin.Context, message string) {
+	c.JSON(http.StatusRequestEntityTooLarge, ResponseModel{
+		TraceID: r.extractTraceID(c),
+		Message: utils.CapitalizeFirstLetter(message),
+		Errors:  []utils.IError{},
+		Result:  nil,
+	})
+}
+
+// TooManyRequests sends an HTTP 429 Too Many Requests response with a custom message and an empty list of errormessage.
+func (r Response) TooManyRequests(c *gin.Context, message string) {
+	c.JSON(http.StatusTooManyRequests, ResponseModel{
+		TraceID: r.extractTraceID(c),
+		Message: utils.CapitalizeFirstLetter(message),
+		Errors:  []utils.IError{},
+		Result:  nil,
+	})
+}
+
+// Forbidden sends an HTTP 403 Forbidden response with a custom message and an empty list of errormessage.
+func (r Response) Forbidden(c *gin.Context, message string) {
+	c.JSON(http.StatusForbidden, ResponseModel{
+		TraceID: r.extractTraceID(c),
+		Message: utils.CapitalizeFirstLetter(message),
+		Errors:  []utils.IError{},
+		Result:  nil,
+	})
+}
+
 // NotFound is a handler function that responds with a '404 Not Found' status and a formatted message using JSON.
 func (r Response) NotFound(c *gin.Context, message string) {
 	c.JSON(http.StatusNotFound, ResponseModel{
@@ -202,15 +265,6 @@ func (p Pagination) GetPage(count int64) int {
 	return (offset / limit) + 1
 }
 
-// GetSort returns the sort field for a query. It defaults to "created_at" if no sort field is specified.
-func (p Pagination) GetSort() string {
-	if p.Sort == "" {
-		p.Sort = "created_at"
-	}
-
-	return validateSort(p.Sort)
-}
-
 // GetTotalPages returns the total number of pages given the total item count.
 func (p Pagination) GetTotalPages(count int64) int {
 	limit := p.GetLimit()
@@ -221,71 +275,45 @@ func (p Pagination) GetTotalPages(count int64) int {
 	return int(math.Ceil(float64(count) / float64(limit)))
 }
 
-// Paginate applies pagination, sorting, and search functionality to a GORM database query.
-// It uses values from the Pagination struct, including limit, offset, search, sort column, and a boolean (Desc) for sorting direction.
-// The `column` parameter specifies the database column to be used for search functionality.
+// Paginate applies pagination, filtering, sorting, and search functionality to a GORM database query.
+// It uses values from the Pagination struct, including limit, offset and search, and the filters and sort order of the
+// ListQuery parsed from it. The `columns` parameter specifies the database columns matched by the search term.
 //
 // Features:
 // - Pagination: Applies limit and offset to control the number of records returned.
-// - Sorting: Sorts results by the specified column and applies ascending or descending order based on the Desc flag.
-// - Search: Filters results using the search term applied to the specified column.
+// - Filtering and sorting: Applies the filters and the sort order of the query, see ParseQuery.
+// - Search: Filters results using the search term applied to any of the specified columns.
 //
-// To mitigate SQL injection risks, only alphanumeric column names are allowed for sorting and searching.
+// To mitigate SQL injection risks, column names only come from the allow-list of the resource or the code, never from
+// the request.
 //
 // Important: This function should be used within a GORM Scope to apply pagination, sorting, and search functionality correctly.
-func Paginate(paging *Pagination, column string) func(db *gorm.DB) *gorm.DB {
+func Paginate(paging *Pagination, query *ListQuery, columns ...string) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		if paging.Search != "" {
-			searchTerm := "%" + strings.ToLower(paging.Search) + "%"
-			lowerColumn := strings.ToLower(column)
-
-			if isAlphaNumeric(lowerColumn) {
-				db = db.Where(lowerColumn+" ILIKE ? ", searchTerm)
-			}
-		}
+		db = Filtered(paging, query, columns...)(db)
 
-		sort := paging.GetSort()
-
-		return db.Offset(paging.GetOffset()).Limit(paging.GetLimit()).
-			Order(clause.OrderByColumn{Column: clause.Column{Name: sort}, Desc: paging.Desc})
+		return query.Order(db.Offset(paging.GetOffset()).Limit(paging.GetLimit()))
 	}
 }
 
-// isAlphaNumeric checks if a given string consists only of alphanumeric characters (a-z, 0-9).
-func isAlphaNumeric(s string) bool {
-	for _, char := range s {
-		if !((char >= 'a' && char <= 'z') || (char >= '0' && char <= '9')) {
-			return false
-		}
+// Filtered applies the search term and the filters of a list without paging or sorting it, e.g. to count its entries.
+func Filtered(paging *Pagination, query *ListQuery, columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return query.Where(search(db, paging, columns))
 	}
-	return true
 }
 
-// validateSort sanitizes and validates the provided sort parameter to prevent SQL injection vulnerabilities (CWE-89).
-//
-// This function ensures that the input sort string only contains valid field names (e.g., "id" or "created_at").
-// If the input is invalid or contains unexpected values, the function defaults to "created_at" to ensure safety.
-//
-// Security:
-//   - This function mitigates SQL injection attacks (CWE-89) by only allowing sorting on predefined fields
-//     and rejecting any invalid input that could be used maliciously.
-//
-// Reference: https://cwe.mitre.org/data/definitions/89.html
-func validateSort(sort string) string {
-	allowedFields := map[string]bool{
-		"id":         true,
-		"created_at": true,
-	}
-
-	parts := strings.Fields(strings.TrimSpace(sort))
-	if len(parts) != 2 {
-		return "created_at"
+// search filters a query by the search term of the pagination, matched against any of the given columns.
+func search(db *gorm.DB, paging *Pagination, columns []string) *gorm.DB {
+	if paging.Search == "" || len(columns) == 0 {
+		return db
 	}
 
-	field := parts[0]
-	if !allowedFields[field] {
-		return "created_at"
+	searchTerm := "%" + strings.ToLower(paging.Search) + "%"
+	conditions := make([]clause.Expression, 0, len(columns))
+	for _, column := range columns {
+		conditions = append(conditions, clause.Like{Column: clause.Expr{SQL: "LOWER(?)", Vars: []interface{}{clause.Column{Name: column}}}, Value: searchTerm})
 	}
 
-	return field
+	return db.Where(clause.Or(conditions...))
 }
@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"strings"
+)
+
+const (
+	// APIKeyPrefix identifies Zenith API keys, e.g. in secret scanners.
+	APIKeyPrefix = "zk"
+
+	apiKeyPublicLen = 6
+	apiKeySecretLen = 32
+	apiKeySeparator = "_"
+)
+
+// GenerateAPIKey generates a new API key of the form "zk_<prefix>_<secret>". The public prefix is used to look the
+// key up, while only the SHA-256 hash of the whole key is meant to be persisted.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	public, err := generateBytes(apiKeyPublicLen)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	secret, _, err := GenerateOpaqueToken(apiKeySecretLen)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(public)
+	key = APIKeyPrefix + apiKeySeparator + prefix + apiKeySeparator + secret
+
+	return key, prefix, HashOpaqueToken(key), nil
+}
+
+// ParseAPIKey extracts the public prefix of the given API key.
+func ParseAPIKey(key string) (string, error) {
+	parts := strings.SplitN(key, apiKeySeparator, 3)
+	if len(parts) != 3 || parts[0] != APIKeyPrefix || len(parts[1]) != apiKeyPublicLen*2 || parts[2] == "" {
+		return "", errormessage.ErrInvalidAPIKey
+	}
+
+	return parts[1], nil
+}
+
+// VerifyAPIKey reports whether the given API key matches the stored hash, using a constant time comparison.
+func VerifyAPIKey(key, hash string) bool {
+	return VerifyOpaqueToken(key, hash)
+}
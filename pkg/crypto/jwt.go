@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+)
+
+// SignES256JWT creates a compact JWT signed with ECDSA P-256 and SHA-256, as used by APNs provider tokens and VAPID.
+// The "alg" header is set by SignES256JWT.
+func SignES256JWT(key *ecdsa.PrivateKey, header map[string]string, claims map[string]interface{}) (string, error) {
+	fields := map[string]string{"alg": "ES256"}
+	for name, value := range header {
+		fields[name] = value
+	}
+
+	encodedHeader, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+
+	encodedClaims, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(encodedHeader) + "." + base64.RawURLEncoding.EncodeToString(encodedClaims)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	// JWS encodes ES256 signatures as the fixed-size concatenation of r and s.
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,32 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateOpaqueToken generates a random URL-safe token of the given byte length together with its SHA-256 hash.
+// The token is meant to be handed out once, while only the hash is persisted.
+func GenerateOpaqueToken(length uint32) (token, hash string, err error) {
+	secret, err := generateBytes(length)
+	if err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(secret)
+
+	return token, HashOpaqueToken(token), nil
+}
+
+// VerifyOpaqueToken reports whether the given token matches the stored hash, using a constant time comparison.
+func VerifyOpaqueToken(token, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashOpaqueToken(token)), []byte(hash)) == 1
+}
+
+// HashOpaqueToken returns the hex encoded SHA-256 hash of the given token.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -6,14 +6,18 @@ import (
 	"github.com/arifai/zenith/pkg/errormessage"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"strings"
 	"time"
 )
 
 // TokenPayload represents the payload data structure embedded in a token.
+// ClientID and Scopes are only set on tokens issued to OAuth2 clients.
 type TokenPayload struct {
 	Jti       uuid.UUID
 	AccountID uuid.UUID
 	DeviceID  uuid.UUID
+	ClientID  uuid.UUID
+	Scopes    []string
 	IssuedAt  time.Time
 	NotBefore time.Time
 	ExpiresAt time.Time
@@ -21,10 +25,31 @@ type TokenPayload struct {
 }
 
 const (
-	AccessToken  = "access_token"
-	RefreshToken = "refresh_token"
+	AccessToken       = "access_token"
+	RefreshToken      = "refresh_token"
+	OAuthAccessToken  = "oauth_access_token"
+	OAuthRefreshToken = "oauth_refresh_token"
+
+	clientIDClaim = "cid"
+	scopeClaim    = "scope"
 )
 
+// HasScope reports whether the token was granted the given scope.
+func (t *TokenPayload) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOAuth reports whether the token was issued to an OAuth2 client.
+func (t *TokenPayload) IsOAuth() bool {
+	return t.TokenType == OAuthAccessToken || t.TokenType == OAuthRefreshToken
+}
+
 // GenerateToken creates a signed token using the given secret key.
 func (t *TokenPayload) GenerateToken(secretKey paseto.V4AsymmetricSecretKey) string {
 	token := paseto.NewToken()
@@ -36,6 +61,14 @@ func (t *TokenPayload) GenerateToken(secretKey paseto.V4AsymmetricSecretKey) str
 	token.SetExpiration(t.ExpiresAt)
 	token.SetFooter([]byte(t.TokenType))
 
+	if t.ClientID != uuid.Nil {
+		token.SetString(clientIDClaim, t.ClientID.String())
+	}
+
+	if len(t.Scopes) > 0 {
+		token.SetString(scopeClaim, strings.Join(t.Scopes, " "))
+	}
+
 	return token.V4Sign(secretKey, nil)
 }
 
@@ -103,6 +136,17 @@ func VerifyToken(token string, publicKey paseto.V4AsymmetricPublicKey) (*TokenPa
 		TokenType: string(parsedToken.Footer()),
 	}
 
+	if clientID, err := parsedToken.GetString(clientIDClaim); err == nil {
+		if tokenPayload.ClientID, err = uuid.Parse(clientID); err != nil {
+			log.Error(errormessage.ErrFailedParseClientIDText, zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if scope, err := parsedToken.GetString(scopeClaim); err == nil {
+		tokenPayload.Scopes = strings.Fields(scope)
+	}
+
 	return tokenPayload, nil
 }
 
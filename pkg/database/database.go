@@ -35,8 +35,9 @@ func connectDatabaseWithDSN(dsn string, debug bool, nowFunc func() time.Time) *g
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger:  logger.Default.LogMode(logLevel),
-		NowFunc: nowFunc,
+		Logger:         logger.Default.LogMode(logLevel),
+		NowFunc:        nowFunc,
+		TranslateError: true,
 	})
 	if err != nil {
 		log.Fatal(errormessage.ErrFailedToConnectDBText, zap.Error(err))
@@ -46,11 +46,103 @@ const (
 	ErrFailedToGenerateRandomBytesText  = "failed to generate random bytes"
 	ErrFailedToLoadEnvFileText          = "failed to load env file"
 	ErrFailedToLoadEnvVariableText      = "failed to load env variable"
+	ErrFailedToGenerateSecretText       = "failed to generate a random secret"
 	ErrMigrationText                    = "error during migration"
 	ErrCreatingEnumsText                = "error creating enums"
 	ErrInsertingMigrationDataText       = "error during inserting migration data"
 	ErrFailedToParseUUIDText            = "failed to parse uuid"
 	ErrInvalidDeviceIDInBodyText        = "invalid device ID"
+	ErrInvalidEmailChangeTokenText      = "invalid or expired email change token"
+	ErrUnknownStorageDriverText         = "unknown storage driver"
+	ErrInvalidStorageKeyText            = "invalid storage key"
+	ErrInvalidS3EndpointText            = "invalid S3 endpoint"
+	ErrMissingS3BucketText              = "S3 bucket is missing"
+	ErrS3RequestFailedText              = "S3 request failed"
+	ErrFailedToInitializeStorageText    = "failed to initialize storage"
+	ErrFailedToDeleteObjectText         = "failed to delete object from storage"
+	ErrUnsupportedImageTypeText         = "unsupported image type, only JPEG, PNG, GIF and WebP are allowed"
+	ErrInvalidImageText                 = "invalid image"
+	ErrImageDimensionsTooLargeText      = "image dimensions are too large"
+	ErrAvatarTooLargeText               = "avatar file is too large"
+	ErrAvatarFileRequiredText           = "avatar file is required"
+	ErrMissingAPIKeyText                = "API key missing"
+	ErrInvalidAPIKeyText                = "invalid, expired or revoked API key"
+	ErrInsufficientScopeText            = "insufficient scope"
+	ErrUnknownScopeText                 = "unknown scope"
+	ErrAPIKeyNotFoundText               = "API key not found"
+	ErrExpiryInThePastText              = "expiry must be in the future"
+	ErrFailedToTouchAPIKeyText          = "failed to update API key last used time"
+	ErrFailedParseClientIDText          = "failed to parse 'cid'"
+	ErrOAuthClientNotFoundText          = "OAuth client not found"
+	ErrInvalidOAuthClientText           = "unknown or revoked OAuth client"
+	ErrInvalidRedirectURIText           = "redirect URI is not registered for this client"
+	ErrInvalidOAuthScopeText            = "requested scope is not allowed for this client"
+	ErrUnsupportedResponseTypeText      = "only the 'code' response type is supported"
+	ErrPKCERequiredText                 = "public clients must use PKCE with the S256 method"
+	ErrUnsupportedChallengeMethodText   = "only the S256 code challenge method is supported"
+	ErrInvalidClientCredentialsText     = "invalid client credentials"
+	ErrInvalidAuthorizationCodeText     = "invalid, expired or already used authorization code"
+	ErrInvalidCodeVerifierText          = "invalid code verifier"
+	ErrUnsupportedGrantTypeText         = "unsupported grant type"
+	ErrInvalidOAuthRefreshTokenText     = "invalid, expired or revoked refresh token"
+	ErrOAuthClientRevokedText           = "the OAuth client of this token has been revoked"
+	ErrAccessDeniedText                 = "the account denied the authorization request"
+	ErrMissingGrantParameterText        = "missing required parameter"
+	ErrNoPushTokenText                  = "account has no active push token"
+	ErrInactiveDeviceTokenText          = "device push token is no longer active"
+	ErrFailedToTouchDeviceTokenText     = "failed to update device push token last seen time"
+	ErrFailedToDeactivateTokenText      = "failed to deactivate device push token"
+	ErrUnknownFCMErrorText              = "unknown FCM error"
+	ErrInvalidTopicText                 = "topic must consist of letters, digits and the characters -_.~%"
+	ErrTopicNotFoundText                = "topic subscription not found"
+	ErrInvalidConditionText             = "condition must combine at most 5 tests like 'topic' in topics with &&, || and parentheses"
+	ErrFailedToSyncTopicsText           = "failed to synchronize topic subscriptions"
+	ErrMissingPushResultText            = "push provider returned no result for the token"
+	ErrWebPushDisabledText              = "web push is not configured"
+	ErrInvalidWebPushKeysText           = "invalid web push subscription keys"
+	ErrSubscriptionNotFoundText         = "web push subscription not found"
+	ErrInvalidWebPushEndpointText       = "web push endpoint must be an HTTPS URL"
+	ErrWebPushEndpointNotAllowedText    = "web push endpoint does not belong to a known push service"
+	ErrForbiddenPushAddressText         = "push service resolved to a loopback, private or link-local address"
+	ErrPushTargetUnsupportedText        = "topics and conditions are not supported by this push provider"
+	ErrSigningKeyNotPEMText             = "signing key is not PEM encoded"
+	ErrSigningKeyNotECDSAText           = "signing key is not an ECDSA key"
+	ErrAPNsRejectedText                 = "APNs rejected the notification"
+	ErrInvalidAPNsDeviceTokenText       = "APNs device token must be hexadecimal"
+	ErrNotAddressedToSubscriptionText   = "message is not addressed to a web push subscription"
+	ErrInvalidVAPIDKeyText              = "VAPID key is not a P-256 key"
+	ErrInvalidWebPushAuthSecretText     = "web push authentication secret must be 16 bytes"
+	ErrWebPushPayloadTooLargeText       = "web push payload exceeds the maximum size"
+	ErrWebPushRejectedText              = "web push service rejected the notification"
+	ErrInternalAPIKeyRequiredText       = "an API key of an internal service is required"
+	ErrFailedToClaimPushText            = "failed to claim pending push notifications"
+	ErrFailedToUpdatePushText           = "failed to update push notification"
+	ErrFailedToInitPushProviderText     = "failed to initialize push provider"
+	ErrTooManyStreamsText               = "too many open notification streams"
+	ErrFailedToPublishEventText         = "failed to publish notification event"
+	ErrFailedToReleaseStreamText        = "failed to release notification stream"
+	ErrFailedToRefreshStreamText        = "failed to refresh notification stream"
+	ErrInvalidCursorText                = "invalid pagination cursor"
+	ErrInvalidFilterText                = "invalid filter"
+	ErrInvalidSortText                  = "invalid sort order"
+	ErrNotificationExpiredText          = "notification expiry must be in the future"
+	ErrTemplateNotFoundText             = "notification template not found"
+	ErrInvalidTemplateText              = "invalid notification template"
+	ErrRenderingTemplateText            = "failed to render notification template"
+	ErrCampaignNotFoundText             = "campaign not found"
+	ErrCampaignStatusText               = "campaign can not be changed in its current status"
+	ErrEmptyAudienceText                = "campaign audience requires account IDs or filters"
+	ErrCampaignEndedText                = "campaign recurrence ends before its first occurrence"
+	ErrFailedToRunCampaignText          = "failed to run campaign"
+	ErrCampaignClaimLostText            = "campaign is no longer claimed by this run"
+	ErrFailedToSendDigestText           = "failed to send notification digest"
+	ErrFailedToPurgeText                = "failed to purge notifications"
+	ErrInvalidPurgeArchiveText          = "invalid purge archive, expected table or file"
+	ErrUnknownMailDriverText            = "unknown mail driver"
+	ErrUnknownSMTPEncryptionText        = "unknown SMTP encryption, expected starttls, tls or none"
+	ErrSTARTTLSUnsupportedText          = "SMTP server does not support STARTTLS"
+	ErrSMTPAuthUnsupportedText          = "SMTP server does not support authentication"
+	ErrFailedToInitializeMailText       = "failed to initialize mail transport"
 )
 
 var (
@@ -67,9 +159,63 @@ var (
 	ErrInvalidSaltLength            = errors.New(ErrInvalidSaltLengthText)
 	ErrMissingAuthorizationHeader   = errors.New(ErrMissingAuthorizationHeaderText)
 	ErrInvalidTokenType             = errors.New(ErrInvalidTokenTypeText)
+	ErrOAuthClientRevoked           = errors.New(ErrOAuthClientRevokedText)
 	ErrInvalidAccessToken           = errors.New(ErrInvalidTokenHashText)
 	ErrInvalidAccessTokenInBody     = errors.New(ErrInvalidAccessTokenInBodyText)
 	ErrInvalidRefreshTokenInBody    = errors.New(ErrInvalidRefreshTokenInBodyText)
 	ErrAccountNotFound              = errors.New(ErrAccountNotFoundText)
 	ErrInvalidDeviceIDInBody        = errors.New(ErrInvalidDeviceIDInBodyText)
+	ErrInvalidEmailChangeToken      = errors.New(ErrInvalidEmailChangeTokenText)
+	ErrInvalidStorageKey            = errors.New(ErrInvalidStorageKeyText)
+	ErrInvalidS3Endpoint            = errors.New(ErrInvalidS3EndpointText)
+	ErrMissingS3Bucket              = errors.New(ErrMissingS3BucketText)
+	ErrUnsupportedImageType         = errors.New(ErrUnsupportedImageTypeText)
+	ErrInvalidImage                 = errors.New(ErrInvalidImageText)
+	ErrImageDimensionsTooLarge      = errors.New(ErrImageDimensionsTooLargeText)
+	ErrAvatarTooLarge               = errors.New(ErrAvatarTooLargeText)
+	ErrAvatarFileRequired           = errors.New(ErrAvatarFileRequiredText)
+	ErrMissingAPIKey                = errors.New(ErrMissingAPIKeyText)
+	ErrInvalidAPIKey                = errors.New(ErrInvalidAPIKeyText)
+	ErrInsufficientScope            = errors.New(ErrInsufficientScopeText)
+	ErrUnknownScope                 = errors.New(ErrUnknownScopeText)
+	ErrExpiryInThePast              = errors.New(ErrExpiryInThePastText)
+	ErrInvalidOAuthClient           = errors.New(ErrInvalidOAuthClientText)
+	ErrInvalidRedirectURI           = errors.New(ErrInvalidRedirectURIText)
+	ErrInvalidOAuthScope            = errors.New(ErrInvalidOAuthScopeText)
+	ErrUnsupportedResponseType      = errors.New(ErrUnsupportedResponseTypeText)
+	ErrPKCERequired                 = errors.New(ErrPKCERequiredText)
+	ErrUnsupportedChallengeMethod   = errors.New(ErrUnsupportedChallengeMethodText)
+	ErrInvalidTopic                 = errors.New(ErrInvalidTopicText)
+	ErrInvalidCondition             = errors.New(ErrInvalidConditionText)
+	ErrWebPushDisabled              = errors.New(ErrWebPushDisabledText)
+	ErrInvalidWebPushKeys           = errors.New(ErrInvalidWebPushKeysText)
+	ErrInvalidWebPushEndpoint       = errors.New(ErrInvalidWebPushEndpointText)
+	ErrWebPushEndpointNotAllowed    = errors.New(ErrWebPushEndpointNotAllowedText)
+	ErrForbiddenPushAddress         = errors.New(ErrForbiddenPushAddressText)
+	ErrPushTargetUnsupported        = errors.New(ErrPushTargetUnsupportedText)
+	ErrSigningKeyNotPEM             = errors.New(ErrSigningKeyNotPEMText)
+	ErrSigningKeyNotECDSA           = errors.New(ErrSigningKeyNotECDSAText)
+	ErrAPNsRejected                 = errors.New(ErrAPNsRejectedText)
+	ErrInvalidAPNsDeviceToken       = errors.New(ErrInvalidAPNsDeviceTokenText)
+	ErrNotAddressedToSubscription   = errors.New(ErrNotAddressedToSubscriptionText)
+	ErrInvalidVAPIDKey              = errors.New(ErrInvalidVAPIDKeyText)
+	ErrInvalidWebPushAuthSecret     = errors.New(ErrInvalidWebPushAuthSecretText)
+	ErrWebPushPayloadTooLarge       = errors.New(ErrWebPushPayloadTooLargeText)
+	ErrWebPushRejected              = errors.New(ErrWebPushRejectedText)
+	ErrTooManyStreams               = errors.New(ErrTooManyStreamsText)
+	ErrInvalidCursor                = errors.New(ErrInvalidCursorText)
+	ErrInvalidFilter                = errors.New(ErrInvalidFilterText)
+	ErrInvalidSort                  = errors.New(ErrInvalidSortText)
+	ErrNotificationExpired          = errors.New(ErrNotificationExpiredText)
+	ErrTemplateNotFound             = errors.New(ErrTemplateNotFoundText)
+	ErrInvalidTemplate              = errors.New(ErrInvalidTemplateText)
+	ErrRenderingTemplate            = errors.New(ErrRenderingTemplateText)
+	ErrCampaignNotFound             = errors.New(ErrCampaignNotFoundText)
+	ErrCampaignStatus               = errors.New(ErrCampaignStatusText)
+	ErrCampaignClaimLost            = errors.New(ErrCampaignClaimLostText)
+	ErrEmptyAudience                = errors.New(ErrEmptyAudienceText)
+	ErrCampaignEnded                = errors.New(ErrCampaignEndedText)
+	ErrInvalidPurgeArchive          = errors.New(ErrInvalidPurgeArchiveText)
+	ErrSTARTTLSUnsupported          = errors.New(ErrSTARTTLSUnsupportedText)
+	ErrSMTPAuthUnsupported          = errors.New(ErrSMTPAuthUnsupportedText)
 )
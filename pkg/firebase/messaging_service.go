@@ -2,32 +2,222 @@ package firebase
 
 import (
 	"context"
+	"errors"
 	"firebase.google.com/go/v4/messaging"
 	"github.com/arifai/zenith/cmd/wire/logger"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
 	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type MessagingService struct{ *Messaging }
 
+const (
+	// batchLimit is the maximum number of messages of a single FCM batch.
+	batchLimit = 500
+
+	// topicManagementLimit is the maximum number of tokens of a single FCM topic management request.
+	topicManagementLimit = 1000
+)
+
 var log = logger.ProvideLogger()
 
 func NewMessagingService(messaging *Messaging) *MessagingService {
 	return &MessagingService{messaging}
 }
 
-func (m *MessagingService) SendMessage(data map[string]string, token, title, body string) error {
-	message := &messaging.Message{
-		Token:        token,
-		Data:         data,
-		Notification: &messaging.Notification{Title: title, Body: body},
+// Send delivers the message through FCM, implementing push.Provider. It returns the message ID assigned by FCM.
+// Errors are classified through classifyError.
+func (m *MessagingService) Send(ctx context.Context, message *push.Message) (string, error) {
+	response, err := m.Client.Send(ctx, fcmMessage(message))
+	if err != nil {
+		return "", classifyError(err)
 	}
 
-	response, err := m.Client.Send(context.Background(), message)
-	if err != nil {
-		return err
+	return response, nil
+}
+
+// SendEach delivers the messages through FCM, implementing push.BatchProvider. Messages are sent in chunks of 500, the
+// limit of a single batch.
+func (m *MessagingService) SendEach(ctx context.Context, messages []*push.Message) ([]push.Result, error) {
+	results := make([]push.Result, 0, len(messages))
+	for _, chunk := range push.Chunk(messages, batchLimit) {
+		fcmMessages := make([]*messaging.Message, 0, len(chunk))
+		for _, message := range chunk {
+			fcmMessages = append(fcmMessages, fcmMessage(message))
+		}
+
+		batch, err := m.Client.SendEach(ctx, fcmMessages)
+		if err != nil {
+			err = classifyError(err)
+			for range chunk {
+				results = append(results, push.Result{Err: err})
+			}
+			continue
+		}
+
+		for _, response := range batch.Responses {
+			if response.Success {
+				results = append(results, push.Result{Response: response.MessageID})
+			} else {
+				results = append(results, push.Result{Err: classifyError(response.Error)})
+			}
+		}
 	}
 
-	log.Info("successful send push notification", zap.String("response", response))
+	return results, nil
+}
+
+// fcmMessage converts a message into its FCM equivalent.
+func fcmMessage(message *push.Message) *messaging.Message {
+	return &messaging.Message{
+		Token:        message.Token,
+		Topic:        message.Topic,
+		Condition:    message.Condition,
+		Data:         message.Data,
+		Notification: notification(message),
+		Android:      androidConfig(message.Options),
+		APNS:         apnsConfig(message.Options),
+		Webpush:      webpushConfig(message.Options),
+	}
+}
+
+// Subscribe subscribes the given tokens to the topic, implementing push.TopicManager.
+func (m *MessagingService) Subscribe(ctx context.Context, topic string, tokens []string) error {
+	return m.manageTopic(ctx, topic, tokens, m.Client.SubscribeToTopic)
+}
+
+// Unsubscribe unsubscribes the given tokens from the topic, implementing push.TopicManager.
+func (m *MessagingService) Unsubscribe(ctx context.Context, topic string, tokens []string) error {
+	return m.manageTopic(ctx, topic, tokens, m.Client.UnsubscribeFromTopic)
+}
+
+// manageTopic applies a topic management operation in chunks of 1000 tokens. Failures of single tokens are logged,
+// only failed requests are returned.
+func (m *MessagingService) manageTopic(ctx context.Context, topic string, tokens []string,
+	operation func(context.Context, []string, string) (*messaging.TopicManagementResponse, error)) error {
+	for _, chunk := range push.Chunk(tokens, topicManagementLimit) {
+		response, err := operation(ctx, chunk, topic)
+		if err != nil {
+			return classifyError(err)
+		}
+
+		for _, info := range response.Errors {
+			log.Warn("failed to manage topic subscription", zap.String("topic", topic), zap.Int("index", info.Index),
+				zap.String("reason", info.Reason))
+		}
+	}
 
 	return nil
 }
+
+// notification builds the notification part of an FCM message.
+func notification(message *push.Message) *messaging.Notification {
+	return &messaging.Notification{Title: message.Title, Body: message.Body, ImageURL: message.Image}
+}
+
+// androidConfig maps the options to the Android-specific part of an FCM message.
+func androidConfig(options *push.Options) *messaging.AndroidConfig {
+	if options == nil {
+		return nil
+	}
+
+	config := &messaging.AndroidConfig{
+		CollapseKey: options.CollapseKey,
+		Priority:    options.Priority,
+		Notification: &messaging.AndroidNotification{
+			ChannelID:   options.ChannelID,
+			ClickAction: options.ClickAction,
+			Sound:       options.Sound,
+		},
+	}
+	if options.TimeToLive != nil {
+		ttl := time.Duration(*options.TimeToLive) * time.Second
+		config.TTL = &ttl
+	}
+
+	return config
+}
+
+// apnsConfig maps the options to the APNs-specific part of an FCM message. The priority and time to live are sent as
+// the apns-priority and apns-expiration headers.
+func apnsConfig(options *push.Options) *messaging.APNSConfig {
+	if options == nil {
+		return nil
+	}
+
+	headers := make(map[string]string)
+	if options.CollapseKey != "" {
+		headers["apns-collapse-id"] = options.CollapseKey
+	}
+	switch options.Priority {
+	case push.PriorityHigh:
+		headers["apns-priority"] = "10"
+	case push.PriorityNormal:
+		headers["apns-priority"] = "5"
+	}
+	if options.TimeToLive != nil {
+		expiration := int64(0)
+		if *options.TimeToLive > 0 {
+			expiration = time.Now().Add(time.Duration(*options.TimeToLive) * time.Second).Unix()
+		}
+		headers["apns-expiration"] = strconv.FormatInt(expiration, 10)
+	}
+
+	return &messaging.APNSConfig{
+		Headers: headers,
+		Payload: &messaging.APNSPayload{Aps: &messaging.Aps{
+			Badge:          options.Badge,
+			Sound:          options.Sound,
+			ThreadID:       options.ThreadID,
+			MutableContent: options.MutableContent,
+			Category:       options.ClickAction,
+		}},
+	}
+}
+
+// webpushConfig maps the options to the Web Push-specific part of an FCM message. The time to live, priority and
+// collapse key are sent as the TTL, Urgency and Topic headers of RFC 8030.
+func webpushConfig(options *push.Options) *messaging.WebpushConfig {
+	if options == nil {
+		return nil
+	}
+
+	config := &messaging.WebpushConfig{Headers: make(map[string]string)}
+	if options.TimeToLive != nil {
+		config.Headers["TTL"] = strconv.Itoa(*options.TimeToLive)
+	}
+	if options.Priority != "" {
+		config.Headers["Urgency"] = options.Priority
+	}
+	if options.CollapseKey != "" {
+		config.Headers["Topic"] = options.CollapseKey
+	}
+	if strings.HasPrefix(options.ClickAction, "https://") {
+		config.FCMOptions = &messaging.WebpushFCMOptions{Link: options.ClickAction}
+	}
+
+	return config
+}
+
+// classifyError wraps an FCM error into a push.Error. Unregistered and foreign registration tokens are permanent token
+// errors. Invalid arguments are permanent message errors but, unlike unregistered tokens, do not deactivate the token:
+// FCM reports invalid payloads, such as oversized data or a bad option, with the same code as malformed tokens, and
+// treating them as token errors would deactivate every device a bad message is sent to. A malformed token fails each of
+// its messages instead, it is never retried. Quota, availability, internal and credential errors are transient, as are
+// network errors.
+func classifyError(err error) error {
+	switch {
+	case err == nil:
+		return &push.Error{Err: errors.New(errormessage.ErrUnknownFCMErrorText)}
+	case messaging.IsUnregistered(err), messaging.IsSenderIDMismatch(err):
+		return &push.Error{Err: err, Permanent: true, InvalidToken: true}
+	case messaging.IsInvalidArgument(err):
+		return &push.Error{Err: err, Permanent: true}
+	default:
+		return &push.Error{Err: err}
+	}
+}
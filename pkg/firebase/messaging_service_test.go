@@ -0,0 +1,67 @@
+package firebase
+
+import (
+	"context"
+	firebase "firebase.google.com/go/v4"
+	"github.com/arifai/zenith/pkg/push"
+	"google.golang.org/api/option"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		errorStatus  string
+		errorCode    string
+		permanent    bool
+		invalidToken bool
+	}{
+		{"unregistered", http.StatusNotFound, "NOT_FOUND", "UNREGISTERED", true, true},
+		{"sender ID mismatch", http.StatusForbidden, "PERMISSION_DENIED", "SENDER_ID_MISMATCH", true, true},
+		{"invalid argument", http.StatusBadRequest, "INVALID_ARGUMENT", "INVALID_ARGUMENT", true, false},
+		{"quota exceeded", http.StatusTooManyRequests, "RESOURCE_EXHAUSTED", "QUOTA_EXCEEDED", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(`{"error":{"message":"rejected","status":"` + test.errorStatus +
+					`","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"` + test.errorCode + `"}]}}`))
+			}))
+			defer server.Close()
+
+			service := newTestMessagingService(t, server.URL)
+			_, err := service.Send(context.Background(), &push.Message{Token: "token", Title: "Hello"})
+			if err == nil {
+				t.Fatal("Send() error = nil, want an error")
+			}
+			if push.IsPermanent(err) != test.permanent || push.IsInvalidToken(err) != test.invalidToken {
+				t.Errorf("Send() error = %v, want permanent %v and invalid token %v", err, test.permanent, test.invalidToken)
+			}
+		})
+	}
+}
+
+// newTestMessagingService creates a MessagingService sending to the given FCM endpoint without authentication.
+func newTestMessagingService(t *testing.T, endpoint string) *MessagingService {
+	t.Helper()
+
+	ctx := context.Background()
+	app, err := firebase.NewApp(ctx, &firebase.Config{ProjectID: "zenith-test"},
+		option.WithEndpoint(endpoint), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := app.Messaging(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewMessagingService(&Messaging{client})
+}
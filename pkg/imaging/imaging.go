@@ -0,0 +1,120 @@
+package imaging
+
+import (
+	"bytes"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"golang.org/x/image/draw"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	GIF  = "image/gif"
+	WebP = "image/webp"
+
+	// MaxPixels limits the decoded size of an image to protect against decompression bombs.
+	MaxPixels = 40_000_000
+
+	jpegQuality = 85
+)
+
+// supportedTypes contains the content types accepted by Decode.
+var supportedTypes = map[string]bool{JPEG: true, PNG: true, GIF: true, WebP: true}
+
+// DetectContentType sniffs the content type of the given data using the first 512 bytes.
+func DetectContentType(data []byte) string {
+	return http.DetectContentType(data)
+}
+
+// Decode sniffs and decodes the given image data. Only JPEG, PNG, GIF and WebP images are accepted.
+// The EXIF orientation of JPEG images is applied to the decoded pixels, so that the image keeps its
+// orientation once it is re-encoded without metadata. It returns the decoded image and its sniffed content type.
+func Decode(data []byte) (image.Image, string, error) {
+	contentType := DetectContentType(data)
+	if !supportedTypes[contentType] {
+		return nil, "", errormessage.ErrUnsupportedImageType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errormessage.ErrInvalidImage
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", errormessage.ErrImageDimensionsTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errormessage.ErrInvalidImage
+	}
+
+	if contentType == JPEG {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	return img, contentType, nil
+}
+
+// Fit scales the image down so that neither side exceeds maxSize, preserving its aspect ratio.
+// Images that already fit are returned unchanged.
+func Fit(img image.Image, maxSize int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSize && h <= maxSize {
+		return img
+	}
+
+	if w >= h {
+		h = max(1, h*maxSize/w)
+		w = maxSize
+	} else {
+		w = max(1, w*maxSize/h)
+		h = maxSize
+	}
+
+	return scale(img, b, w, h)
+}
+
+// Thumbnail crops the center square of the image and scales it to size x size pixels.
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+
+	return scale(img, image.Rect(x, y, x+side, y+side), size, size)
+}
+
+// Encode encodes the image for the given source content type. Images with possible transparency are encoded as PNG,
+// everything else as JPEG. Encoding never writes metadata, so EXIF data of the source is stripped.
+// It returns the encoded data, its content type and its file extension.
+func Encode(img image.Image, sourceType string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if sourceType == JPEG {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), JPEG, ".jpg", nil
+	}
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, "", "", err
+	}
+
+	return buf.Bytes(), PNG, ".png", nil
+}
+
+// scale draws the src rectangle of img into a new w x h image.
+func scale(img image.Image, src image.Rectangle, w, h int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Over, nil)
+	return dst
+}
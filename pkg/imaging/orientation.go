@@ -0,0 +1,137 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+const (
+	jpegMarkerSOI       = 0xD8
+	jpegMarkerEOI       = 0xD9
+	jpegMarkerSOS       = 0xDA
+	jpegMarkerAPP1      = 0xE1
+	exifOrientationTag  = 0x0112
+	exifEntrySize       = 12
+	defaultOrientation  = 1
+	exifHeader          = "Exif\x00\x00"
+	tiffHeaderSize      = 8
+	maxExifOrientation  = 8
+	transposedThreshold = 5
+)
+
+// jpegOrientation reads the EXIF orientation of a JPEG image. It returns 1 (no transformation) when the image carries
+// no orientation or its metadata cannot be read.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != jpegMarkerSOI {
+		return defaultOrientation
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return defaultOrientation
+		}
+
+		marker := data[i+1]
+		if marker == jpegMarkerEOI || marker == jpegMarkerSOS {
+			return defaultOrientation
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return defaultOrientation
+		}
+
+		if marker == jpegMarkerAPP1 {
+			if orientation := exifOrientation(data[i+4 : i+2+size]); orientation != 0 {
+				return orientation
+			}
+		}
+
+		i += 2 + size
+	}
+
+	return defaultOrientation
+}
+
+// exifOrientation reads the orientation tag from the first IFD of an APP1 EXIF segment, returning 0 if it is absent.
+func exifOrientation(segment []byte) int {
+	if len(segment) < len(exifHeader)+tiffHeaderSize || string(segment[:len(exifHeader)]) != exifHeader {
+		return 0
+	}
+
+	tiff := segment[len(exifHeader):]
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < tiffHeaderSize || offset+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*exifEntrySize
+		if entry+exifEntrySize > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < defaultOrientation || orientation > maxExifOrientation {
+				return 0
+			}
+			return orientation
+		}
+	}
+
+	return 0
+}
+
+// applyOrientation flips and rotates the image according to the EXIF orientation value.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= defaultOrientation || orientation > maxExifOrientation {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if orientation >= transposedThreshold {
+		dw, dh = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,21 @@
+package mail
+
+import (
+	"github.com/arifai/zenith/cmd/wire/logger"
+	"go.uber.org/zap"
+)
+
+// Console is a Transport logging every message instead of sending it.
+type Console struct{}
+
+var log = logger.ProvideLogger()
+
+// NewConsole creates a Console transport.
+func NewConsole() *Console {
+	return &Console{}
+}
+
+func (c *Console) Send(from string, to []string, message []byte) error {
+	log.Info("mail", zap.String("from", from), zap.Strings("to", to), zap.String("message", string(message)))
+	return nil
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File is a Transport writing every message to an .eml file below a directory instead of sending it, e.g. to inspect
+// mails during development. Files are named after the time they were written, so they sort chronologically.
+type File struct {
+	dir string
+}
+
+// NewFile creates a File transport writing below dir.
+func NewFile(dir string) *File {
+	return &File{dir: dir}
+}
+
+func (f *File) Send(_ string, _ []string, message []byte) error {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000Z"), uuid.NewString())
+	tmp, err := os.CreateTemp(f.dir, ".mail-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(message); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(f.dir, name))
+}
@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"slices"
+	"sync"
+)
+
+// Memory is a Transport capturing every message in memory instead of sending it, for tests.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Message is a message captured by a Memory transport.
+type Message struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// NewMemory creates an empty Memory transport.
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(from string, to []string, message []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, Message{From: from, To: slices.Clone(to), Data: slices.Clone(message)})
+	return nil
+}
+
+// Messages returns the messages captured so far, oldest first.
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.messages)
+}
+
+// Reset drops every captured message.
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = nil
+}
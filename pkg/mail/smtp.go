@@ -0,0 +1,143 @@
+package mail
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP is a Transport delivering messages through an SMTP server.
+type SMTP struct {
+	options SMTPOptions
+}
+
+// SMTPOptions configures the SMTP server a Transport delivers through. Encryption selects how the connection is
+// secured:
+//   - EncryptionTLS connects over implicit TLS, usually on port 465.
+//   - EncryptionSTARTTLS upgrades the connection with STARTTLS and fails if the server does not offer it.
+//   - EncryptionNone never encrypts the connection. Credentials are then only sent to a server on localhost.
+//   - An empty encryption upgrades the connection with STARTTLS whenever the server offers it.
+//
+// Credentials are required to be accepted: sending fails if Username is set and the server does not offer AUTH.
+// Timeout bounds the whole session, from connecting to the server until the message is handed over.
+type SMTPOptions struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	Encryption string
+	Timeout    time.Duration
+}
+
+const (
+	EncryptionSTARTTLS = "starttls"
+	EncryptionTLS      = "tls"
+	EncryptionNone     = "none"
+
+	// defaultSMTPTimeout bounds a session with the SMTP server when no timeout is configured.
+	defaultSMTPTimeout = 30 * time.Second
+)
+
+// NewSMTP creates an SMTP transport with the given options.
+func NewSMTP(options SMTPOptions) (*SMTP, error) {
+	switch options.Encryption {
+	case "", EncryptionSTARTTLS, EncryptionTLS, EncryptionNone:
+	default:
+		return nil, fmt.Errorf("%s: %s", errormessage.ErrUnknownSMTPEncryptionText, options.Encryption)
+	}
+
+	if options.Timeout == 0 {
+		options.Timeout = defaultSMTPTimeout
+	}
+
+	return &SMTP{options: options}, nil
+}
+
+func (s *SMTP) Send(from string, to []string, message []byte) error {
+	client, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	if s.options.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errormessage.ErrSMTPAuthUnsupported
+		}
+		if err := client.Auth(smtp.PlainAuth("", s.options.Username, s.options.Password, s.options.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// dial connects to the SMTP server and secures the connection as configured.
+func (s *SMTP) dial() (*smtp.Client, error) {
+	address := net.JoinHostPort(s.options.Host, strconv.Itoa(s.options.Port))
+	tlsConfig := &tls.Config{ServerName: s.options.Host}
+	dialer := &net.Dialer{Timeout: s.options.Timeout}
+
+	var conn net.Conn
+	var err error
+	if s.options.Encryption == EncryptionTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.options.Timeout)); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, s.options.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	if s.options.Encryption == EncryptionTLS || s.options.Encryption == EncryptionNone {
+		return client, nil
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	} else if s.options.Encryption == EncryptionSTARTTLS {
+		_ = client.Close()
+		return nil, errormessage.ErrSTARTTLSUnsupported
+	}
+
+	return client, nil
+}
@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"fmt"
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/pkg/errormessage"
+)
+
+// Transport delivers composed email messages. The message is a complete RFC 5322 message, headers included, while
+// from and to form the envelope.
+type Transport interface {
+	// Send delivers the message from the sender to every recipient.
+	Send(from string, to []string, message []byte) error
+}
+
+const (
+	SMTPDriver    = "smtp"
+	FileDriver    = "file"
+	ConsoleDriver = "console"
+	MemoryDriver  = "memory"
+)
+
+// New creates the Transport selected by the MailDriver setting of the provided configuration.
+func New(cfg *config.Config) (Transport, error) {
+	switch cfg.MailDriver {
+	case SMTPDriver, "":
+		return NewSMTP(SMTPOptions{
+			Host:       cfg.SMTPHost,
+			Port:       cfg.SMTPPort,
+			Username:   cfg.SMTPUsername,
+			Password:   cfg.SMTPPassword,
+			Encryption: cfg.SMTPEncryption,
+		})
+	case FileDriver:
+		return NewFile(cfg.MailFileDir), nil
+	case ConsoleDriver:
+		return NewConsole(), nil
+	case MemoryDriver:
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("%s: %s", errormessage.ErrUnknownMailDriverText, cfg.MailDriver)
+	}
+}
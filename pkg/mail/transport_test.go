@@ -0,0 +1,327 @@
+package mail
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testMessage = "From: noreply@example.com\r\nTo: john@example.com\r\nSubject: Hello\r\n\r\nHello John\r\n"
+
+// fakeSMTP is a minimal SMTP server accepting a single session, optionally offering AUTH PLAIN, and recording the
+// envelope, credentials and data it received.
+type fakeSMTP struct {
+	listener net.Listener
+	auth     bool
+	done     chan struct{}
+
+	mu          sync.Mutex
+	from        string
+	to          []string
+	data        string
+	credentials string
+}
+
+func TestNew(t *testing.T) {
+	tests := map[string]func(Transport) bool{
+		"":            func(transport Transport) bool { _, ok := transport.(*SMTP); return ok },
+		SMTPDriver:    func(transport Transport) bool { _, ok := transport.(*SMTP); return ok },
+		FileDriver:    func(transport Transport) bool { _, ok := transport.(*File); return ok },
+		ConsoleDriver: func(transport Transport) bool { _, ok := transport.(*Console); return ok },
+		MemoryDriver:  func(transport Transport) bool { _, ok := transport.(*Memory); return ok },
+	}
+
+	for driver, isDriver := range tests {
+		transport, err := New(&config.Config{MailDriver: driver, MailFileDir: t.TempDir()})
+		if err != nil {
+			t.Fatalf("New(%q) error = %v", driver, err)
+		}
+		if !isDriver(transport) {
+			t.Errorf("New(%q) = %T, want the transport of the driver", driver, transport)
+		}
+	}
+
+	if _, err := New(&config.Config{MailDriver: "carrier-pigeon"}); err == nil || !strings.Contains(err.Error(), errormessage.ErrUnknownMailDriverText) {
+		t.Errorf("New() error = %v, want an unknown mail driver", err)
+	}
+	if _, err := New(&config.Config{MailDriver: SMTPDriver, SMTPEncryption: "ssl"}); err == nil || !strings.Contains(err.Error(), errormessage.ErrUnknownSMTPEncryptionText) {
+		t.Errorf("New() error = %v, want an unknown SMTP encryption", err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	memory := NewMemory()
+	to := []string{"john@example.com"}
+	message := []byte(testMessage)
+
+	if err := memory.Send("noreply@example.com", to, message); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	to[0], message[0] = "jane@example.com", 'X'
+
+	messages := memory.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Messages() returned %d messages, want 1", len(messages))
+	}
+	if messages[0].From != "noreply@example.com" || messages[0].To[0] != "john@example.com" || string(messages[0].Data) != testMessage {
+		t.Errorf("Messages()[0] = %+v, want the message as it was sent", messages[0])
+	}
+
+	memory.Reset()
+	if len(memory.Messages()) != 0 {
+		t.Error("Reset() kept captured messages")
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	file := NewFile(dir)
+
+	for i := 0; i < 2; i++ {
+		if err := file.Send("noreply@example.com", []string{"john@example.com"}, []byte(testMessage+strconv.Itoa(i))); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("wrote %d files, want 2", len(entries))
+	}
+
+	for i, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".eml") {
+			t.Errorf("file %q is not an .eml file", entry.Name())
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := testMessage + strconv.Itoa(i); string(data) != want {
+			t.Errorf("file %d = %q, want %q", i, data, want)
+		}
+	}
+}
+
+func TestSMTP(t *testing.T) {
+	server := newFakeSMTP(t, true)
+	smtp, err := NewSMTP(SMTPOptions{
+		Host:       "localhost",
+		Port:       server.port(),
+		Username:   "mailer",
+		Password:   "secret",
+		Encryption: EncryptionNone,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := smtp.Send("noreply@example.com", []string{"john@example.com", "jane@example.com"}, []byte(testMessage)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	<-server.done
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.from != "noreply@example.com" {
+		t.Errorf("MAIL FROM = %q, want %q", server.from, "noreply@example.com")
+	}
+	if strings.Join(server.to, ",") != "john@example.com,jane@example.com" {
+		t.Errorf("RCPT TO = %v, want both recipients", server.to)
+	}
+	if server.data != testMessage {
+		t.Errorf("DATA = %q, want %q", server.data, testMessage)
+	}
+	if server.credentials != "\x00mailer\x00secret" {
+		t.Errorf("AUTH PLAIN = %q, want the username and password", server.credentials)
+	}
+}
+
+func TestSMTPRequiresSTARTTLS(t *testing.T) {
+	server := newFakeSMTP(t, false)
+	smtp, err := NewSMTP(SMTPOptions{Host: "localhost", Port: server.port(), Encryption: EncryptionSTARTTLS})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = smtp.Send("noreply@example.com", []string{"john@example.com"}, []byte(testMessage))
+	if !errors.Is(err, errormessage.ErrSTARTTLSUnsupported) {
+		t.Fatalf("Send() error = %v, want %v", err, errormessage.ErrSTARTTLSUnsupported)
+	}
+}
+
+func TestSMTPRequiresAUTH(t *testing.T) {
+	server := newFakeSMTP(t, false)
+	smtp, err := NewSMTP(SMTPOptions{Host: "localhost", Port: server.port(), Username: "mailer", Password: "secret", Encryption: EncryptionNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = smtp.Send("noreply@example.com", []string{"john@example.com"}, []byte(testMessage))
+	if !errors.Is(err, errormessage.ErrSMTPAuthUnsupported) {
+		t.Fatalf("Send() error = %v, want %v", err, errormessage.ErrSMTPAuthUnsupported)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.from != "" {
+		t.Errorf("MAIL FROM = %q, want the message not to be sent", server.from)
+	}
+}
+
+func TestSMTPTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	// The server accepts the connection but never greets the client.
+	stop := make(chan struct{})
+	t.Cleanup(func() {
+		close(stop)
+	})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		<-stop
+		_ = conn.Close()
+	}()
+
+	smtp, err := NewSMTP(SMTPOptions{Host: "localhost", Port: listener.Addr().(*net.TCPAddr).Port, Encryption: EncryptionNone, Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.Send("noreply@example.com", []string{"john@example.com"}, []byte(testMessage))
+	}()
+
+	select {
+	case err := <-done:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Fatalf("Send() error = %v, want a timeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send() did not time out")
+	}
+}
+
+// newFakeSMTP starts a fakeSMTP on a loopback port.
+func newFakeSMTP(t *testing.T, auth bool) *fakeSMTP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	server := &fakeSMTP{listener: listener, auth: auth, done: make(chan struct{})}
+	go server.serve()
+
+	return server
+}
+
+// port returns the port the server listens on.
+func (f *fakeSMTP) port() int {
+	return f.listener.Addr().(*net.TCPAddr).Port
+}
+
+// serve accepts a single session and answers its commands until the client quits.
+func (f *fakeSMTP) serve() {
+	defer close(f.done)
+
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		_, _ = conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(command, " ", 2)[0])
+
+		switch verb {
+		case "EHLO":
+			if f.auth {
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			} else {
+				reply("250 localhost")
+			}
+		case "AUTH":
+			fields := strings.Fields(command)
+			credentials, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+			f.mu.Lock()
+			f.credentials = string(credentials)
+			f.mu.Unlock()
+			reply("235 Authentication successful")
+		case "MAIL":
+			f.mu.Lock()
+			f.from = strings.Trim(strings.TrimPrefix(command, "MAIL FROM:"), "<>")
+			f.mu.Unlock()
+			reply("250 OK")
+		case "RCPT":
+			f.mu.Lock()
+			f.to = append(f.to, strings.Trim(strings.TrimPrefix(command, "RCPT TO:"), "<>"))
+			f.mu.Unlock()
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			f.mu.Lock()
+			f.data = data.String()
+			f.mu.Unlock()
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package push
+
+import (
+	"context"
+	"errors"
+)
+
+type (
+	// Message is a push notification addressed to a single device registration token, or to all devices subscribed to
+	// a topic or matching a condition expression such as "'news' in topics && 'sports' in topics". Exactly one of Token,
+	// Topic and Condition is set. Provider names the push service that issued the token, such as "apns".
+	Message struct {
+		Token     string
+		Topic     string
+		Condition string
+		Provider  string
+		Title     string
+		Body      string
+		Image     string
+		Data      map[string]string
+		Options   *Options
+		Keys      *Keys
+	}
+
+	// Keys are the message encryption keys of a Web Push subscription, whose endpoint is the Token of the message.
+	Keys struct {
+		P256dh string
+		Auth   string
+	}
+
+	// Options are the platform-specific delivery options of a message. Every provider applies the options supported by
+	// its platform and ignores the others.
+	Options struct {
+		// ChannelID is the Android notification channel the notification is posted to.
+		ChannelID string `json:"channel_id,omitempty"`
+
+		// ClickAction is the Android intent filter or iOS category opened on click. On the web it is the link opened on
+		// click and must use HTTPS.
+		ClickAction string `json:"click_action,omitempty"`
+
+		// CollapseKey identifies a group of notifications of which only the latest is shown, used as the Android collapse
+		// key, the APNs collapse ID and the Web Push topic.
+		CollapseKey string `json:"collapse_key,omitempty"`
+
+		// TimeToLive is the number of seconds the push service keeps an undelivered notification. Zero means deliver now
+		// or never, nil leaves the default of the push service.
+		TimeToLive *int `json:"time_to_live,omitempty"`
+
+		// Priority is either PriorityHigh or PriorityNormal. High priority wakes sleeping devices.
+		Priority string `json:"priority,omitempty"`
+
+		// Badge is the number shown on the iOS app icon, nil leaves the badge unchanged.
+		Badge *int `json:"badge,omitempty"`
+
+		// Sound is the sound played on Android and iOS, "default" plays the default sound of the device.
+		Sound string `json:"sound,omitempty"`
+
+		// ThreadID groups iOS notifications in the notification center.
+		ThreadID string `json:"thread_id,omitempty"`
+
+		// MutableContent lets an iOS notification service extension modify the notification before it is shown.
+		MutableContent bool `json:"mutable_content,omitempty"`
+	}
+
+	// Result is the outcome of delivering one of the messages of a batch.
+	Result struct {
+		Response string
+		Err      error
+	}
+
+	// Provider delivers push notifications to a push service such as FCM. Send returns the raw response of the
+	// provider, which is recorded along with the notification, for example the message ID assigned by FCM.
+	Provider interface {
+		Send(ctx context.Context, message *Message) (response string, err error)
+	}
+
+	// BatchProvider is implemented by providers that deliver many messages, each addressed to a single token, at once.
+	// The results are in the order of the messages. The returned error is only set if the whole batch failed.
+	BatchProvider interface {
+		Provider
+		SendEach(ctx context.Context, messages []*Message) ([]Result, error)
+	}
+
+	// TopicManager manages the topic subscriptions of registration tokens at the push service.
+	TopicManager interface {
+		Subscribe(ctx context.Context, topic string, tokens []string) error
+		Unsubscribe(ctx context.Context, topic string, tokens []string) error
+	}
+
+	// Disabled is used in place of a push service when none is configured. Every operation fails with ErrDisabled.
+	Disabled struct{}
+
+	// Error is a delivery error classified by the provider. Permanent errors fail the same way on every attempt and must
+	// not be retried, InvalidToken errors additionally mean that the registration token is dead and must be pruned.
+	// Errors that are not an Error are treated as transient.
+	Error struct {
+		Err          error
+		Permanent    bool
+		InvalidToken bool
+	}
+)
+
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+)
+
+// ErrDisabled is returned by Disabled.
+var ErrDisabled = &Error{Err: errors.New("push service is not configured"), Permanent: true}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (Disabled) Send(context.Context, *Message) (string, error) {
+	return "", ErrDisabled
+}
+
+func (Disabled) Subscribe(context.Context, string, []string) error {
+	return ErrDisabled
+}
+
+func (Disabled) Unsubscribe(context.Context, string, []string) error {
+	return ErrDisabled
+}
+
+// IsPermanent reports whether err is a delivery error that retrying cannot fix.
+func IsPermanent(err error) bool {
+	var pushErr *Error
+	return errors.As(err, &pushErr) && pushErr.Permanent
+}
+
+// IsInvalidToken reports whether err means that the registration token of the message is no longer valid.
+func IsInvalidToken(err error) bool {
+	var pushErr *Error
+	return errors.As(err, &pushErr) && pushErr.InvalidToken
+}
+
+// Chunk splits tokens or messages into consecutive chunks of at most size items, matching the per-request limits of
+// push services.
+func Chunk[T any](items []T, size int) [][]T {
+	if len(items) == 0 {
+		return nil
+	}
+
+	chunks := make([][]T, 0, (len(items)+size-1)/size)
+	for size < len(items) {
+		items, chunks = items[size:], append(chunks, items[:size:size])
+	}
+
+	return append(chunks, items)
+}
+
+// Validate reports whether the options hold values every provider accepts.
+func (o *Options) Validate() error {
+	if o.Priority != "" && o.Priority != PriorityHigh && o.Priority != PriorityNormal {
+		return errors.New("invalid push priority")
+	}
+
+	if o.TimeToLive != nil && *o.TimeToLive < 0 {
+		return errors.New("invalid push time to live")
+	}
+
+	if o.Badge != nil && *o.Badge < 0 {
+		return errors.New("invalid push badge")
+	}
+
+	return nil
+}
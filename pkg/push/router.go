@@ -0,0 +1,104 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"github.com/arifai/zenith/pkg/errormessage"
+)
+
+// ErrMissingResult is the result of a message a batch provider returned no result for.
+var ErrMissingResult = errors.New(errormessage.ErrMissingPushResultText)
+
+// Router is a Provider that delivers messages through the provider registered for the push service that issued their
+// token, and through the fallback provider otherwise. Messages to Web Push subscriptions, which carry Keys, use the
+// subscription provider. Topic and condition messages are not bound to a token and always use the fallback.
+type Router struct {
+	fallback      Provider
+	subscriptions Provider
+	providers     map[string]Provider
+}
+
+// NewRouter creates a Router delivering through fallback unless a provider is registered for the push service of the
+// message.
+func NewRouter(fallback Provider) *Router {
+	return &Router{fallback: fallback, providers: make(map[string]Provider)}
+}
+
+// Handle registers the provider of the tokens issued by the named push service.
+func (r *Router) Handle(service string, provider Provider) *Router {
+	r.providers[service] = provider
+	return r
+}
+
+// HandleSubscriptions registers the provider of Web Push subscriptions.
+func (r *Router) HandleSubscriptions(provider Provider) *Router {
+	r.subscriptions = provider
+	return r
+}
+
+func (r *Router) Send(ctx context.Context, message *Message) (string, error) {
+	return r.route(message).Send(ctx, message)
+}
+
+// SendEach delivers every message through the provider of its push service, in a single batch per provider if it supports
+// batches and one by one otherwise.
+func (r *Router) SendEach(ctx context.Context, messages []*Message) ([]Result, error) {
+	results := make([]Result, len(messages))
+
+	var providers []Provider
+	indexes := make(map[Provider][]int)
+	for i, message := range messages {
+		provider := r.route(message)
+		if _, ok := indexes[provider]; !ok {
+			providers = append(providers, provider)
+		}
+		indexes[provider] = append(indexes[provider], i)
+	}
+
+	for _, provider := range providers {
+		batch, ok := provider.(BatchProvider)
+		if !ok {
+			for _, i := range indexes[provider] {
+				response, err := provider.Send(ctx, messages[i])
+				results[i] = Result{Response: response, Err: err}
+			}
+			continue
+		}
+
+		routed := make([]*Message, 0, len(indexes[provider]))
+		for _, i := range indexes[provider] {
+			routed = append(routed, messages[i])
+		}
+
+		batchResults, err := batch.SendEach(ctx, routed)
+		for j, i := range indexes[provider] {
+			switch {
+			case err != nil:
+				results[i] = Result{Err: err}
+			case j < len(batchResults):
+				results[i] = batchResults[j]
+			default:
+				results[i] = Result{Err: ErrMissingResult}
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// route returns the provider of a message.
+func (r *Router) route(message *Message) Provider {
+	if message.Topic != "" || message.Condition != "" {
+		return r.fallback
+	}
+
+	if message.Keys != nil && r.subscriptions != nil {
+		return r.subscriptions
+	}
+
+	if provider, ok := r.providers[message.Provider]; ok {
+		return provider
+	}
+
+	return r.fallback
+}
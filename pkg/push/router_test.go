@@ -0,0 +1,89 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// recorder is a Provider recording the messages it was asked to send.
+type recorder struct {
+	sent []*Message
+}
+
+// batchRecorder is a BatchProvider recording the batches it was asked to send.
+type batchRecorder struct {
+	recorder
+	batches [][]*Message
+}
+
+func (r *recorder) Send(_ context.Context, message *Message) (string, error) {
+	r.sent = append(r.sent, message)
+	return "single:" + message.Token, nil
+}
+
+func (b *batchRecorder) SendEach(_ context.Context, messages []*Message) ([]Result, error) {
+	b.batches = append(b.batches, messages)
+
+	results := make([]Result, 0, len(messages))
+	for _, message := range messages {
+		results = append(results, Result{Response: "batch:" + message.Token})
+	}
+
+	return results, nil
+}
+
+func TestRouterSendEachKeepsPayloadPerMessage(t *testing.T) {
+	fcm, apns := &batchRecorder{}, &recorder{}
+	router := NewRouter(fcm).Handle("apns", apns)
+
+	messages := []*Message{
+		{Token: "a", Provider: "fcm", Data: map[string]string{"push_id": "1"}},
+		{Token: "b", Provider: "apns", Data: map[string]string{"push_id": "2"}},
+		{Token: "c", Provider: "fcm", Data: map[string]string{"push_id": "3"}},
+	}
+
+	results, err := router.SendEach(context.Background(), messages)
+	if err != nil {
+		t.Fatalf("SendEach() error = %v", err)
+	}
+
+	want := []string{"batch:a", "single:b", "batch:c"}
+	for i, result := range results {
+		if result.Err != nil || result.Response != want[i] {
+			t.Errorf("result %d = %+v, want response %q", i, result, want[i])
+		}
+	}
+
+	if len(fcm.batches) != 1 || len(fcm.batches[0]) != 2 {
+		t.Fatalf("batches = %v, want a single batch of two messages", fcm.batches)
+	}
+	if fcm.batches[0][0].Data["push_id"] != "1" || fcm.batches[0][1].Data["push_id"] != "3" {
+		t.Errorf("batch payloads = %v, %v, want push IDs 1 and 3", fcm.batches[0][0].Data, fcm.batches[0][1].Data)
+	}
+	if len(apns.sent) != 1 || apns.sent[0].Data["push_id"] != "2" {
+		t.Errorf("sent = %v, want the message with push ID 2", apns.sent)
+	}
+}
+
+// shortBatch is a BatchProvider returning fewer results than messages.
+type shortBatch struct{ recorder }
+
+func (*shortBatch) SendEach(context.Context, []*Message) ([]Result, error) {
+	return []Result{{Response: "first"}}, nil
+}
+
+func TestRouterSendEachMissingResult(t *testing.T) {
+	router := NewRouter(&shortBatch{})
+
+	results, err := router.SendEach(context.Background(), []*Message{{Token: "a"}, {Token: "b"}})
+	if err != nil {
+		t.Fatalf("SendEach() error = %v", err)
+	}
+	if results[0].Response != "first" {
+		t.Errorf("result 0 = %+v, want response %q", results[0], "first")
+	}
+	if !errors.Is(results[1].Err, ErrMissingResult) {
+		t.Errorf("result 1 error = %v, want %v", results[1].Err, ErrMissingResult)
+	}
+}
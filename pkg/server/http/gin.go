@@ -1,17 +1,54 @@
 package http
 
 import (
+	"fmt"
 	"github.com/arifai/zenith/internal/handler"
 	"github.com/arifai/zenith/internal/middleware"
 	"github.com/arifai/zenith/pkg/api"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"regexp"
+	"time"
 )
 
-func ProvideGinEngine(accountHandler *handler.AccountHandler, notificationHandler *handler.NotificationHandler, mid *middleware.StrictAuthMiddleware) *gin.Engine {
-	engine := gin.Default()
+// accessTokenPattern matches the value of the access_token query parameter, which carries the access token of stream
+// requests and must never reach the access log.
+var accessTokenPattern = regexp.MustCompile(`([?&]access_token=)[^&]*`)
+
+func ProvideGinEngine(accountHandler *handler.AccountHandler, notificationHandler *handler.NotificationHandler, apiKeyHandler *handler.APIKeyHandler, oauthHandler *handler.OAuthHandler, topicHandler *handler.TopicHandler, webPushHandler *handler.WebPushHandler, preferenceHandler *handler.NotificationPreferenceHandler, templateHandler *handler.NotificationTemplateHandler, campaignHandler *handler.CampaignHandler, receiptHandler *handler.PushReceiptHandler, mid *middleware.StrictAuthMiddleware, apiKeyMid *middleware.APIKeyAuthMiddleware) *gin.Engine {
+	engine := gin.New()
+	engine.Use(gin.LoggerWithFormatter(logFormatter), gin.Recovery())
 	engine.Use(otelgin.Middleware("zenith-server"))
-	api.SetupRouter(engine, accountHandler, notificationHandler, mid)
+	api.SetupRouter(engine, accountHandler, notificationHandler, apiKeyHandler, oauthHandler, topicHandler, webPushHandler, preferenceHandler, templateHandler, campaignHandler, receiptHandler, mid, apiKeyMid)
 
 	return engine
 }
+
+// logFormatter formats access log lines like the default logger of gin, with the access token of the query redacted.
+func logFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		redactAccessToken(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactAccessToken replaces the value of the access_token query parameter of the path.
+func redactAccessToken(path string) string {
+	return accessTokenPattern.ReplaceAllString(path, "${1}REDACTED")
+}
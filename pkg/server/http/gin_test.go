@@ -0,0 +1,32 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"strings"
+	"testing"
+)
+
+func TestRedactAccessToken(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/notification/stream", "/api/v1/notification/stream"},
+		{"/api/v1/notification/stream?access_token=v4.local.secret", "/api/v1/notification/stream?access_token=REDACTED"},
+		{"/api/v1/notification/stream?last_event_id=1&access_token=secret&x=1", "/api/v1/notification/stream?last_event_id=1&access_token=REDACTED&x=1"},
+		{"/api/v1/notification/stream?my_access_token=kept", "/api/v1/notification/stream?my_access_token=kept"},
+	}
+
+	for _, tt := range tests {
+		if got := redactAccessToken(tt.path); got != tt.want {
+			t.Errorf("redactAccessToken(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLogFormatterRedactsAccessToken(t *testing.T) {
+	line := logFormatter(gin.LogFormatterParams{Method: "GET", StatusCode: 200, Path: "/stream?access_token=secret"})
+	if strings.Contains(line, "secret") {
+		t.Errorf("log line %q contains the access token", line)
+	}
+}
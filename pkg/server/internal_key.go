@@ -0,0 +1,47 @@
+package server
+
+import (
+	"fmt"
+
+	cfg "github.com/arifai/zenith/cmd/wire/config"
+	repo "github.com/arifai/zenith/cmd/wire/repository"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/crypto"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/google/uuid"
+)
+
+// CreateInternalAPIKey creates an API key for an internal service, owned by the organization running the server and
+// granted the given scopes. The plaintext key is returned once and never stored.
+func CreateInternalAPIKey(name string, scopes []string) (string, error) {
+	for _, scope := range scopes {
+		if !model.KnownScope(scope) {
+			return "", fmt.Errorf("%w: %q", errormessage.ErrUnknownScope, scope)
+		}
+	}
+
+	db, err := connectDatabase(cfg.ProvideConfig())
+	if err != nil {
+		return "", err
+	}
+
+	key, prefix, hash, err := crypto.GenerateAPIKey()
+	if err != nil {
+		return "", err
+	}
+
+	apiKey := &model.APIKey{
+		OwnerType: model.OrganizationOwner,
+		OwnerID:   uuid.Nil,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    scopes,
+	}
+
+	if err := repo.ProvideAPIKeyRepository(db, nil).Create(apiKey); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
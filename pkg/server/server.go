@@ -5,13 +5,22 @@ import (
 	"fmt"
 	"github.com/arifai/zenith/cmd/wire"
 	cfg "github.com/arifai/zenith/cmd/wire/config"
+	"github.com/arifai/zenith/cmd/wire/firebase"
 	"github.com/arifai/zenith/cmd/wire/logger"
 	"github.com/arifai/zenith/cmd/wire/migration"
+	svc "github.com/arifai/zenith/cmd/wire/service"
 	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/internal/model"
+	"github.com/arifai/zenith/pkg/apns"
 	"github.com/arifai/zenith/pkg/database"
 	"github.com/arifai/zenith/pkg/errormessage"
+	fcm "github.com/arifai/zenith/pkg/firebase"
+	"github.com/arifai/zenith/pkg/mail"
+	"github.com/arifai/zenith/pkg/push"
+	"github.com/arifai/zenith/pkg/storage"
 	"github.com/arifai/zenith/pkg/tracer"
 	"github.com/arifai/zenith/pkg/utils"
+	"github.com/arifai/zenith/pkg/webpush"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
@@ -91,7 +100,61 @@ func connectRedis(config *config.Config) (*redis.Client, error) {
 func setupRouter(db *gorm.DB, rdb *redis.Client, config *config.Config) error {
 	migrate(db)
 	utils.SetupTranslation()
-	rtr := wire.InitializeRouter(db, rdb, config, log)
+
+	transport, err := mail.New(config)
+	if err != nil {
+		return fmt.Errorf(errormessage.ErrFailedToInitializeMailText+"%v", err)
+	}
+
+	// The mail templates are resolved relative to the working directory, the root of the repository.
+	mailer := utils.NewMailer(*config, transport, os.DirFS("."), config.MailQueueSize, config.MailWorkers)
+	defer mailer.Shutdown()
+
+	if config.DigestInterval > 0 {
+		digest := svc.ProvideNotificationDigest(db, rdb, config, log, mailer)
+		digest.Start()
+		defer digest.Shutdown()
+	}
+
+	if config.ReadRetention > 0 || config.PushRetention > 0 {
+		purger := svc.ProvideRetentionPurger(db, rdb, config, log)
+		purger.Start()
+		defer purger.Shutdown()
+	}
+
+	store, err := storage.New(config)
+	if err != nil {
+		return fmt.Errorf(errormessage.ErrFailedToInitializeStorageText+"%v", err)
+	}
+
+	messaging, err := firebaseMessaging(config)
+	if err != nil {
+		return err
+	}
+
+	var topics push.TopicManager = push.Disabled{}
+	if messaging != nil {
+		topics = messaging
+	}
+
+	provider, err := pushRouter(config, messaging)
+	if err != nil {
+		return err
+	}
+	if provider != nil {
+		dispatcher := svc.ProvidePushDispatcher(db, rdb, config, log, provider)
+		dispatcher.Start()
+		defer dispatcher.Shutdown()
+
+		scheduler := svc.ProvideCampaignScheduler(db, rdb, config, log)
+		scheduler.Start()
+		defer scheduler.Shutdown()
+	}
+
+	rtr := wire.InitializeRouter(db, rdb, config, log, mailer, store, topics)
+	if storage.IsLocal(config.StorageDriver) {
+		rtr.Static(config.StorageRoute, config.StorageLocalRoot)
+	}
 
 	if err := rtr.SetTrustedProxies([]string{config.AppHost}); err != nil {
 		return fmt.Errorf(errormessage.ErrFailedSetTrustedProxiesText+"%v", err)
@@ -105,8 +168,74 @@ func setupRouter(db *gorm.DB, rdb *redis.Client, config *config.Config) error {
 	return nil
 }
 
+// firebaseMessaging initializes the delivery of push notifications and the management of topics through FCM. Without
+// Firebase credentials it returns nil and topics can not be managed.
+func firebaseMessaging(config *config.Config) (*fcm.MessagingService, error) {
+	if config.FirebaseCredFile == "" {
+		return nil, nil
+	}
+
+	provider, err := firebase.ProvideFirebaseMessagingService(config.FirebaseCredFile)
+	if err != nil {
+		return nil, fmt.Errorf(errormessage.ErrFailedToInitPushProviderText+"%v", err)
+	}
+
+	return provider, nil
+}
+
+// pushRouter routes push notifications to the configured providers by the push service that issued the device token:
+// APNs device tokens are sent through APNs, and fail without being pruned when no APNs signing key is configured, Web
+// Push subscriptions through the Web Push protocol when a VAPID key is configured, and every other notification, FCM
+// tokens of iOS devices included, through FCM. Without any provider it returns nil, pending notifications then stay in
+// the table.
+func pushRouter(config *config.Config, messaging *fcm.MessagingService) (*push.Router, error) {
+	var fallback push.Provider = push.Disabled{}
+	if messaging != nil {
+		fallback = messaging
+	}
+
+	router := push.NewRouter(fallback).Handle(string(model.ProviderAPNs), push.Disabled{})
+	if config.APNsKeyFile != "" {
+		client, err := apns.New(apns.Config{
+			KeyFile:    config.APNsKeyFile,
+			KeyID:      config.APNsKeyID,
+			TeamID:     config.APNsTeamID,
+			Topic:      config.APNsTopic,
+			Production: config.APNsProduction,
+		})
+		if err != nil {
+			return nil, fmt.Errorf(errormessage.ErrFailedToInitPushProviderText+"%v", err)
+		}
+		router.Handle(string(model.ProviderAPNs), client)
+	}
+
+	if config.VAPIDPrivateKey != "" {
+		client, err := webpush.New(webpush.Config{PrivateKey: config.VAPIDPrivateKey, Subject: config.VAPIDSubject})
+		if err != nil {
+			return nil, fmt.Errorf(errormessage.ErrFailedToInitPushProviderText+"%v", err)
+		}
+		router.HandleSubscriptions(client)
+	}
+
+	if messaging == nil && config.APNsKeyFile == "" && config.VAPIDPrivateKey == "" {
+		log.Warn("No push provider is configured, push notifications will not be delivered")
+		return nil, nil
+	}
+
+	return router, nil
+}
+
 func migrate(db *gorm.DB) {
 	migrator := migration.ProvideMigration(db, uuid.New(), log)
 	migrator.AccountMigration()
 	migrator.NotificationMigration()
+	migrator.DeviceTokenMigration()
+	migrator.TopicMigration()
+	migrator.NotificationPreferenceMigration()
+	migrator.NotificationTemplateMigration()
+	migrator.CampaignMigration()
+	migrator.PushReceiptMigration()
+	migrator.PurgedRecordMigration()
+	migrator.APIKeyMigration()
+	migrator.OAuthMigration()
 }
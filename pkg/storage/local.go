@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local is a Storage that keeps objects on the local filesystem below a root directory.
+type Local struct {
+	root      string
+	publicURL string
+}
+
+// NewLocal creates a Local storage writing below root and serving objects from publicURL.
+func NewLocal(root, publicURL string) *Local {
+	return &Local{root: root, publicURL: publicURL}
+}
+
+func (l *Local) Put(_ context.Context, key string, body io.Reader, _ string) (string, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return l.URL(key), nil
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return joinURL(l.publicURL, key)
+}
+
+// path resolves the key to a filesystem path, rejecting keys that would escape the root directory.
+func (l *Local) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(strings.TrimLeft(key, "/")))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", errormessage.ErrInvalidStorageKey
+	}
+
+	return filepath.Join(l.root, cleaned), nil
+}
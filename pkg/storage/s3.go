@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// S3Options contains the settings of an S3-compatible object storage such as AWS S3, MinIO or a local stand-in.
+	S3Options struct {
+		Endpoint     string
+		Region       string
+		Bucket       string
+		AccessKey    string
+		SecretKey    string
+		UsePathStyle bool
+		PublicURL    string
+		HTTPClient   *http.Client
+	}
+
+	// S3 is a Storage that keeps objects in an S3-compatible bucket, signing requests with AWS Signature Version 4.
+	S3 struct {
+		endpoint  *url.URL
+		options   S3Options
+		client    *http.Client
+		nowFunc   func() time.Time
+		publicURL string
+	}
+)
+
+const (
+	s3Service          = "s3"
+	s3Algorithm        = "AWS4-HMAC-SHA256"
+	s3TimeFormat       = "20060102T150405Z"
+	s3DateFormat       = "20060102"
+	s3DefaultRegion    = "us-east-1"
+	s3ErrorBodyMaxSize = 1024
+)
+
+// NewS3 creates an S3 storage from the given options. The endpoint must be an absolute URL, which allows pointing the
+// driver at any S3-compatible server, including a local one during tests.
+func NewS3(options S3Options) (*S3, error) {
+	endpoint, err := url.Parse(options.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, errormessage.ErrInvalidS3Endpoint
+	}
+
+	if options.Bucket == "" {
+		return nil, errormessage.ErrMissingS3Bucket
+	}
+
+	if options.Region == "" {
+		options.Region = s3DefaultRegion
+	}
+
+	client := options.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	s := &S3{endpoint: endpoint, options: options, client: client, nowFunc: time.Now}
+	s.publicURL = options.PublicURL
+	if s.publicURL == "" {
+		s.publicURL = s.bucketURL().String()
+	}
+
+	return s, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	headers := http.Header{}
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+
+	if err := s.do(ctx, http.MethodPut, key, payload, headers); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	return s.do(ctx, http.MethodDelete, key, nil, http.Header{})
+}
+
+func (s *S3) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
+
+// do sends a signed request for the object identified by key and checks the response status.
+func (s *S3) do(ctx context.Context, method, key string, payload []byte, headers http.Header) error {
+	objectURL := s.objectURL(key)
+
+	req, err := http.NewRequestWithContext(ctx, method, objectURL.String(), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(payload))
+
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	s.sign(req, payload)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		if method == http.MethodDelete && res.StatusCode == http.StatusNotFound {
+			return nil
+		}
+
+		message, _ := io.ReadAll(io.LimitReader(res.Body, s3ErrorBodyMaxSize))
+		return fmt.Errorf("%s: %s %s: %d %s", errormessage.ErrS3RequestFailedText, method, key, res.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// bucketURL returns the base URL of the bucket, using either path-style or virtual-hosted-style addressing.
+func (s *S3) bucketURL() *url.URL {
+	u := *s.endpoint
+	if s.options.UsePathStyle {
+		u.Path = strings.TrimRight(u.Path, "/") + "/" + s.options.Bucket
+	} else {
+		u.Host = s.options.Bucket + "." + u.Host
+	}
+
+	return &u
+}
+
+// objectURL returns the URL of the object identified by key.
+func (s *S3) objectURL(key string) *url.URL {
+	u := s.bucketURL()
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + strings.TrimLeft(key, "/")
+	u.RawPath = canonicalURI(u)
+
+	return u
+}
+
+// sign adds the AWS Signature Version 4 authorization headers to the request.
+func (s *S3) sign(req *http.Request, payload []byte) {
+	now := s.nowFunc().UTC()
+	amzDate := now.Format(s3TimeFormat)
+	date := now.Format(s3DateFormat)
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders, canonicalHeaders := canonicalizeHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		req.URL.Query().Encode(),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.options.Region, s3Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.options.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, s.options.Region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.options.AccessKey, scope, signedHeaders, signature))
+}
+
+// canonicalizeHeaders returns the signed header list and the canonical header block of the request.
+func canonicalizeHeaders(req *http.Request) (string, string) {
+	names := []string{"host"}
+	values := map[string]string{"host": req.URL.Host}
+
+	for name, v := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "host" || (lower != "content-type" && !strings.HasPrefix(lower, "x-amz-")) {
+			continue
+		}
+		names = append(names, lower)
+		values[lower] = strings.TrimSpace(strings.Join(v, ","))
+	}
+
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// canonicalURI URI-encodes every segment of the request path as required by Signature Version 4.
+func canonicalURI(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(u.Path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// uriEncode percent-encodes every byte except the unreserved characters, following the Signature Version 4 rules.
+func uriEncode(value string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+
+	return encoded.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/arifai/zenith/config"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"io"
+	"strings"
+)
+
+// Storage is an object storage used to persist uploaded files such as avatars.
+type Storage interface {
+	// Put stores the content read from body under the given key and returns the public URL of the stored object.
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+
+	// Delete removes the object stored under the given key. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the public URL of the object stored under the given key.
+	URL(key string) string
+}
+
+const (
+	LocalDriver = "local"
+	S3Driver    = "s3"
+)
+
+// New creates the Storage selected by the StorageDriver setting of the provided configuration.
+func New(cfg *config.Config) (Storage, error) {
+	switch {
+	case IsLocal(cfg.StorageDriver):
+		publicURL := cfg.StoragePublicURL
+		if publicURL == "" {
+			publicURL = strings.TrimRight(cfg.AppURL, "/") + cfg.StorageRoute
+		}
+		return NewLocal(cfg.StorageLocalRoot, publicURL), nil
+	case cfg.StorageDriver == S3Driver:
+		return NewS3(S3Options{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+			PublicURL:    cfg.StoragePublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("%s: %s", errormessage.ErrUnknownStorageDriverText, cfg.StorageDriver)
+	}
+}
+
+// IsLocal reports whether the given driver keeps files on the local disk, which is the default when none is set.
+func IsLocal(driver string) bool {
+	return driver == LocalDriver || driver == ""
+}
+
+// joinURL joins a base URL and an object key with a single slash.
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(key, "/")
+}
@@ -2,22 +2,32 @@ package utils
 
 import (
 	"bytes"
-	"fmt"
+	"errors"
 	"github.com/arifai/zenith/cmd/wire/logger"
 	"github.com/arifai/zenith/config"
 	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/mail"
 	"go.uber.org/zap"
 	"html/template"
-	"net/smtp"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"net/textproto"
+	"path"
+	"strings"
 	"sync"
+	text "text/template"
+	"time"
 )
 
 // Mailer is an interface for sending emails.
 type Mailer interface {
-	// SendMail sends an email to the specified recipients with the given subject and body using the configured SMTP server.
+	// SendMail sends an email to the specified recipients with the given subject and body through the configured transport.
 	SendMail(to []string, subject string, body string) error
 
-	// SendMailWithTemplate sends an email to the specified recipients using an HTML template.
+	// SendMailWithTemplate sends an email to the specified recipients using an HTML template. When a text template with
+	// the same name and the .txt extension sits next to it, both are rendered and sent as alternatives.
 	SendMailWithTemplate(to []string, subject string, templateFileName string, data interface{}) error
 
 	// QueueMail enqueues an email to be sent later by a worker.
@@ -26,19 +36,23 @@ type Mailer interface {
 	// QueueMailWithTemplate enqueues an email with a template to be sent later by a worker.
 	QueueMailWithTemplate(to []string, subject string, templateFileName string, data interface{})
 
-	// Worker processes email requests from the queue, sending each email using the configured SMTP server.
+	// Worker processes email requests from the queue, sending each email through the configured transport.
 	Worker()
 
 	// Shutdown signals the mailer to stop processing new email requests and waits until all current tasks are completed.
 	Shutdown()
 }
 
-// MailerImpl provides functionality for sending emails using SMTP. It is initialized with SMTP server configuration details.
+// MailerImpl provides functionality for composing emails and sending them through a mail.Transport. Messages are sent
+// from MailFrom, or from the SMTP username without it. Templates are looked up by their path in the templates file
+// system.
 type MailerImpl struct {
-	config  config.Config
-	queue   chan emailRequest
-	workers int
-	wg      sync.WaitGroup
+	config    config.Config
+	transport mail.Transport
+	templates fs.FS
+	queue     chan emailRequest
+	workers   int
+	wg        sync.WaitGroup
 }
 
 // emailRequest represents a request to send an email, including recipient addresses, subject, and email body content.
@@ -52,42 +66,67 @@ type emailRequest struct {
 
 var log = logger.ProvideLogger()
 
-// NewMailer creates a new MailerImpl instance with the provided SMTPConfig, queue size, and number of worker routines.
-func NewMailer(config config.Config, queueSize int, workers int) *MailerImpl {
+// NewMailer creates a new MailerImpl instance with the provided configuration, transport, file system of the
+// templates, queue size, and number of worker routines.
+func NewMailer(config config.Config, transport mail.Transport, templates fs.FS, queueSize int, workers int) *MailerImpl {
 	mailer := &MailerImpl{
-		config:  config,
-		queue:   make(chan emailRequest, queueSize),
-		workers: workers,
+		config:    config,
+		transport: transport,
+		templates: templates,
+		queue:     make(chan emailRequest, queueSize),
+		workers:   workers,
 	}
 
+	// The workers are counted before they start, so Shutdown waits for them even if it is called right away.
+	mailer.wg.Add(workers)
 	for i := 0; i < workers; i++ {
-		go mailer.Worker()
+		go func() {
+			defer mailer.wg.Done()
+			mailer.Worker()
+		}()
 	}
 
 	return mailer
 }
 
 func (m *MailerImpl) SendMail(to []string, subject string, body string) error {
-	auth := smtp.PlainAuth("", m.config.SMTPUsername, m.config.SMTPPassword, m.config.SMTPHost)
-	msg := "From: " + m.config.SMTPUsername + "\n" +
-		"To: " + fmt.Sprintf("%s", to) + "\n" +
-		"Subject: " + subject + "\n\n" +
-		body
-	return smtp.SendMail(fmt.Sprintf("%s:%d", m.config.SMTPHost, m.config.SMTPPort), auth, m.config.SMTPUsername, to, []byte(msg))
+	return m.send(to, subject, "text/plain; charset=UTF-8", body)
 }
 
 func (m *MailerImpl) SendMailWithTemplate(to []string, subject string, templateFileName string, data interface{}) error {
-	tmpl, err := template.ParseFiles(templateFileName)
+	tmpl, err := template.ParseFS(m.templates, templateFileName)
 	if err != nil {
 		return err
 	}
 
+	var html bytes.Buffer
+	if err := tmpl.Execute(&html, data); err != nil {
+		return err
+	}
+
+	plain, err := m.renderTextTemplate(strings.TrimSuffix(templateFileName, path.Ext(templateFileName))+".txt", data)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m.send(to, subject, "text/html; charset=UTF-8", html.String())
+	} else if err != nil {
+		return err
+	}
+
 	var body bytes.Buffer
-	if err := tmpl.Execute(&body, data); err != nil {
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{{"text/plain", plain}, {"text/html", html.String()}} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType + "; charset=UTF-8"}})
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
 		return err
 	}
 
-	return m.SendMail(to, subject, body.String())
+	return m.send(to, subject, "multipart/alternative; boundary="+writer.Boundary(), body.String())
 }
 
 func (m *MailerImpl) QueueMail(to []string, subject string, body string) {
@@ -99,8 +138,6 @@ func (m *MailerImpl) QueueMailWithTemplate(to []string, subject string, template
 }
 
 func (m *MailerImpl) Worker() {
-	m.wg.Add(1)
-	defer m.wg.Done()
 	for email := range m.queue {
 		var err error
 		if email.templateFileName != "" {
@@ -118,3 +155,47 @@ func (m *MailerImpl) Shutdown() {
 	close(m.queue)
 	m.wg.Wait()
 }
+
+// renderTextTemplate renders the plain text template at the given path, returning an error wrapping fs.ErrNotExist if
+// there is none.
+func (m *MailerImpl) renderTextTemplate(templateFileName string, data interface{}) (string, error) {
+	if _, err := fs.Stat(m.templates, templateFileName); err != nil {
+		return "", err
+	}
+
+	tmpl, err := text.ParseFS(m.templates, templateFileName)
+	if err != nil {
+		return "", err
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return "", err
+	}
+
+	return body.String(), nil
+}
+
+// send composes a message with the given content type and delivers it to the recipients through the transport.
+func (m *MailerImpl) send(to []string, subject, contentType, body string) error {
+	from := m.config.MailFrom
+	if from == "" {
+		from = m.config.SMTPUsername
+	}
+
+	sender := from
+	if address, err := netmail.ParseAddress(from); err == nil {
+		sender = address.Address
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + from + "\r\n")
+	msg.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: " + contentType + "\r\n\r\n")
+	msg.WriteString(body)
+
+	return m.transport.Send(sender, to, msg.Bytes())
+}
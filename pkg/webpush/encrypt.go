@@ -0,0 +1,150 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"strings"
+)
+
+const (
+	// recordSize is the record size announced in the aes128gcm header. Payloads are sent as a single record.
+	recordSize = 4096
+
+	// headerSize is the size of the aes128gcm header carrying a P-256 public key: salt, record size, key ID length and
+	// the key ID.
+	headerSize = 16 + 4 + 1 + 65
+
+	// MaxPayloadSize is the largest plaintext that fits into a single record together with its delimiter and the
+	// authentication tag of AES-GCM.
+	MaxPayloadSize = recordSize - headerSize - 1 - 16
+)
+
+// ErrPayloadTooLarge is returned for payloads larger than MaxPayloadSize.
+var ErrPayloadTooLarge = errormessage.ErrWebPushPayloadTooLarge
+
+// encrypt encrypts the payload for the subscription with the public key p256dh and the authentication secret auth,
+// following the aes128gcm content encoding of RFC 8188 and the key derivation of RFC 8291.
+func encrypt(payload []byte, p256dh, auth string) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	userAgentKey, err := decodeKey(p256dh)
+	if err != nil {
+		return nil, err
+	}
+	authSecret, err := decodeKey(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	userAgentPublic, err := ecdh.P256().NewPublicKey(userAgentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	serverPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serverPublic := serverPrivate.PublicKey().Bytes()
+
+	sharedSecret, err := serverPrivate.ECDH(userAgentPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	keyInfo := append(append([]byte("WebPush: info\x00"), userAgentKey...), serverPublic...)
+	mac := hmac.New(sha256.New, authSecret)
+	mac.Write(sharedSecret)
+	ikm, err := expand(mac.Sum(nil), keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	contentKey, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+
+	// The plaintext of the last and only record is terminated by the delimiter 0x02.
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// expand reads length bytes of HKDF-Expand output for the given pseudorandom key and info.
+func expand(prk, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// decodeKey decodes a key of a subscription, which browsers encode as unpadded base64url, accepting padding and the
+// standard alphabet as well.
+func decodeKey(key string) ([]byte, error) {
+	key = strings.TrimRight(key, "=")
+	key = strings.NewReplacer("+", "-", "/", "_").Replace(key)
+
+	return base64.RawURLEncoding.DecodeString(key)
+}
+
+// ValidateKeys reports whether p256dh is an uncompressed P-256 public key and auth a 16 byte authentication secret, the
+// keys of a subscription messages can be encrypted for.
+func ValidateKeys(p256dh, auth string) error {
+	userAgentKey, err := decodeKey(p256dh)
+	if err != nil {
+		return err
+	}
+	if _, err := ecdh.P256().NewPublicKey(userAgentKey); err != nil {
+		return err
+	}
+
+	authSecret, err := decodeKey(auth)
+	if err != nil {
+		return err
+	}
+	if len(authSecret) != 16 {
+		return errormessage.ErrInvalidWebPushAuthSecret
+	}
+
+	return nil
+}
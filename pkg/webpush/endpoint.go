@@ -0,0 +1,100 @@
+package webpush
+
+import (
+	"github.com/arifai/zenith/pkg/errormessage"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultAllowedHosts are the hosts of the push services of the major browsers, Chrome and Edge through FCM, Firefox
+// through Mozilla autopush, legacy Edge through WNS and Safari through Apple. Entries starting with a dot match every
+// subdomain of the host.
+var DefaultAllowedHosts = []string{
+	"fcm.googleapis.com",
+	"updates.push.services.mozilla.com",
+	".push.services.mozilla.com",
+	".notify.windows.com",
+	"web.push.apple.com",
+}
+
+// blockedPrefixes are the address ranges besides loopback, private, link-local and multicast addresses that are never
+// dialed: the unspecified, shared (carrier-grade NAT) and benchmarking ranges.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// ValidateEndpoint checks that a subscription endpoint is an HTTPS URL of one of the allowed hosts,
+// so subscriptions can not make the server send requests to arbitrary hosts. A nil list allows DefaultAllowedHosts.
+func ValidateEndpoint(endpoint string, allowedHosts []string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme != "https" || parsed.User != nil || parsed.Host == "" {
+		return errormessage.ErrInvalidWebPushEndpoint
+	}
+	if allowedHosts == nil {
+		allowedHosts = DefaultAllowedHosts
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return nil
+		}
+	}
+
+	return errormessage.ErrWebPushEndpointNotAllowed
+}
+
+// newHTTPClient returns the HTTP client used to reach push services when Config has none. Its dialer refuses loopback,
+// private and link-local addresses, so an allowed host resolving to an internal address is not reached either. Proxies
+// from the environment are ignored since the dialer would only see the address of the proxy.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: guardAddress}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Transport: transport}
+}
+
+// guardAddress is the Control hook of the dialer, rejecting connections to addresses that are not publicly routable.
+func guardAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(ip.Unmap()) {
+		return errormessage.ErrForbiddenPushAddress
+	}
+
+	return nil
+}
+
+// publicAddress reports whether an address is publicly routable.
+func publicAddress(ip netip.Addr) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,170 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestValidateEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     error
+	}{
+		{"https://fcm.googleapis.com/fcm/send/abc", nil},
+		{"https://updates.push.services.mozilla.com/wpush/v2/abc", nil},
+		{"https://wns2-par02p.notify.windows.com/w/?token=abc", nil},
+		{"https://web.push.apple.com/abc", nil},
+		{"https://fcm.googleapis.com:443/fcm/send/abc", nil},
+		{"http://fcm.googleapis.com/fcm/send/abc", errormessage.ErrInvalidWebPushEndpoint},
+		{"https://user@fcm.googleapis.com/fcm/send/abc", errormessage.ErrInvalidWebPushEndpoint},
+		{"https://127.0.0.1/abc", errormessage.ErrWebPushEndpointNotAllowed},
+		{"https://169.254.169.254/latest/meta-data", errormessage.ErrWebPushEndpointNotAllowed},
+		{"https://notify.windows.com.attacker.example/abc", errormessage.ErrWebPushEndpointNotAllowed},
+		{"https://evilfcm.googleapis.com/abc", errormessage.ErrWebPushEndpointNotAllowed},
+	}
+
+	for _, test := range tests {
+		if err := ValidateEndpoint(test.endpoint, nil); !errors.Is(err, test.want) {
+			t.Errorf("ValidateEndpoint(%q) = %v, want %v", test.endpoint, err, test.want)
+		}
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	tests := map[string]bool{
+		"142.250.185.74":  true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+	}
+
+	for address, want := range tests {
+		if got := publicAddress(netip.MustParseAddr(address)); got != want {
+			t.Errorf("publicAddress(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
+
+func TestSendRefusesLoopbackAddress(t *testing.T) {
+	var reached bool
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient(t, []string{endpoint.Hostname()}, nil)
+	_, err = client.Send(context.Background(), newTestMessage(t, server.URL+"/push"))
+	if !errors.Is(err, errormessage.ErrForbiddenPushAddress) {
+		t.Fatalf("Send() error = %v, want %v", err, errormessage.ErrForbiddenPushAddress)
+	}
+	if reached {
+		t.Fatal("push service on a loopback address was reached")
+	}
+}
+
+func TestSendRejectsEndpointOutsideAllowedHosts(t *testing.T) {
+	client := newTestClient(t, nil, http.DefaultClient)
+
+	_, err := client.Send(context.Background(), newTestMessage(t, "https://push.attacker.example/abc"))
+
+	var pushErr *push.Error
+	if !errors.As(err, &pushErr) || !pushErr.Permanent || !pushErr.InvalidToken {
+		t.Fatalf("Send() error = %v, want a permanent invalid token error", err)
+	}
+	if !errors.Is(err, errormessage.ErrWebPushEndpointNotAllowed) {
+		t.Fatalf("Send() error = %v, want %v", err, errormessage.ErrWebPushEndpointNotAllowed)
+	}
+}
+
+func TestSendDeliversToAllowedHost(t *testing.T) {
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Location", "/messages/1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient(t, []string{endpoint.Hostname()}, server.Client())
+	location, err := client.Send(context.Background(), newTestMessage(t, server.URL+"/push"))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if location != "/messages/1" {
+		t.Errorf("Send() location = %q, want %q", location, "/messages/1")
+	}
+	if authorization == "" {
+		t.Error("Send() sent no VAPID authorization")
+	}
+}
+
+// newTestClient creates a Client with a fresh VAPID key, the given allowed hosts and HTTP client.
+func newTestClient(t *testing.T, allowedHosts []string, httpClient *http.Client) *Client {
+	t.Helper()
+
+	privateKey, _, err := GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := New(Config{PrivateKey: privateKey, Subject: "mailto:ops@example.com", AllowedHosts: allowedHosts, HTTPClient: httpClient})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+// newTestMessage creates a message to a subscription at the given endpoint with fresh browser keys.
+func newTestMessage(t *testing.T, endpoint string) *push.Message {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		t.Fatal(err)
+	}
+
+	return &push.Message{
+		Token: endpoint,
+		Title: "Hello",
+		Body:  "World",
+		Keys: &push.Keys{
+			P256dh: base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:   base64.RawURLEncoding.EncodeToString(auth),
+		},
+	}
+}
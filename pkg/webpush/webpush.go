@@ -0,0 +1,317 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/arifai/zenith/pkg/crypto"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// Config contains the settings of a Client.
+	Config struct {
+		// PrivateKey is the VAPID private key, the unpadded base64url encoding of a P-256 private scalar as generated by
+		// GenerateKeys.
+		PrivateKey string
+
+		// Subject is the contact of the application server, a mailto: or https: URL push services may use to reach
+		// the operator.
+		Subject string
+
+		// AllowedHosts overrides the push service hosts subscription endpoints may point to, DefaultAllowedHosts when
+		// nil. See ValidateEndpoint.
+		AllowedHosts []string
+
+		// HTTPClient overrides the HTTP client used to reach the push services. The default client refuses to connect
+		// to loopback, private and link-local addresses.
+		HTTPClient *http.Client
+	}
+
+	// Client delivers notifications to browser push subscriptions with the Web Push protocol of RFC 8030, authenticated
+	// with VAPID (RFC 8292) and encrypted with aes128gcm (RFC 8291). It implements push.Provider.
+	Client struct {
+		key       *ecdsa.PrivateKey
+		publicKey string
+		subject   string
+		http      *http.Client
+		hosts     []string
+
+		mu     sync.Mutex
+		tokens map[string]vapidToken
+	}
+
+	// vapidToken is a signed VAPID token of a push service origin.
+	vapidToken struct {
+		token     string
+		expiresAt time.Time
+	}
+
+	// notification is the JSON payload handed to the service worker of the subscription.
+	notification struct {
+		Title       string            `json:"title"`
+		Body        string            `json:"body"`
+		Image       string            `json:"image,omitempty"`
+		ClickAction string            `json:"click_action,omitempty"`
+		Data        map[string]string `json:"data,omitempty"`
+	}
+)
+
+const (
+	// tokenLifetime is the validity of a VAPID token, push services reject tokens valid for more than 24 hours.
+	tokenLifetime = time.Hour * 12
+
+	// tokenRenewal is how long before its expiry a cached VAPID token is replaced.
+	tokenRenewal = time.Hour
+
+	// defaultTTL is the number of seconds a push service keeps a message without a time to live, four weeks.
+	defaultTTL = 2419200
+
+	// requestTimeout bounds a single request to a push service when the context has no deadline.
+	requestTimeout = time.Second * 30
+)
+
+// topicPattern matches the values push services accept in the Topic header.
+var topicPattern = regexp.MustCompile(`^[A-Za-z0-9\-_]{1,32}$`)
+
+// New creates a Client from the given Config.
+func New(config Config) (*Client, error) {
+	key, err := parsePrivateKey(config.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := encodePublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = newHTTPClient()
+	}
+
+	return &Client{
+		key:       key,
+		publicKey: publicKey,
+		subject:   config.Subject,
+		http:      client,
+		hosts:     config.AllowedHosts,
+		tokens:    make(map[string]vapidToken),
+	}, nil
+}
+
+// GenerateKeys generates a new VAPID key pair, encoded as unpadded base64url. The public key is the applicationServerKey
+// browsers subscribe with.
+func GenerateKeys() (privateKey, publicKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key.Bytes()), base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// PublicKey returns the VAPID public key of the given private key.
+func PublicKey(privateKey string) (string, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	return encodePublicKey(key)
+}
+
+// Send encrypts the message for the subscription whose endpoint is the token of the message and delivers it to the push
+// service. It returns the location of the message created by the push service. Expired subscriptions and endpoints
+// outside the allowed hosts are permanent token errors, throttling and outages are transient and other rejections are
+// permanent.
+func (c *Client) Send(ctx context.Context, message *push.Message) (string, error) {
+	if message.Token == "" || message.Keys == nil {
+		return "", &push.Error{Err: errormessage.ErrNotAddressedToSubscription, Permanent: true}
+	}
+
+	if err := ValidateEndpoint(message.Token, c.hosts); err != nil {
+		return "", &push.Error{Err: err, Permanent: true, InvalidToken: true}
+	}
+
+	endpoint, err := url.Parse(message.Token)
+	if err != nil {
+		return "", &push.Error{Err: err, Permanent: true, InvalidToken: true}
+	}
+
+	payload, err := json.Marshal(notificationPayload(message))
+	if err != nil {
+		return "", &push.Error{Err: err, Permanent: true}
+	}
+
+	body, err := encrypt(payload, message.Keys.P256dh, message.Keys.Auth)
+	if errors.Is(err, ErrPayloadTooLarge) {
+		return "", &push.Error{Err: err, Permanent: true}
+	} else if err != nil {
+		return "", &push.Error{Err: err, Permanent: true, InvalidToken: true}
+	}
+
+	token, err := c.token(endpoint.Scheme+"://"+endpoint.Host, time.Now())
+	if err != nil {
+		return "", &push.Error{Err: err, Permanent: true}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, message.Token, bytes.NewReader(body))
+	if err != nil {
+		return "", &push.Error{Err: err, Permanent: true}
+	}
+
+	request.Header.Set("Authorization", "vapid t="+token+", k="+c.publicKey)
+	request.Header.Set("Content-Encoding", "aes128gcm")
+	request.Header.Set("Content-Type", "application/octet-stream")
+	for name, value := range headers(message.Options) {
+		request.Header.Set(name, value)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return "", &push.Error{Err: err}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return response.Header.Get("Location"), nil
+	}
+
+	return "", classifyError(response.StatusCode)
+}
+
+// token returns the cached VAPID token of a push service origin, signing a new one when it is about to expire.
+func (c *Client) token(audience string, now time.Time) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.tokens[audience]; ok && now.Add(tokenRenewal).Before(cached.expiresAt) {
+		return cached.token, nil
+	}
+
+	expiresAt := now.Add(tokenLifetime)
+	claims := map[string]interface{}{"aud": audience, "exp": expiresAt.Unix()}
+	if c.subject != "" {
+		claims["sub"] = c.subject
+	}
+
+	token, err := crypto.SignES256JWT(c.key, map[string]string{"typ": "JWT"}, claims)
+	if err != nil {
+		return "", err
+	}
+	c.tokens[audience] = vapidToken{token: token, expiresAt: expiresAt}
+
+	return token, nil
+}
+
+// headers returns the TTL, Urgency and Topic headers of a message. A TTL is always sent since push services require it.
+func headers(options *push.Options) map[string]string {
+	headers := map[string]string{"TTL": strconv.Itoa(defaultTTL)}
+	if options == nil {
+		return headers
+	}
+
+	if options.TimeToLive != nil {
+		headers["TTL"] = strconv.Itoa(*options.TimeToLive)
+	}
+	if options.Priority != "" {
+		headers["Urgency"] = options.Priority
+	}
+	if topicPattern.MatchString(options.CollapseKey) {
+		headers["Topic"] = options.CollapseKey
+	}
+
+	return headers
+}
+
+// notificationPayload builds the payload of a message, which the service worker shows as a notification.
+func notificationPayload(message *push.Message) *notification {
+	payload := &notification{Title: message.Title, Body: message.Body, Image: message.Image, Data: message.Data}
+	if message.Options != nil {
+		payload.ClickAction = message.Options.ClickAction
+	}
+
+	return payload
+}
+
+// classifyError maps a rejected request to a push.Error by its status code. Push services answer 404 and 410 for
+// subscriptions that expired or were unsubscribed.
+func classifyError(status int) error {
+	err := fmt.Errorf("%w: %d", errormessage.ErrWebPushRejected, status)
+
+	switch {
+	case status == http.StatusNotFound || status == http.StatusGone:
+		return &push.Error{Err: err, Permanent: true, InvalidToken: true}
+	case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		return &push.Error{Err: err}
+	default:
+		return &push.Error{Err: err, Permanent: true}
+	}
+}
+
+// parsePrivateKey decodes a VAPID private key into an ECDSA key for signing.
+func parsePrivateKey(privateKey string) (*ecdsa.PrivateKey, error) {
+	raw, err := decodeKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return ecdsaKey(key)
+}
+
+// encodePublicKey encodes the public key of a VAPID key as an uncompressed point in unpadded base64url.
+func encodePublicKey(key *ecdsa.PrivateKey) (string, error) {
+	public, err := key.PublicKey.ECDH()
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(public.Bytes()), nil
+}
+
+// ecdsaKey converts a P-256 key for key agreement into the equivalent key for signing.
+func ecdsaKey(key *ecdh.PrivateKey) (*ecdsa.PrivateKey, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	signingKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errormessage.ErrInvalidVAPIDKey
+	}
+
+	return signingKey, nil
+}
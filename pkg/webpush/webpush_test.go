@@ -0,0 +1,69 @@
+package webpush
+
+import (
+	"context"
+	"errors"
+	"github.com/arifai/zenith/pkg/errormessage"
+	"github.com/arifai/zenith/pkg/push"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSendClassifiesRejections(t *testing.T) {
+	tests := []struct {
+		status       int
+		permanent    bool
+		invalidToken bool
+	}{
+		{http.StatusGone, true, true},
+		{http.StatusNotFound, true, true},
+		{http.StatusBadRequest, true, false},
+		{http.StatusTooManyRequests, false, false},
+		{http.StatusServiceUnavailable, false, false},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(test.status)
+		}))
+
+		endpoint, err := url.Parse(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		client := newTestClient(t, []string{endpoint.Hostname()}, server.Client())
+		_, err = client.Send(context.Background(), newTestMessage(t, server.URL+"/push"))
+		server.Close()
+
+		if !errors.Is(err, errormessage.ErrWebPushRejected) {
+			t.Fatalf("Send() error = %v, want %v", err, errormessage.ErrWebPushRejected)
+		}
+		if push.IsPermanent(err) != test.permanent || push.IsInvalidToken(err) != test.invalidToken {
+			t.Errorf("status %d: Send() error = %v, want permanent %v and invalid token %v", test.status, err,
+				test.permanent, test.invalidToken)
+		}
+	}
+}
+
+func TestSendRequiresSubscription(t *testing.T) {
+	client := newTestClient(t, nil, http.DefaultClient)
+
+	_, err := client.Send(context.Background(), &push.Message{Token: "https://fcm.googleapis.com/fcm/send/abc"})
+	if !errors.Is(err, errormessage.ErrNotAddressedToSubscription) || !push.IsPermanent(err) {
+		t.Fatalf("Send() error = %v, want a permanent %v", err, errormessage.ErrNotAddressedToSubscription)
+	}
+}
+
+func TestValidateKeysRejectsShortAuthSecret(t *testing.T) {
+	message := newTestMessage(t, "https://fcm.googleapis.com/fcm/send/abc")
+
+	if err := ValidateKeys(message.Keys.P256dh, "c2hvcnQ"); !errors.Is(err, errormessage.ErrInvalidWebPushAuthSecret) {
+		t.Fatalf("ValidateKeys() error = %v, want %v", err, errormessage.ErrInvalidWebPushAuthSecret)
+	}
+	if err := ValidateKeys(message.Keys.P256dh, message.Keys.Auth); err != nil {
+		t.Fatalf("ValidateKeys() error = %v", err)
+	}
+}